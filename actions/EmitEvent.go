@@ -0,0 +1,80 @@
+package actions
+
+import (
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * EmitEvent publishes an event and returns `SUCCESS`, so a tree can signal
+ * the game systems without a custom node. The event goes to the bus of the
+ * blackboard (Blackboard.Events) or, with the global scope, to
+ * GlobalEvents. Its payload is the value of the payloadKey key of the
+ * global memory when set, else the payload property.
+ *
+ * The event name is a template: "{key}" placeholders are replaced with
+ * the values of the blackboard as in RenderTitle.
+ *
+ * @module b3
+ * @class EmitEvent
+ * @extends Action
+**/
+type EmitEvent struct {
+	Action
+	event      string
+	global     bool
+	payload    interface{}
+	payloadKey string
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **event**      (*String*) The topic of the event.
+ * - **scope**      (*String*) "blackboard" (default) or "global".
+ * - **payload**    (*Object*) The payload.
+ * - **payloadKey** (*String*) Global memory key of the payload.
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *EmitEvent) Initialize(setting *BTNodeCfg) {
+	this.Action.Initialize(setting)
+	this.event = setting.GetPropertyAsString("event")
+	if this.event == "" {
+		panic("event parameter in EmitEvent action is an obligatory parameter")
+	}
+	this.global = false
+	if _, ok := setting.Properties["scope"]; ok {
+		switch scope := setting.GetPropertyAsString("scope"); scope {
+		case "blackboard":
+		case "global":
+			this.global = true
+		default:
+			panic("EmitEvent action: unknown scope " + scope)
+		}
+	}
+	this.payload = setting.Properties["payload"]
+	this.payloadKey = ""
+	if _, ok := setting.Properties["payloadKey"]; ok {
+		this.payloadKey = setting.GetPropertyAsString("payloadKey")
+	}
+}
+
+func (this *EmitEvent) OnTick(tick *Tick) b3.Status {
+	topic := RenderTemplate(this.event, tick.Blackboard, tick.GetTree().GetID(), this.GetID(), nil)
+	payload := this.payload
+	if this.payloadKey != "" {
+		payload = tick.Blackboard.GetMem(this.payloadKey)
+	}
+	bus := tick.Events()
+	if this.global {
+		bus = GlobalEvents()
+	}
+	bus.Publish(topic, payload)
+	return b3.SUCCESS
+}
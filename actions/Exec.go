@@ -0,0 +1,166 @@
+package actions
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+// execRun is a running command, kept in the node memory.
+type execRun struct {
+	cmd    *exec.Cmd
+	done   chan struct{}
+	output bytes.Buffer
+	code   int
+	err    error
+}
+
+/**
+ * Exec runs an external command without blocking the tick: the process
+ * starts when the node opens and the node returns `RUNNING` until it
+ * exits, then `SUCCESS` for the exit code 0 and `FAILURE` otherwise (or
+ * when it could not start). The exit code and the combined output are
+ * written to the codeKey and outputKey keys of the global memory, when
+ * set.
+ *
+ * The command and its args are templates: "{key}" placeholders are
+ * replaced with the values of the blackboard as in RenderTitle. Each arg
+ * is passed as is to the process, no shell is involved. Halting the node
+ * kills the process unless killOnAbort is false.
+ *
+ * @module b3
+ * @class Exec
+ * @extends Action
+**/
+type Exec struct {
+	Action
+	command     string
+	args        []string
+	dir         string
+	killOnAbort bool
+	codeKey     string
+	outputKey   string
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **command**     (*String*)  The program to run.
+ * - **args**        (*Array*)   The arguments templates.
+ * - **dir**         (*String*)  Working directory.
+ * - **killOnAbort** (*Boolean*) Kill the process when the node is halted
+ *                               (default true).
+ * - **codeKey**     (*String*)  Key of the exit code.
+ * - **outputKey**   (*String*)  Key of the output.
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *Exec) Initialize(setting *BTNodeCfg) {
+	this.Action.Initialize(setting)
+	this.command = setting.GetPropertyAsString("command")
+	if this.command == "" {
+		panic("command parameter in Exec action is an obligatory parameter")
+	}
+	this.args = nil
+	if args, ok := setting.Properties["args"].([]interface{}); ok {
+		for _, arg := range args {
+			s, ok := arg.(string)
+			if !ok {
+				panic("Exec action: args must be strings")
+			}
+			this.args = append(this.args, s)
+		}
+	}
+	this.dir, this.codeKey, this.outputKey = "", "", ""
+	if _, ok := setting.Properties["dir"]; ok {
+		this.dir = setting.GetPropertyAsString("dir")
+	}
+	if _, ok := setting.Properties["codeKey"]; ok {
+		this.codeKey = setting.GetPropertyAsString("codeKey")
+	}
+	if _, ok := setting.Properties["outputKey"]; ok {
+		this.outputKey = setting.GetPropertyAsString("outputKey")
+	}
+	this.killOnAbort = true
+	if _, ok := setting.Properties["killOnAbort"]; ok {
+		this.killOnAbort = setting.GetPropertyAsBool("killOnAbort")
+	}
+}
+
+func (this *Exec) OnOpen(tick *Tick) {
+	treeID := tick.GetTree().GetID()
+	render := func(s string) string {
+		return RenderTemplate(s, tick.Blackboard, treeID, this.GetID(), nil)
+	}
+	args := make([]string, len(this.args))
+	for i, arg := range this.args {
+		args[i] = render(arg)
+	}
+
+	run := &execRun{done: make(chan struct{})}
+	run.cmd = exec.Command(render(this.command), args...)
+	run.cmd.Dir = this.dir
+	run.cmd.Stdout = &run.output
+	run.cmd.Stderr = &run.output
+	tick.Blackboard.Set("run", run, treeID, this.GetID())
+	if run.err = run.cmd.Start(); run.err != nil {
+		close(run.done)
+		return
+	}
+	go func() {
+		defer close(run.done)
+		err := run.cmd.Wait()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			run.code = exitErr.ExitCode()
+		} else {
+			run.err = err
+		}
+	}()
+}
+
+func (this *Exec) OnTick(tick *Tick) b3.Status {
+	run, ok := tick.Blackboard.Get("run", tick.GetTree().GetID(), this.GetID()).(*execRun)
+	if !ok {
+		return b3.ERROR
+	}
+	select {
+	case <-run.done:
+	default:
+		return b3.RUNNING
+	}
+	if run.err != nil {
+		tick.SetError(this, run.err)
+		return b3.FAILURE
+	}
+	if this.codeKey != "" {
+		tick.Blackboard.SetMem(this.codeKey, run.code)
+	}
+	if this.outputKey != "" {
+		tick.Blackboard.SetMem(this.outputKey, run.output.String())
+	}
+	if run.code != 0 {
+		return b3.FAILURE
+	}
+	return b3.SUCCESS
+}
+
+func (this *Exec) OnClose(tick *Tick) {
+	treeID := tick.GetTree().GetID()
+	if run, ok := tick.Blackboard.Get("run", treeID, this.GetID()).(*execRun); ok && this.killOnAbort {
+		select {
+		case <-run.done:
+		default:
+			run.cmd.Process.Kill()
+		}
+	}
+	tick.Blackboard.Set("run", nil, treeID, this.GetID())
+}
@@ -0,0 +1,64 @@
+package actions
+
+import (
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+	"github.com/youngtrips/behavior3go/expr"
+)
+
+/**
+ * Expression evaluates an expression over the global memory of the
+ * blackboard (see package expr for the syntax), covering the glue actions
+ * too small to be written in Go. With an assignment, e.g.
+ * "damage = atk * 2 - def", the result is written to the target key and
+ * the node returns `SUCCESS`; without one it returns `SUCCESS` when the
+ * result holds and `FAILURE` otherwise. An evaluation error (e.g.
+ * arithmetic on a missing key) makes it return `FAILURE`, reported with
+ * Tick.SetError, and nothing is written.
+ *
+ * @module b3
+ * @class Expression
+ * @extends Action
+**/
+type Expression struct {
+	Action
+	target string
+	expr   *expr.Expr
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **expr** (*String*) The expression, possibly "key = expression".
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *Expression) Initialize(setting *BTNodeCfg) {
+	this.Action.Initialize(setting)
+	target, e, err := expr.ParseAssignment(setting.GetPropertyAsString("expr"))
+	if err != nil {
+		panic("Expression action: " + err.Error())
+	}
+	this.target, this.expr = target, e
+}
+
+func (this *Expression) OnTick(tick *Tick) b3.Status {
+	v, err := this.expr.Eval(tick.Blackboard.GetMem)
+	if err != nil {
+		tick.SetError(this, err)
+		return b3.FAILURE
+	}
+	if this.target != "" {
+		tick.Blackboard.SetMem(this.target, v)
+		return b3.SUCCESS
+	}
+	if expr.Truth(v) {
+		return b3.SUCCESS
+	}
+	return b3.FAILURE
+}
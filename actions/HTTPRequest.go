@@ -0,0 +1,175 @@
+package actions
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+// httpCall is a request in flight, kept in the node memory.
+type httpCall struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	status int
+	body   []byte
+	err    error
+}
+
+/**
+ * HTTPRequest performs an HTTP request without blocking the tick: the
+ * request starts when the node opens and the node returns `RUNNING` until
+ * the response arrived. It then writes the status code and the body (a
+ * string) to the statusKey and bodyKey keys of the global memory, when
+ * set, and returns `SUCCESS` for a 2xx status, `FAILURE` otherwise. A
+ * transport error or the timeout is a failure, its message written to
+ * errorKey; halting the node cancels the request.
+ *
+ * The url and body are templates: "{key}" placeholders are replaced with
+ * the values of the blackboard as in RenderTitle, escaped with
+ * url.PathEscape in the url.
+ *
+ * @module b3
+ * @class HTTPRequest
+ * @extends Action
+**/
+type HTTPRequest struct {
+	Action
+	method    string
+	url       string
+	body      string
+	headers   map[string]string
+	timeout   time.Duration
+	statusKey string
+	bodyKey   string
+	errorKey  string
+}
+
+// HTTPClient is the client of the HTTPRequest nodes.
+var HTTPClient = http.DefaultClient
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **method**    (*String*)  "GET" by default.
+ * - **url**       (*String*)  The url template.
+ * - **body**      (*String*)  The body template.
+ * - **headers**   (*Object*)  Header values by name.
+ * - **timeout**   (*Integer*) Timeout, in milliseconds (default 10000).
+ * - **statusKey** (*String*)  Key of the status code.
+ * - **bodyKey**   (*String*)  Key of the response body.
+ * - **errorKey**  (*String*)  Key of the transport error.
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *HTTPRequest) Initialize(setting *BTNodeCfg) {
+	this.Action.Initialize(setting)
+	this.url = setting.GetPropertyAsString("url")
+	if this.url == "" {
+		panic("url parameter in HTTPRequest action is an obligatory parameter")
+	}
+	this.method = http.MethodGet
+	if _, ok := setting.Properties["method"]; ok {
+		this.method = strings.ToUpper(setting.GetPropertyAsString("method"))
+	}
+	this.timeout = 10 * time.Second
+	if _, ok := setting.Properties["timeout"]; ok {
+		this.timeout = time.Duration(setting.GetPropertyAsInt64("timeout")) * time.Millisecond
+	}
+	this.body, this.statusKey, this.bodyKey, this.errorKey = "", "", "", ""
+	if _, ok := setting.Properties["body"]; ok {
+		this.body = setting.GetPropertyAsString("body")
+	}
+	if _, ok := setting.Properties["statusKey"]; ok {
+		this.statusKey = setting.GetPropertyAsString("statusKey")
+	}
+	if _, ok := setting.Properties["bodyKey"]; ok {
+		this.bodyKey = setting.GetPropertyAsString("bodyKey")
+	}
+	if _, ok := setting.Properties["errorKey"]; ok {
+		this.errorKey = setting.GetPropertyAsString("errorKey")
+	}
+	this.headers = make(map[string]string)
+	if headers, ok := setting.Properties["headers"].(map[string]interface{}); ok {
+		for name, value := range headers {
+			this.headers[name], _ = value.(string)
+		}
+	}
+}
+
+func (this *HTTPRequest) OnOpen(tick *Tick) {
+	treeID := tick.GetTree().GetID()
+	rawURL := RenderTemplate(this.url, tick.Blackboard, treeID, this.GetID(), url.PathEscape)
+	body := RenderTemplate(this.body, tick.Blackboard, treeID, this.GetID(), nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), this.timeout)
+	call := &httpCall{cancel: cancel, done: make(chan struct{})}
+	tick.Blackboard.Set("call", call, treeID, this.GetID())
+
+	req, err := http.NewRequestWithContext(ctx, this.method, rawURL, strings.NewReader(body))
+	if err != nil {
+		call.err = err
+		close(call.done)
+		return
+	}
+	for name, value := range this.headers {
+		req.Header.Set(name, value)
+	}
+	go func() {
+		defer close(call.done)
+		resp, err := HTTPClient.Do(req)
+		if err != nil {
+			call.err = err
+			return
+		}
+		defer resp.Body.Close()
+		call.status = resp.StatusCode
+		call.body, call.err = ioutil.ReadAll(resp.Body)
+	}()
+}
+
+func (this *HTTPRequest) OnTick(tick *Tick) b3.Status {
+	call, ok := tick.Blackboard.Get("call", tick.GetTree().GetID(), this.GetID()).(*httpCall)
+	if !ok {
+		return b3.ERROR
+	}
+	select {
+	case <-call.done:
+	default:
+		return b3.RUNNING
+	}
+	if call.err != nil {
+		if this.errorKey != "" {
+			tick.Blackboard.SetMem(this.errorKey, call.err.Error())
+		}
+		return b3.FAILURE
+	}
+	if this.statusKey != "" {
+		tick.Blackboard.SetMem(this.statusKey, call.status)
+	}
+	if this.bodyKey != "" {
+		tick.Blackboard.SetMem(this.bodyKey, string(call.body))
+	}
+	if call.status < 200 || call.status > 299 {
+		return b3.FAILURE
+	}
+	return b3.SUCCESS
+}
+
+func (this *HTTPRequest) OnClose(tick *Tick) {
+	treeID := tick.GetTree().GetID()
+	if call, ok := tick.Blackboard.Get("call", treeID, this.GetID()).(*httpCall); ok {
+		call.cancel()
+	}
+	tick.Blackboard.Set("call", nil, treeID, this.GetID())
+}
@@ -1,24 +1,54 @@
 package actions
 
 import (
-	"fmt"
-
 	b3 "github.com/youngtrips/behavior3go"
 	. "github.com/youngtrips/behavior3go/config"
 	. "github.com/youngtrips/behavior3go/core"
 )
 
+/**
+ * Log sends a message to the logger of the tree (see
+ * BehaviorTree.SetLogger) and returns `SUCCESS`. The message is a
+ * template: "{key}" placeholders are replaced with the values of the
+ * blackboard as in RenderTitle.
+ *
+ * @module b3
+ * @class Log
+ * @extends Action
+**/
 type Log struct {
 	Action
-	info string
+	info  string
+	level LogLevel
 }
 
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **info**  (*String*) The message template.
+ * - **level** (*String*) "debug", "info" (default), "warn" or "error".
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
 func (this *Log) Initialize(setting *BTNodeCfg) {
 	this.Action.Initialize(setting)
 	this.info = setting.GetPropertyAsString("info")
+	this.level = LevelInfo
+	if _, ok := setting.Properties["level"]; ok {
+		level, err := ParseLogLevel(setting.GetPropertyAsString("level"))
+		if err != nil {
+			panic("Log action: " + err.Error())
+		}
+		this.level = level
+	}
 }
 
 func (this *Log) OnTick(tick *Tick) b3.Status {
-	fmt.Println("log:", this.info)
+	msg := RenderTemplate(this.info, tick.Blackboard, tick.GetTree().GetID(), this.GetID(), nil)
+	LogAt(tick.Logger(), this.level, "log: ", msg)
 	return b3.SUCCESS
 }
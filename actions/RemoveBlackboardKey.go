@@ -0,0 +1,71 @@
+package actions
+
+import (
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * RemoveBlackboardKey removes a key from the blackboard and returns
+ * `SUCCESS`, so a tree can clear transient facts (a target, a flag) once
+ * handled. The key is removed from the global memory, from the memory of
+ * the tree, or from the memory of a node of the tree given by its id.
+ *
+ * @module b3
+ * @class RemoveBlackboardKey
+ * @extends Action
+**/
+type RemoveBlackboardKey struct {
+	Action
+	key    string
+	scope  string
+	nodeID string
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **key**   (*String*) The key to remove.
+ * - **scope** (*String*) "global" (default), "tree" or "node".
+ * - **node**  (*String*) Id of the node, for the node scope.
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *RemoveBlackboardKey) Initialize(setting *BTNodeCfg) {
+	this.Action.Initialize(setting)
+	this.key = setting.GetPropertyAsString("key")
+	if this.key == "" {
+		panic("key parameter in RemoveBlackboardKey action is an obligatory parameter")
+	}
+	this.scope = "global"
+	if _, ok := setting.Properties["scope"]; ok {
+		this.scope = setting.GetPropertyAsString("scope")
+	}
+	switch this.scope {
+	case "global", "tree":
+	case "node":
+		this.nodeID = setting.GetPropertyAsString("node")
+		if this.nodeID == "" {
+			panic("node parameter in RemoveBlackboardKey action is obligatory for the node scope")
+		}
+	default:
+		panic("RemoveBlackboardKey action: unknown scope " + this.scope)
+	}
+}
+
+func (this *RemoveBlackboardKey) OnTick(tick *Tick) b3.Status {
+	switch this.scope {
+	case "tree":
+		tick.Blackboard.RemoveKey(this.key, tick.GetTree().GetID(), "")
+	case "node":
+		tick.Blackboard.RemoveKey(this.key, tick.GetTree().GetID(), this.nodeID)
+	default:
+		tick.Blackboard.Remove(this.key)
+	}
+	return b3.SUCCESS
+}
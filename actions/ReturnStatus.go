@@ -0,0 +1,44 @@
+package actions
+
+import (
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * ReturnStatus always returns the status of its "status" property, and
+ * covers Succeeder, Failer, Runner and Error with a single node.
+ *
+ * @module b3
+ * @class ReturnStatus
+ * @extends Action
+**/
+type ReturnStatus struct {
+	Action
+	status b3.Status
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **status** (*String*) "success", "failure", "running" or "error".
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *ReturnStatus) Initialize(setting *BTNodeCfg) {
+	this.Action.Initialize(setting)
+	status, err := b3.ParseStatus(setting.GetPropertyAsString("status"))
+	if err != nil {
+		panic("ReturnStatus action: " + err.Error())
+	}
+	this.status = status
+}
+
+func (this *ReturnStatus) OnTick(tick *Tick) b3.Status {
+	return this.status
+}
@@ -0,0 +1,45 @@
+package actions
+
+import (
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * StampTime writes the current time of the blackboard clock, in
+ * milliseconds since the epoch, to a key of the global memory and returns
+ * `SUCCESS`. See the CooldownElapsed condition.
+ *
+ * @module b3
+ * @class StampTime
+ * @extends Action
+**/
+type StampTime struct {
+	Action
+	key string
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **key** (*String*) The key of the stamp.
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *StampTime) Initialize(setting *BTNodeCfg) {
+	this.Action.Initialize(setting)
+	this.key = setting.GetPropertyAsString("key")
+	if this.key == "" {
+		panic("key parameter in StampTime action is an obligatory parameter")
+	}
+}
+
+func (this *StampTime) OnTick(tick *Tick) b3.Status {
+	tick.Blackboard.SetMem(this.key, tick.Now().UnixNano()/1000000)
+	return b3.SUCCESS
+}
@@ -4,11 +4,12 @@ import (
 	b3 "github.com/youngtrips/behavior3go"
 	. "github.com/youngtrips/behavior3go/config"
 	. "github.com/youngtrips/behavior3go/core"
-	"time"
 )
 
 /**
- * Wait a few seconds.
+ * Wait a few seconds. With the key property, the duration is read from
+ * that key of the global memory when the node opens, falling back on
+ * milliseconds when it is not set or not a number.
  *
  * @module b3
  * @class Wait
@@ -17,6 +18,7 @@ import (
 type Wait struct {
 	Action
 	endTime int64
+	key     string
 }
 
 /**
@@ -26,6 +28,7 @@ type Wait struct {
  *
  * - **milliseconds** (*Integer*) Maximum time, in milliseconds, a child
  *                                can execute.
+ * - **key**          (*String*)  Key of the duration, in milliseconds.
  *
  * @method Initialize
  * @param {Object} settings Object with parameters.
@@ -34,6 +37,10 @@ type Wait struct {
 func (this *Wait) Initialize(setting *BTNodeCfg) {
 	this.Action.Initialize(setting)
 	this.endTime = setting.GetPropertyAsInt64("milliseconds")
+	this.key = ""
+	if _, ok := setting.Properties["key"]; ok {
+		this.key = setting.GetPropertyAsString("key")
+	}
 }
 
 /**
@@ -42,8 +49,15 @@ func (this *Wait) Initialize(setting *BTNodeCfg) {
  * @param {Tick} tick A tick instance.
 **/
 func (this *Wait) OnOpen(tick *Tick) {
-	var startTime int64 = time.Now().UnixNano() / 1000000
+	var startTime int64 = tick.Now().UnixNano() / 1000000
 	tick.Blackboard.Set("startTime", startTime, tick.GetTree().GetID(), this.GetID())
+	endTime := this.endTime
+	if this.key != "" {
+		if ms, ok := CoerceFloat64(tick.Blackboard.GetMem(this.key)); ok {
+			endTime = int64(ms)
+		}
+	}
+	tick.Blackboard.Set("endTime", endTime, tick.GetTree().GetID(), this.GetID())
 }
 
 /**
@@ -53,10 +67,11 @@ func (this *Wait) OnOpen(tick *Tick) {
  * @return {Constant} A state constant.
 **/
 func (this *Wait) OnTick(tick *Tick) b3.Status {
-	var currTime int64 = time.Now().UnixNano() / 1000000
+	var currTime int64 = tick.Now().UnixNano() / 1000000
 	var startTime = tick.Blackboard.GetInt64("startTime", tick.GetTree().GetID(), this.GetID())
+	var endTime = tick.Blackboard.GetInt64("endTime", tick.GetTree().GetID(), this.GetID())
 	//fmt.Println("wait:",this.GetTitle(),tick.GetLastSubTree(),"=>", currTime-startTime)
-	if currTime-startTime > this.endTime {
+	if currTime-startTime > endTime {
 		return b3.SUCCESS
 	}
 
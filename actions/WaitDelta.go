@@ -0,0 +1,65 @@
+package actions
+
+import (
+	"time"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * WaitDelta waits like Wait, counting the delta time of the ticks (see
+ * BehaviorTree.TickDelta) instead of reading the clock; ticked with Tick
+ * it never ends.
+ *
+ * @module b3
+ * @class WaitDelta
+ * @extends Action
+**/
+type WaitDelta struct {
+	Action
+	duration time.Duration
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **milliseconds** (*Integer*) Simulation time to wait, in milliseconds.
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *WaitDelta) Initialize(setting *BTNodeCfg) {
+	this.Action.Initialize(setting)
+	this.duration = time.Duration(setting.GetPropertyAsInt64("milliseconds")) * time.Millisecond
+}
+
+/**
+ * Open method.
+ * @method open
+ * @param {Tick} tick A tick instance.
+**/
+func (this *WaitDelta) OnOpen(tick *Tick) {
+	tick.Blackboard.Set("elapsed", time.Duration(0), tick.GetTree().GetID(), this.GetID())
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (this *WaitDelta) OnTick(tick *Tick) b3.Status {
+	elapsed, _ := tick.Blackboard.Get("elapsed", tick.GetTree().GetID(), this.GetID()).(time.Duration)
+	elapsed += tick.DeltaTime
+	tick.Blackboard.Set("elapsed", elapsed, tick.GetTree().GetID(), this.GetID())
+	if elapsed >= this.duration {
+		return b3.SUCCESS
+	}
+
+	return b3.RUNNING
+}
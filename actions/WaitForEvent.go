@@ -0,0 +1,138 @@
+package actions
+
+import (
+	"sync"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+// eventMailbox receives the event awaited by a WaitForEvent node, possibly
+// from another goroutine.
+type eventMailbox struct {
+	mutex    sync.Mutex
+	received bool
+	event    Event
+	cancel   func()
+}
+
+func (this *eventMailbox) put(event Event) {
+	this.mutex.Lock()
+	if !this.received {
+		this.received, this.event = true, event
+	}
+	this.mutex.Unlock()
+}
+
+func (this *eventMailbox) take() (Event, bool) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return this.event, this.received
+}
+
+/**
+ * WaitForEvent returns `RUNNING` until an event is published on a topic,
+ * then `SUCCESS`, storing its payload under the payloadKey key of the
+ * global memory when set. The node subscribes when it opens, to the bus
+ * of the blackboard or to GlobalEvents with the global scope, so events
+ * published before are missed. With a timeout, it returns `FAILURE` once
+ * it elapsed without the event, read from the clock of the blackboard.
+ * The topic is a template, rendered on open as in EmitEvent.
+ *
+ * The event may be published from any goroutine: it is only written to
+ * the blackboard by the next tick of the node.
+ *
+ * @module b3
+ * @class WaitForEvent
+ * @extends Action
+**/
+type WaitForEvent struct {
+	Action
+	event        string
+	global       bool
+	payloadKey   string
+	milliseconds int64
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **event**      (*String*)  The topic template.
+ * - **scope**      (*String*)  "blackboard" (default) or "global".
+ * - **payloadKey** (*String*)  Global memory key of the payload.
+ * - **timeout**    (*Integer*) Maximum wait, in milliseconds.
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *WaitForEvent) Initialize(setting *BTNodeCfg) {
+	this.Action.Initialize(setting)
+	this.event = setting.GetPropertyAsString("event")
+	if this.event == "" {
+		panic("event parameter in WaitForEvent action is an obligatory parameter")
+	}
+	this.global = false
+	if _, ok := setting.Properties["scope"]; ok {
+		switch scope := setting.GetPropertyAsString("scope"); scope {
+		case "blackboard":
+		case "global":
+			this.global = true
+		default:
+			panic("WaitForEvent action: unknown scope " + scope)
+		}
+	}
+	this.payloadKey, this.milliseconds = "", 0
+	if _, ok := setting.Properties["payloadKey"]; ok {
+		this.payloadKey = setting.GetPropertyAsString("payloadKey")
+	}
+	if _, ok := setting.Properties["timeout"]; ok {
+		this.milliseconds = setting.GetPropertyAsInt64("timeout")
+	}
+}
+
+func (this *WaitForEvent) OnOpen(tick *Tick) {
+	treeID := tick.GetTree().GetID()
+	bus := tick.Events()
+	if this.global {
+		bus = GlobalEvents()
+	}
+	topic := RenderTemplate(this.event, tick.Blackboard, treeID, this.GetID(), nil)
+	mailbox := &eventMailbox{}
+	mailbox.cancel = bus.Subscribe(topic, mailbox.put)
+	tick.Blackboard.Set("mailbox", mailbox, treeID, this.GetID())
+	tick.Blackboard.Set("startTime", tick.Now().UnixNano()/1000000, treeID, this.GetID())
+}
+
+func (this *WaitForEvent) OnTick(tick *Tick) b3.Status {
+	treeID := tick.GetTree().GetID()
+	mailbox, ok := tick.Blackboard.Get("mailbox", treeID, this.GetID()).(*eventMailbox)
+	if !ok {
+		return b3.ERROR
+	}
+	if event, received := mailbox.take(); received {
+		if this.payloadKey != "" {
+			tick.Blackboard.SetMem(this.payloadKey, event.Payload)
+		}
+		return b3.SUCCESS
+	}
+	if this.milliseconds > 0 {
+		var currTime int64 = tick.Now().UnixNano() / 1000000
+		var startTime int64 = tick.Blackboard.GetInt64("startTime", treeID, this.GetID())
+		if currTime-startTime >= this.milliseconds {
+			return b3.FAILURE
+		}
+	}
+	return b3.RUNNING
+}
+
+func (this *WaitForEvent) OnClose(tick *Tick) {
+	treeID := tick.GetTree().GetID()
+	if mailbox, ok := tick.Blackboard.Get("mailbox", treeID, this.GetID()).(*eventMailbox); ok {
+		mailbox.cancel()
+	}
+	tick.Blackboard.Set("mailbox", nil, treeID, this.GetID())
+}
@@ -0,0 +1,376 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	b3 "github.com/youngtrips/behavior3go"
+	"github.com/youngtrips/behavior3go/core"
+)
+
+var (
+	ErrBusy    = errors.New("agents: shard queue is full")
+	ErrStopped = errors.New("agents: manager is stopped")
+)
+
+type Agent struct {
+	ID         string
+	Zone       string
+	Tree       *core.BehaviorTree
+	Blackboard *core.Blackboard
+	Target     interface{}
+	// blackboard of the shard of the agent, set when it is added; shared
+	// by the agents of the shard, see Manager.DoShard
+	Shard *core.Blackboard
+
+	//上一帧Tick的返回值
+	Status b3.Status
+}
+
+func NewAgent(id string, zone string, tree *core.BehaviorTree, target interface{}) *Agent {
+	return &Agent{ID: id, Zone: zone, Tree: tree, Blackboard: core.NewBlackboard(nil), Target: target}
+}
+
+type Options struct {
+	//分片(工作协程)数量，默认runtime.NumCPU()
+	Shards int
+	//每个分片的命令队列长度，默认1024
+	QueueSize int
+}
+
+/**
+ * Manager owns a large population of agents and ticks them on a fixed set
+ * of worker goroutines (shards). Agents are assigned to a shard by hashing
+ * their ID; a shard is the only goroutine touching its agents, so their
+ * trees' nodes and blackboards need no locking. Each shard also has a
+ * blackboard of its own, shared by its agents (Agent.Shard) for what they
+ * know in common, without locking either. Everything else (adding,
+ * removing, reading or writing an agent) goes through the shard queue:
+ *
+ *     m := agents.NewManager(agents.Options{Shards: 8})
+ *     m.Start()
+ *     m.Add(agents.NewAgent("npc-1", "forest", tree, npc))
+ *     go m.Run(ctx, 100*time.Millisecond)
+ *     m.Do("npc-1", func(a *agents.Agent) { a.Blackboard.SetMem("alarm", true) })
+ *
+ * Backpressure is explicit: queueing fails with ErrBusy when a shard queue
+ * is full, and a frame is skipped (and counted) for a shard still busy with
+ * the previous one. Stats exposes both per shard.
+ *
+ * @class Manager
+**/
+type Manager struct {
+	shards []*shard
+
+	zoneMutex sync.RWMutex
+	paused    map[string]bool
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func NewManager(opts Options) *Manager {
+	if opts.Shards <= 0 {
+		opts.Shards = runtime.NumCPU()
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 1024
+	}
+	m := &Manager{
+		paused: make(map[string]bool),
+		stop:   make(chan struct{}),
+	}
+	for i := 0; i < opts.Shards; i++ {
+		m.shards = append(m.shards, &shard{
+			index:   i,
+			manager: m,
+			agents:  make(map[string]*Agent),
+			board:   core.NewBlackboard(nil),
+			cmds:    make(chan func(*shard), opts.QueueSize),
+			frames:  make(chan struct{}, 1),
+		})
+	}
+	return m
+}
+
+// Start launches the shard goroutines.
+func (this *Manager) Start() {
+	for _, s := range this.shards {
+		this.wg.Add(1)
+		go s.loop()
+	}
+}
+
+// Stop stops the shards after the work already queued; it blocks until
+// they exit.
+func (this *Manager) Stop() {
+	this.stopOnce.Do(func() {
+		close(this.stop)
+	})
+	this.wg.Wait()
+}
+
+func (this *Manager) shardOf(id string) *shard {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return this.shards[h.Sum32()%uint32(len(this.shards))]
+}
+
+func (this *Manager) enqueue(s *shard, cmd func(*shard)) error {
+	select {
+	case <-this.stop:
+		return ErrStopped
+	default:
+	}
+	select {
+	case s.cmds <- cmd:
+		return nil
+	default:
+		atomic.AddUint64(&s.rejected, 1)
+		return ErrBusy
+	}
+}
+
+// Add queues agent for insertion, replacing (and aborting, see Remove) an
+// agent with the same ID.
+func (this *Manager) Add(agent *Agent) error {
+	return this.enqueue(this.shardOf(agent.ID), func(s *shard) {
+		if old, ok := s.agents[agent.ID]; !ok {
+			atomic.AddInt64(&s.count, 1)
+		} else if old != agent {
+			old.abort()
+		}
+		agent.Shard = s.board
+		s.agents[agent.ID] = agent
+	})
+}
+
+// Remove queues the removal of agent id. Its tree is aborted (see
+// BehaviorTree.Abort), so its running nodes are closed.
+func (this *Manager) Remove(id string) error {
+	return this.enqueue(this.shardOf(id), func(s *shard) {
+		if agent, ok := s.agents[id]; ok {
+			delete(s.agents, id)
+			atomic.AddInt64(&s.count, -1)
+			agent.abort()
+		}
+	})
+}
+
+func (this *Agent) abort() {
+	this.Tree.Abort(this.Target, this.Blackboard)
+}
+
+/**
+ * Do runs f with agent id on its shard goroutine, between two frames. It
+ * does not wait for f; f is called with nil if the agent does not exist.
+**/
+func (this *Manager) Do(id string, f func(agent *Agent)) error {
+	return this.enqueue(this.shardOf(id), func(s *shard) {
+		f(s.agents[id])
+	})
+}
+
+// DoShard runs f with the blackboard of the shard of agent id on the
+// shard goroutine, as Do.
+func (this *Manager) DoShard(id string, f func(shard *core.Blackboard)) error {
+	return this.enqueue(this.shardOf(id), func(s *shard) {
+		f(s.board)
+	})
+}
+
+// DoWait is Do but waits for f to return.
+func (this *Manager) DoWait(id string, f func(agent *Agent)) error {
+	done := make(chan struct{})
+	err := this.Do(id, func(agent *Agent) {
+		defer close(done)
+		f(agent)
+	})
+	if err != nil {
+		return err
+	}
+	select {
+	case <-done:
+		return nil
+	case <-this.stop:
+		return ErrStopped
+	}
+}
+
+// Flush waits until every shard has processed what was queued before.
+func (this *Manager) Flush() error {
+	var wg sync.WaitGroup
+	for _, s := range this.shards {
+		wg.Add(1)
+		if err := this.enqueue(s, func(*shard) { wg.Done() }); err != nil {
+			wg.Done()
+			return err
+		}
+	}
+	wg.Wait()
+	return nil
+}
+
+// PauseZone stops ticking the agents of zone, ResumeZone restarts them.
+func (this *Manager) PauseZone(zone string) {
+	this.zoneMutex.Lock()
+	this.paused[zone] = true
+	this.zoneMutex.Unlock()
+}
+
+func (this *Manager) ResumeZone(zone string) {
+	this.zoneMutex.Lock()
+	delete(this.paused, zone)
+	this.zoneMutex.Unlock()
+}
+
+func (this *Manager) IsPaused(zone string) bool {
+	this.zoneMutex.RLock()
+	defer this.zoneMutex.RUnlock()
+	return this.paused[zone]
+}
+
+func (this *Manager) pausedZones() map[string]bool {
+	this.zoneMutex.RLock()
+	defer this.zoneMutex.RUnlock()
+	if len(this.paused) == 0 {
+		return nil
+	}
+	zones := make(map[string]bool, len(this.paused))
+	for zone := range this.paused {
+		zones[zone] = true
+	}
+	return zones
+}
+
+/**
+ * Tick asks every shard to tick its agents once and returns without
+ * waiting. A shard that has not finished the previous frame yet skips this
+ * one.
+**/
+func (this *Manager) Tick() {
+	for _, s := range this.shards {
+		select {
+		case s.frames <- struct{}{}:
+		default:
+			atomic.AddUint64(&s.skipped, 1)
+		}
+	}
+}
+
+// Run calls Tick every interval until ctx is done or the manager stops.
+func (this *Manager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-this.stop:
+			return
+		case <-ticker.C:
+			this.Tick()
+		}
+	}
+}
+
+type ShardStats struct {
+	Shard  int
+	Agents int
+	//排队中的命令数
+	Queued int
+	Frames uint64
+	//上一帧还没跑完而跳过的帧数
+	Skipped uint64
+	//队列满被拒绝的命令数
+	Rejected  uint64
+	LastFrame time.Duration
+	MaxFrame  time.Duration
+}
+
+// Stats returns the load and backpressure counters of every shard.
+func (this *Manager) Stats() []ShardStats {
+	stats := make([]ShardStats, 0, len(this.shards))
+	for _, s := range this.shards {
+		stats = append(stats, ShardStats{
+			Shard:     s.index,
+			Agents:    int(atomic.LoadInt64(&s.count)),
+			Queued:    len(s.cmds),
+			Frames:    atomic.LoadUint64(&s.frameCount),
+			Skipped:   atomic.LoadUint64(&s.skipped),
+			Rejected:  atomic.LoadUint64(&s.rejected),
+			LastFrame: time.Duration(atomic.LoadInt64(&s.lastFrame)),
+			MaxFrame:  time.Duration(atomic.LoadInt64(&s.maxFrame)),
+		})
+	}
+	return stats
+}
+
+// ------------------------shard-------------------------
+type shard struct {
+	//原子计数放在最前面，保证32位平台8字节对齐
+	count      int64
+	frameCount uint64
+	skipped    uint64
+	rejected   uint64
+	lastFrame  int64
+	maxFrame   int64
+
+	index   int
+	manager *Manager
+	agents  map[string]*Agent
+	// see Agent.Shard
+	board  *core.Blackboard
+	cmds   chan func(*shard)
+	frames chan struct{}
+}
+
+func (this *shard) loop() {
+	defer this.manager.wg.Done()
+	for {
+		select {
+		case cmd := <-this.cmds:
+			cmd(this)
+		case <-this.frames:
+			this.frame()
+		case <-this.manager.stop:
+			this.drain()
+			return
+		}
+	}
+}
+
+// 停止前执行已排队的命令，保证DoWait不会永远阻塞
+func (this *shard) drain() {
+	for {
+		select {
+		case cmd := <-this.cmds:
+			cmd(this)
+		default:
+			return
+		}
+	}
+}
+
+func (this *shard) frame() {
+	start := time.Now()
+	paused := this.manager.pausedZones()
+	for _, agent := range this.agents {
+		if paused != nil && paused[agent.Zone] {
+			continue
+		}
+		agent.Status = agent.Tree.Tick(agent.Target, agent.Blackboard)
+	}
+	elapsed := int64(time.Since(start))
+	atomic.StoreInt64(&this.lastFrame, elapsed)
+	if elapsed > atomic.LoadInt64(&this.maxFrame) {
+		atomic.StoreInt64(&this.maxFrame, elapsed)
+	}
+	atomic.AddUint64(&this.frameCount, 1)
+}
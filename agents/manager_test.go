@@ -0,0 +1,209 @@
+package agents_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	b3 "github.com/youngtrips/behavior3go"
+	"github.com/youngtrips/behavior3go/agents"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+	"github.com/youngtrips/behavior3go/loader"
+)
+
+// patrol counts its ticks in the agent memory and runs until closed.
+type patrol struct {
+	Action
+	closed *int64
+}
+
+func (this *patrol) OnTick(tick *Tick) b3.Status {
+	tick.Blackboard.SetMem("ticks", tick.Blackboard.GetInt("ticks", "", "")+1)
+	return b3.RUNNING
+}
+
+func (this *patrol) OnClose(tick *Tick) {
+	atomic.AddInt64(this.closed, 1)
+}
+
+func newTree(t *testing.T, closed *int64) *BehaviorTree {
+	t.Helper()
+	maps := b3.NewRegisterStructMaps()
+	RegisterNodeFactory(maps, "Patrol", func() IBaseNode {
+		return &patrol{closed: closed}
+	})
+	treeConfig, err := LoadTreeCfgFromBytes([]byte(`{
+		"id": "t", "title": "patrol", "root": "p",
+		"nodes": {"p": {"id": "p", "name": "Patrol", "category": "action"}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := loader.TryCreateBevTreeFromConfig(treeConfig, maps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+// frame ticks every shard once, after the queued commands, and waits
+// until they are done.
+func frame(t *testing.T, m *agents.Manager) {
+	t.Helper()
+	if err := m.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	before := frames(m)
+	m.Tick()
+	deadline := time.Now().Add(time.Second)
+	for frames(m) < before+uint64(len(m.Stats())) {
+		if time.Now().After(deadline) {
+			t.Fatal("frame not run")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func frames(m *agents.Manager) uint64 {
+	var n uint64
+	for _, s := range m.Stats() {
+		n += s.Frames
+	}
+	return n
+}
+
+func ticksOf(t *testing.T, m *agents.Manager, id string) int {
+	t.Helper()
+	ticks := -1
+	if err := m.DoWait(id, func(a *agents.Agent) {
+		if a != nil {
+			ticks = a.Blackboard.GetInt("ticks", "", "")
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return ticks
+}
+
+func TestManagerAddRemove(t *testing.T) {
+	var closed int64
+	tree := newTree(t, &closed)
+	m := agents.NewManager(agents.Options{Shards: 2})
+	m.Start()
+	defer m.Stop()
+	for _, id := range []string{"a", "b", "c"} {
+		if err := m.Add(agents.NewAgent(id, "forest", tree, nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	frame(t, m)
+	frame(t, m)
+	if ticks := ticksOf(t, m, "a"); ticks != 2 {
+		t.Error("ticks:", ticks)
+	}
+
+	if err := m.Remove("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt64(&closed); n != 1 {
+		t.Errorf("%d nodes closed by Remove, want 1", n)
+	}
+	if ticks := ticksOf(t, m, "a"); ticks != -1 {
+		t.Error("removed agent still there")
+	}
+	agentCount := 0
+	for _, s := range m.Stats() {
+		agentCount += s.Agents
+	}
+	if agentCount != 2 {
+		t.Error("agents in stats:", agentCount)
+	}
+
+	// replacing an agent aborts the previous one
+	if err := m.Add(agents.NewAgent("b", "forest", tree, nil)); err != nil {
+		t.Fatal(err)
+	}
+	m.Flush()
+	if n := atomic.LoadInt64(&closed); n != 2 {
+		t.Errorf("%d nodes closed after replacing, want 2", n)
+	}
+}
+
+func TestManagerPauseZone(t *testing.T) {
+	var closed int64
+	tree := newTree(t, &closed)
+	m := agents.NewManager(agents.Options{Shards: 1})
+	m.Start()
+	defer m.Stop()
+	m.Add(agents.NewAgent("wolf", "forest", tree, nil))
+	m.Add(agents.NewAgent("crab", "beach", tree, nil))
+	m.PauseZone("forest")
+	if !m.IsPaused("forest") || m.IsPaused("beach") {
+		t.Fatal("paused zones")
+	}
+	frame(t, m)
+	if wolf, crab := ticksOf(t, m, "wolf"), ticksOf(t, m, "crab"); wolf != 0 || crab != 1 {
+		t.Errorf("paused: wolf %d and crab %d ticks", wolf, crab)
+	}
+	m.ResumeZone("forest")
+	frame(t, m)
+	if wolf := ticksOf(t, m, "wolf"); wolf != 1 {
+		t.Errorf("resumed: wolf %d ticks", wolf)
+	}
+}
+
+func TestManagerDoAndShardBlackboard(t *testing.T) {
+	var closed int64
+	tree := newTree(t, &closed)
+	m := agents.NewManager(agents.Options{Shards: 1})
+	m.Start()
+	defer m.Stop()
+	m.Add(agents.NewAgent("a", "", tree, nil))
+	m.Add(agents.NewAgent("b", "", tree, nil))
+	if err := m.DoShard("a", func(shard *Blackboard) {
+		shard.SetMem("threat", "dragon")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var threat interface{}
+	if err := m.DoWait("b", func(a *agents.Agent) {
+		threat = a.Shard.GetMem("threat")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if threat != "dragon" {
+		t.Errorf("shard blackboard not shared: %v", threat)
+	}
+	found := true
+	m.DoWait("missing", func(a *agents.Agent) {
+		found = a != nil
+	})
+	if found {
+		t.Error("Do found a missing agent")
+	}
+}
+
+func TestManagerBackpressure(t *testing.T) {
+	// not started: nothing drains the queue nor runs the frames
+	m := agents.NewManager(agents.Options{Shards: 1, QueueSize: 1})
+	if err := m.Do("a", func(*agents.Agent) {}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Do("a", func(*agents.Agent) {}); err != agents.ErrBusy {
+		t.Fatal("full queue:", err)
+	}
+	m.Tick()
+	m.Tick()
+	stats := m.Stats()[0]
+	if stats.Queued != 1 || stats.Rejected != 1 || stats.Skipped != 1 {
+		t.Errorf("stats %+v", stats)
+	}
+	m.Stop()
+	if err := m.Do("a", func(*agents.Agent) {}); err != agents.ErrStopped {
+		t.Error("stopped:", err)
+	}
+}
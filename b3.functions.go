@@ -69,10 +69,12 @@ func MinInt(a int, b int) int {
 //定义注册结构map
 type RegisterStructMaps struct {
 	maps map[string]reflect.Type
+	// see RegisterFactory
+	factories map[string]func() interface{}
 }
 
 func NewRegisterStructMaps() *RegisterStructMaps {
-	return &RegisterStructMaps{make(map[string]reflect.Type)}
+	return &RegisterStructMaps{make(map[string]reflect.Type), make(map[string]func() interface{})}
 }
 
 //根据name初始化结构
@@ -81,6 +83,9 @@ func (rsm *RegisterStructMaps) New(name string) (interface{}, error) {
 	//fmt.Println("New ", name)
 	var c interface{}
 	var err error
+	if factory, ok := rsm.factories[name]; ok {
+		return factory(), nil
+	}
 	if v, ok := rsm.maps[name]; ok {
 		c = reflect.New(v).Interface()
 		//fmt.Println("found ", name, "  ", reflect.TypeOf(c))
@@ -97,12 +102,26 @@ func (rsm *RegisterStructMaps) CheckElem(name string) bool {
 	if _, ok := rsm.maps[name]; ok {
 		return true
 	}
-	return false
+	_, ok := rsm.factories[name]
+	return ok
 }
 
 //根据名字注册实例
 func (rsm *RegisterStructMaps) Register(name string, c interface{}) {
+	delete(rsm.factories, name)
 	rsm.maps[name] = reflect.TypeOf(c).Elem()
 }
 
+/**
+ * RegisterFactory registers a function creating the nodes named name,
+ * called by New instead of reflect.New: it is faster, and can inject the
+ * dependencies of the node (a service, a config) through a closure.
+ * factory must return a new node, as a pointer, on each call; see
+ * core.RegisterNodeFactory for the typed form.
+**/
+func (rsm *RegisterStructMaps) RegisterFactory(name string, factory func() interface{}) {
+	delete(rsm.maps, name)
+	rsm.factories[name] = factory
+}
+
 
@@ -1,5 +1,10 @@
 package behavior3go
 
+import (
+	"fmt"
+	"strings"
+)
+
 //b3 define
 const (
 	VERSION = "0.2.0"
@@ -20,3 +25,33 @@ const (
 	RUNNING Status = 3
 	ERROR   Status = 4
 )
+
+func (this Status) String() string {
+	switch this {
+	case SUCCESS:
+		return "success"
+	case FAILURE:
+		return "failure"
+	case RUNNING:
+		return "running"
+	case ERROR:
+		return "error"
+	}
+	return "unknown"
+}
+
+// ParseStatus parses the name of a status, as returned by String, ignoring
+// case.
+func ParseStatus(s string) (Status, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "success":
+		return SUCCESS, nil
+	case "failure":
+		return FAILURE, nil
+	case "running":
+		return RUNNING, nil
+	case "error":
+		return ERROR, nil
+	}
+	return 0, fmt.Errorf("unknown status %q", s)
+}
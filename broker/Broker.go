@@ -0,0 +1,202 @@
+package broker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * Message is the broker neutral form of an incoming message. Adapters for
+ * NATS, Kafka, etc. fill in what their client provides.
+**/
+type Message struct {
+	Topic  string
+	Key    string
+	Data   []byte
+	Header map[string]string
+}
+
+type Subscription interface {
+	Unsubscribe() error
+}
+
+/**
+ * Subscriber is implemented by a thin wrapper around the broker client.
+ * For NATS:
+ *
+ *     type natsSubscriber struct{ nc *nats.Conn }
+ *
+ *     func (s natsSubscriber) Subscribe(topic string, h func(*broker.Message)) (broker.Subscription, error) {
+ *         return s.nc.Subscribe(topic, func(m *nats.Msg) {
+ *             h(&broker.Message{Topic: m.Subject, Data: m.Data})
+ *         })
+ *     }
+ *
+ * A Kafka consumer loop calls the handler for each fetched record and
+ * returns a Subscription that stops the loop.
+**/
+type Subscriber interface {
+	Subscribe(topic string, handler func(msg *Message)) (Subscription, error)
+}
+
+/**
+ * Agent is the tree/blackboard pair addressed by messages. Blackboards are
+ * not safe for concurrent use and broker callbacks may run on any goroutine,
+ * so every tick goes through the agent lock. If the game loop also ticks
+ * the agent it must use Agent.Tick as well.
+**/
+type Agent struct {
+	Tree       *BehaviorTree
+	Blackboard *Blackboard
+	Target     interface{}
+	mutex      sync.Mutex
+}
+
+func NewAgent(tree *BehaviorTree, target interface{}) *Agent {
+	return &Agent{Tree: tree, Blackboard: NewBlackboard(nil), Target: target}
+}
+
+// Tick ticks the agent's tree under the agent lock.
+func (this *Agent) Tick() b3.Status {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return this.Tree.Tick(this.Target, this.Blackboard)
+}
+
+// apply writes values to the global memory and ticks, atomically.
+func (this *Agent) apply(writes map[string]interface{}) b3.Status {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	for k, v := range writes {
+		this.Blackboard.SetMem(k, v)
+	}
+	return this.Tree.Tick(this.Target, this.Blackboard)
+}
+
+/**
+ * Mapper decodes a message into the id of the addressed agent and the
+ * values to write into its global blackboard memory before the tick.
+**/
+type Mapper func(msg *Message) (agentID string, writes map[string]interface{}, err error)
+
+var ErrNoAgent = errors.New("broker: no agent addressed by message")
+
+/**
+ * JSONMapper is the default Mapper. It expects payloads like
+ *
+ *     {"agent": "npc-42", "set": {"alarm": true, "target": "player-7"}}
+ *
+ * If "agent" is empty the message key is used instead.
+**/
+func JSONMapper(msg *Message) (string, map[string]interface{}, error) {
+	var payload struct {
+		Agent string                 `json:"agent"`
+		Set   map[string]interface{} `json:"set"`
+	}
+	if err := json.Unmarshal(msg.Data, &payload); err != nil {
+		return "", nil, err
+	}
+	if payload.Agent == "" {
+		payload.Agent = msg.Key
+	}
+	return payload.Agent, payload.Set, nil
+}
+
+/**
+ * Trigger subscribes to topics and, for every message, writes the mapped
+ * values to the addressed agent's blackboard and ticks its tree right away.
+ * This turns trees into event driven workflows instead of polling them at a
+ * fixed rate.
+ *
+ * @class Trigger
+**/
+type Trigger struct {
+	sub     Subscriber
+	resolve func(agentID string) *Agent
+	mapper  Mapper
+
+	onTick  func(agentID string, status b3.Status)
+	onError func(msg *Message, err error)
+
+	mutex sync.Mutex
+	subs  []Subscription
+}
+
+/**
+ * NewTrigger creates a trigger. resolve looks up the agent for an id and
+ * returns nil when it is unknown. A nil mapper means JSONMapper.
+**/
+func NewTrigger(sub Subscriber, resolve func(agentID string) *Agent, mapper Mapper) *Trigger {
+	if mapper == nil {
+		mapper = JSONMapper
+	}
+	return &Trigger{sub: sub, resolve: resolve, mapper: mapper}
+}
+
+// OnTick sets a callback receiving the status of every triggered tick.
+func (this *Trigger) OnTick(f func(agentID string, status b3.Status)) {
+	this.onTick = f
+}
+
+// OnError sets a callback for messages that could not be handled.
+func (this *Trigger) OnError(f func(msg *Message, err error)) {
+	this.onError = f
+}
+
+// Listen subscribes to topics. It may be called several times.
+func (this *Trigger) Listen(topics ...string) error {
+	for _, topic := range topics {
+		s, err := this.sub.Subscribe(topic, this.dispatch)
+		if err != nil {
+			return fmt.Errorf("broker: subscribe %s: %v", topic, err)
+		}
+		this.mutex.Lock()
+		this.subs = append(this.subs, s)
+		this.mutex.Unlock()
+	}
+	return nil
+}
+
+// Close unsubscribes from every topic, returning the first error.
+func (this *Trigger) Close() error {
+	this.mutex.Lock()
+	subs := this.subs
+	this.subs = nil
+	this.mutex.Unlock()
+
+	var first error
+	for _, s := range subs {
+		if err := s.Unsubscribe(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (this *Trigger) dispatch(msg *Message) {
+	if _, err := this.Handle(msg); err != nil && this.onError != nil {
+		this.onError(msg, err)
+	}
+}
+
+// Handle processes a single message synchronously.
+func (this *Trigger) Handle(msg *Message) (b3.Status, error) {
+	agentID, writes, err := this.mapper(msg)
+	if err != nil {
+		return b3.ERROR, err
+	}
+	agent := this.resolve(agentID)
+	if agent == nil {
+		return b3.ERROR, fmt.Errorf("%w: %q", ErrNoAgent, agentID)
+	}
+	status := agent.apply(writes)
+	if this.onTick != nil {
+		this.onTick(agentID, status)
+	}
+	return status, nil
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/youngtrips/behavior3go/config"
+)
+
+// generate returns the formatted source for project, read from source.
+func generate(pkg string, source string, project *config.BTProjectCfg) ([]byte, error) {
+	var buf bytes.Buffer
+	w := func(format string, args ...interface{}) {
+		fmt.Fprintf(&buf, format, args...)
+	}
+	names := newIdents()
+	w("// Tree ids\nconst (\n")
+	for _, tree := range project.Trees {
+		title := tree.Title
+		if title == "" {
+			title = tree.ID
+		}
+		w("\t%s = %s\n", names.get("Tree", title), strconv.Quote(tree.ID))
+	}
+	w(")\n\n")
+	if keys := blackboardKeys(project); len(keys) > 0 {
+		w("// Blackboard keys of the properties\nconst (\n")
+		for _, key := range keys {
+			w("\t%s = %s\n", names.get("Key", key), strconv.Quote(key))
+		}
+		w(")\n\n")
+	}
+
+	w("// Project returns the config of the project, a new one per call.\n")
+	w("func Project() *config.BTProjectCfg {\n")
+	w("\treturn &config.BTProjectCfg{\n")
+	w("\t\tID: %s,\n", strconv.Quote(project.ID))
+	w("\t\tSelect: %s,\n", strconv.Quote(project.Select))
+	w("\t\tScope: %s,\n", strconv.Quote(project.Scope))
+	if len(project.Roots) > 0 {
+		w("\t\tRoots: %s,\n", literal(stringList(project.Roots)))
+	}
+	w("\t\tTrees: []config.BTTreeCfg{\n")
+	for i := range project.Trees {
+		writeTree(&buf, &project.Trees[i])
+	}
+	w("\t\t},\n\t}\n}\n\n")
+
+	w("// NewTrees builds the trees of the project by id, see\n")
+	w("// loader.CreateProjectTrees.\n")
+	w("func NewTrees(extMap *b3.RegisterStructMaps) (map[string]*core.BehaviorTree, error) {\n")
+	w("\treturn loader.CreateProjectTrees(Project(), extMap)\n}\n")
+
+	var head bytes.Buffer
+	fmt.Fprintf(&head, "// Code generated by b3gen from %s. DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&head, "package %s\n\nimport (\n", pkg)
+	if bytes.Contains(buf.Bytes(), []byte("json.Number(")) {
+		head.WriteString("\t\"encoding/json\"\n\n")
+	}
+	head.WriteString("\tb3 \"github.com/youngtrips/behavior3go\"\n")
+	head.WriteString("\t\"github.com/youngtrips/behavior3go/config\"\n")
+	head.WriteString("\t\"github.com/youngtrips/behavior3go/core\"\n")
+	head.WriteString("\t\"github.com/youngtrips/behavior3go/loader\"\n)\n\n")
+	head.Write(buf.Bytes())
+	src, err := format.Source(head.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("b3gen: %v", err)
+	}
+	return src, nil
+}
+
+func writeTree(buf *bytes.Buffer, tree *config.BTTreeCfg) {
+	w := func(format string, args ...interface{}) {
+		fmt.Fprintf(buf, format, args...)
+	}
+	w("{\n")
+	w("ID: %s,\n", strconv.Quote(tree.ID))
+	w("Title: %s,\n", strconv.Quote(tree.Title))
+	w("Description: %s,\n", strconv.Quote(tree.Description))
+	w("Root: %s,\n", strconv.Quote(tree.Root))
+	w("Properties: %s,\n", literal(tree.Properties))
+	w("Nodes: map[string]config.BTNodeCfg{\n")
+	ids := make([]string, 0, len(tree.Nodes))
+	for id := range tree.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		node := tree.Nodes[id]
+		w("%s: {\n", strconv.Quote(id))
+		w("Id: %s,\n", strconv.Quote(node.Id))
+		w("Name: %s,\n", strconv.Quote(node.Name))
+		w("Category: %s,\n", strconv.Quote(node.Category))
+		w("Title: %s,\n", strconv.Quote(node.Title))
+		if node.Description != "" {
+			w("Description: %s,\n", strconv.Quote(node.Description))
+		}
+		if len(node.Children) > 0 {
+			w("Children: %s,\n", literal(stringList(node.Children)))
+		}
+		if node.Child != "" {
+			w("Child: %s,\n", strconv.Quote(node.Child))
+		}
+		if node.Parameters != nil {
+			w("Parameters: %s,\n", literal(node.Parameters))
+		}
+		w("Properties: %s,\n", literal(node.Properties))
+		w("},\n")
+	}
+	w("},\n},\n")
+}
+
+// stringList marks a []string for literal.
+type stringList []string
+
+// literal returns the Go expression of a decoded config value.
+func literal(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "nil"
+	case string:
+		return strconv.Quote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return "json.Number(" + strconv.Quote(v.String()) + ")"
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case stringList:
+		items := make([]string, len(v))
+		for i, s := range v {
+			items[i] = strconv.Quote(s)
+		}
+		return "[]string{" + strings.Join(items, ", ") + "}"
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, e := range v {
+			items[i] = literal(e)
+		}
+		return "[]interface{}{" + strings.Join(items, ", ") + "}"
+	case map[string]interface{}:
+		if v == nil {
+			return "map[string]interface{}(nil)"
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var b strings.Builder
+		b.WriteString("map[string]interface{}{")
+		for _, k := range keys {
+			fmt.Fprintf(&b, "\n%s: %s,", strconv.Quote(k), literal(v[k]))
+		}
+		if len(keys) > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("}")
+		return b.String()
+	}
+	// not produced by decoding
+	return fmt.Sprintf("%#v", v)
+}
+
+// blackboardKeys returns the string values of the "key" and "...Key"
+// properties of the nodes, sorted.
+func blackboardKeys(project *config.BTProjectCfg) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, tree := range project.Trees {
+		for _, node := range tree.Nodes {
+			for name, v := range node.Properties {
+				key, ok := v.(string)
+				if !ok || key == "" || seen[key] || !(name == "key" || strings.HasSuffix(name, "Key")) {
+					continue
+				}
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// idents makes unique exported identifiers.
+type idents map[string]bool
+
+func newIdents() idents {
+	return make(idents)
+}
+
+// get returns prefix followed by s in camel case, numbered if taken.
+func (this idents) get(prefix string, s string) string {
+	var b strings.Builder
+	b.WriteString(prefix)
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	name := b.String()
+	for i := 2; this[name]; i++ {
+		name = fmt.Sprintf("%s%d", b.String(), i)
+	}
+	this[name] = true
+	return name
+}
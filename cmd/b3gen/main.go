@@ -0,0 +1,72 @@
+// Command b3gen generates Go source building the trees of a project, so
+// they ship inside the binary without JSON parsing at runtime:
+//
+//	//go:generate go run github.com/youngtrips/behavior3go/cmd/b3gen -o monster_b3.go monster.b3
+//
+// The input is a behavior3editor raw project (.b3) or an exported project.
+// The generated file declares the project config as Go values, NewTrees
+// building its trees with loader.CreateProjectTrees, a Tree<Title>
+// constant per tree id and a Key<Name> constant per blackboard key named
+// by the properties ("key" and "...Key" properties).
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/youngtrips/behavior3go/config"
+)
+
+// loadProject reads a raw project or a project.
+func loadProject(path string) (*config.BTProjectCfg, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if probe["data"] != nil {
+		var raw config.RawProjectCfg
+		if err := config.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		return &raw.Data, nil
+	}
+	return config.ReadProjectCfg(path)
+}
+
+func main() {
+	out := flag.String("o", "", "output file (default: standard output)")
+	pkg := flag.String("pkg", os.Getenv("GOPACKAGE"), "package of the generated file (default: $GOPACKAGE, set by go generate)")
+	flag.Parse()
+	if flag.NArg() != 1 || *pkg == "" {
+		fmt.Fprintln(os.Stderr, "usage: b3gen [-o file] -pkg name project.b3")
+		os.Exit(2)
+	}
+
+	path := flag.Arg(0)
+	project, err := loadProject(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	src, err := generate(*pkg, filepath.Base(path), project)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
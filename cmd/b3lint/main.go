@@ -0,0 +1,90 @@
+// Command b3lint checks behavior tree files against the rules of a lint
+// config:
+//
+//	b3lint -config lint.json [-json] ai/monster.b3 ai/boss.json ...
+//
+// Files may be behavior3editor raw projects (.b3), exported projects or
+// single trees. It exits with status 1 if any finding is an error.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/youngtrips/behavior3go/config"
+	"github.com/youngtrips/behavior3go/lint"
+)
+
+// loadTrees reads the trees of a raw project, a project or a single tree.
+func loadTrees(path string) ([]config.BTTreeCfg, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	switch {
+	case probe["data"] != nil:
+		var raw config.RawProjectCfg
+		err = config.Unmarshal(data, &raw)
+		return raw.Data.Trees, err
+	case probe["trees"] != nil:
+		var project config.BTProjectCfg
+		err = config.Unmarshal(data, &project)
+		return project.Trees, err
+	default:
+		var tree config.BTTreeCfg
+		err = config.Unmarshal(data, &tree)
+		return []config.BTTreeCfg{tree}, err
+	}
+}
+
+func main() {
+	configPath := flag.String("config", "", "lint config (JSON)")
+	asJSON := flag.Bool("json", false, "print findings as a JSON array")
+	flag.Parse()
+
+	var cfg *lint.Config
+	if *configPath != "" {
+		var err error
+		if cfg, err = lint.LoadConfig(*configPath); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+	linter, err := lint.New(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	findings := []lint.Finding{}
+	for _, path := range flag.Args() {
+		trees, err := loadTrees(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		for i := range trees {
+			findings = append(findings, linter.LintTree(&trees[i])...)
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(findings)
+	} else {
+		for _, f := range findings {
+			fmt.Println(f)
+		}
+	}
+	if lint.HasErrors(findings) {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,197 @@
+package composites
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * Concurrent runs the blocking work of its children on goroutines, all at
+ * once, and aggregates their statuses like a Parallel with policies.
+ *
+ * On each tick the unfinished children implementing IConcurrentWork
+ * prepare their input, their Work runs concurrently, each on its own
+ * goroutine, under a shared deadline (timeout property), and once all
+ * returned or the deadline passed the unfinished children are executed in
+ * order on the ticking goroutine, reading their outcome with
+ * Tick.WorkResult (a late work gets context.DeadlineExceeded). The tick
+ * waits for the works meanwhile. The status of the children that finished
+ * is kept in the node memory, like Parallel does, so they are neither
+ * executed nor their work run again before the node closes. Only Work runs off the ticking goroutine and
+ * it never gets the tick nor the blackboard: that is how the composite
+ * keeps the blackboard, which has no locking, safe. Children without work
+ * are simply executed.
+ *
+ * The node returns SUCCESS once successPolicy children succeeded ("one",
+ * "all" by default, or a count), FAILURE once failurePolicy children
+ * failed or success is out of reach, RUNNING otherwise; running children
+ * are halted when the result is decided.
+ *
+ * @module b3
+ * @class Concurrent
+ * @extends CompositeHelper
+**/
+type Concurrent struct {
+	CompositeHelper
+	success int
+	failure int
+	timeout time.Duration
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **successPolicy** (*String*)  "one", "all" (default) or a count.
+ * - **failurePolicy** (*String*)  "one", "all" or a count.
+ * - **timeout**       (*Integer*) Deadline of the works, in milliseconds
+ *                                 (default 1000).
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *Concurrent) Initialize(setting *BTNodeCfg) {
+	this.CompositeHelper.Initialize(setting)
+	if v, ok := setting.Properties["successPolicy"]; ok {
+		this.success = parsePolicy(v)
+	}
+	if v, ok := setting.Properties["failurePolicy"]; ok {
+		this.failure = parsePolicy(v)
+	}
+	this.timeout = time.Second
+	if _, ok := setting.Properties["timeout"]; ok {
+		this.timeout = time.Duration(setting.GetPropertyAsInt64("timeout")) * time.Millisecond
+	}
+}
+
+/**
+ * Open method.
+ * @method open
+ * @param {b3.Tick} tick A tick instance.
+**/
+func (this *Concurrent) OnOpen(tick *Tick) {
+	this.CompositeHelper.OnOpen(tick)
+	tick.Blackboard.Set("childStatus", nil, tick.GetTree().GetID(), this.GetID())
+}
+
+// runWork runs the works of the unfinished children and records their
+// outcomes.
+func (this *Concurrent) runWork(tick *Tick, done []b3.Status) {
+	type job struct {
+		node  IBaseNode
+		work  IConcurrentWork
+		input interface{}
+	}
+	var jobs []job
+	for i := 0; i < this.GetChildCount(); i++ {
+		if done[i] != 0 {
+			continue
+		}
+		child := this.GetChild(i)
+		if w, ok := child.(IConcurrentWork); ok {
+			jobs = append(jobs, job{child, w, w.PrepareWork(tick)})
+		}
+	}
+	if len(jobs) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), this.timeout)
+	defer cancel()
+	type result struct {
+		value interface{}
+		err   error
+	}
+	results := make([]chan result, len(jobs))
+	var wg sync.WaitGroup
+	for j := range jobs {
+		results[j] = make(chan result, 1)
+		wg.Add(1)
+		go func(j int) {
+			defer wg.Done()
+			value, err := jobs[j].work.Work(ctx, jobs[j].input)
+			results[j] <- result{value, err}
+		}(j)
+	}
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-ctx.Done():
+	}
+	for j := range jobs {
+		select {
+		case r := <-results[j]:
+			tick.SetWorkResult(jobs[j].node, r.value, r.err)
+		default:
+			tick.SetWorkResult(jobs[j].node, nil, context.DeadlineExceeded)
+		}
+	}
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (this *Concurrent) OnTick(tick *Tick) b3.Status {
+	treeID, nodeID := tick.GetTree().GetID(), this.GetID()
+	count := this.GetChildCount()
+	done, _ := tick.Blackboard.Get("childStatus", treeID, nodeID).([]b3.Status)
+	if len(done) != count {
+		done = make([]b3.Status, count)
+	} else {
+		done = append([]b3.Status(nil), done...)
+	}
+	this.runWork(tick, done)
+
+	success, failure := this.success, this.failure
+	if success <= 0 || success > count {
+		success = count
+	}
+	if failure <= 0 || failure > count {
+		failure = count
+	}
+	successes, failures := 0, 0
+	for i := 0; i < count; i++ {
+		if done[i] == 0 {
+			done[i] = this.GetChild(i).Execute(tick)
+			if done[i] == b3.RUNNING {
+				done[i] = 0
+			}
+		}
+		switch done[i] {
+		case 0: // still running
+		case b3.SUCCESS:
+			successes++
+		default:
+			failures++
+		}
+	}
+
+	result := b3.RUNNING
+	switch {
+	case successes >= success:
+		result = b3.SUCCESS
+	case failures >= failure || count-failures < success:
+		result = b3.FAILURE
+	}
+	if result == b3.RUNNING {
+		tick.Blackboard.Set("childStatus", done, treeID, nodeID)
+		return result
+	}
+	this.HaltChildrenAfter(tick, -1)
+	tick.Blackboard.Set("childStatus", nil, treeID, nodeID)
+	return result
+}
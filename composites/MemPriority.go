@@ -5,8 +5,22 @@ import (
 	. "github.com/youngtrips/behavior3go/core"
 )
 
+/**
+ * MemPriority is a Priority resuming its RUNNING child on the next tick
+ * instead of ticking the children before it again. Observer aborts (see
+ * AbortMode) make it reactive where wanted: a branch with the "abort"
+ * property "lowerPriority" or "both" has its guard checked every tick
+ * while a later branch runs, and takes over once it succeeds; a running
+ * branch with "self" or "both" is aborted once its guard fails. Aborted
+ * branches are halted; a branch taking over does not evaluate its guard
+ * again.
+ *
+ * @module b3
+ * @class MemPriority
+ * @extends CompositeHelper
+**/
 type MemPriority struct {
-	Composite
+	CompositeHelper
 }
 
 /**
@@ -18,6 +32,20 @@ func (this *MemPriority) OnOpen(tick *Tick) {
 	tick.Blackboard.Set("runningChild", 0, tick.GetTree().GetID(), this.GetID())
 }
 
+// observe applies the observer aborts and returns the child to start from.
+func (this *MemPriority) observe(tick *Tick, child int) int {
+	if child <= 0 || child >= this.GetChildCount() {
+		return child
+	}
+	for i := 0; i < child; i++ {
+		if AbortModeOf(this.GetChild(i)).AbortsLowerPriority() && GuardPasses(tick, this.GetChild(i)) {
+			this.HaltChild(tick, child)
+			return i
+		}
+	}
+	return child
+}
+
 /**
  * Tick method.
  * @method tick
@@ -26,10 +54,20 @@ func (this *MemPriority) OnOpen(tick *Tick) {
 **/
 func (this *MemPriority) OnTick(tick *Tick) b3.Status {
 	var child = tick.Blackboard.GetInt("runningChild", tick.GetTree().GetID(), this.GetID())
+	child = this.observe(tick, child)
+	if child < this.GetChildCount() {
+		running := this.GetChild(child)
+		isOpen := tick.Blackboard.GetBool("isOpen", tick.GetTree().GetID(), running.GetID())
+		if isOpen && AbortModeOf(running).AbortsSelf() && !GuardPasses(tick, running) {
+			this.HaltChild(tick, child)
+			child++
+		}
+	}
 	for i := child; i < this.GetChildCount(); i++ {
 		var status = this.GetChild(i).Execute(tick)
 
 		if status != b3.FAILURE {
+			tick.SetResult(this, i)
 			if status == b3.RUNNING {
 				tick.Blackboard.Set("runningChild", i, tick.GetTree().GetID(), this.GetID())
 			}
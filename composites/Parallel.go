@@ -1,16 +1,90 @@
 package composites
 
 import (
-	_ "fmt"
+	"fmt"
 	"strconv"
+	"strings"
 
 	b3 "github.com/youngtrips/behavior3go"
-	_ "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/config"
 	. "github.com/youngtrips/behavior3go/core"
 )
 
+/**
+ * Parallel ticks all its children on each tick.
+ *
+ * Without policy properties it keeps its original behavior: every child
+ * is ticked on every tick and the node succeeds when at least
+ * MaxSuccessCount children (all by default) succeeded, failing otherwise.
+ * The children still running are left open and resume on the next tick.
+ *
+ * With successPolicy and/or failurePolicy, each policy being "one", "all"
+ * or a number of children, the node returns RUNNING until a policy is
+ * met: SUCCESS once enough children succeeded, FAILURE once enough failed
+ * (ERROR counts as a failure) or success became out of reach. Finished
+ * children are not ticked again until the node reopens. The result is
+ * checked after each child: once it is decided the remaining children are
+ * not ticked, and the ones still running are halted (closed) instead of
+ * being left open.
+ *
+ * @module b3
+ * @class Parallel
+ * @extends CompositeHelper
+**/
 type Parallel struct {
-	Composite
+	CompositeHelper
+	policies bool
+	// 0 means all the children
+	success int
+	failure int
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **MaxSuccessCount** (*String*) Successes needed, without policies.
+ * - **successPolicy**   (*String*) "one", "all" (default) or a count.
+ * - **failurePolicy**   (*String*) "one", "all" or a count; by default
+ *                                  the node fails once success is out of
+ *                                  reach.
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *Parallel) Initialize(setting *BTNodeCfg) {
+	this.CompositeHelper.Initialize(setting)
+	if v, ok := setting.Properties["successPolicy"]; ok {
+		this.policies = true
+		this.success = parsePolicy(v)
+	}
+	if v, ok := setting.Properties["failurePolicy"]; ok {
+		this.policies = true
+		this.failure = parsePolicy(v)
+	}
+}
+
+func parsePolicy(v interface{}) int {
+	if s, ok := v.(string); ok {
+		switch strings.ToLower(strings.TrimSpace(s)) {
+		case "one", "requireone":
+			return 1
+		case "all", "requireall", "":
+			return 0
+		}
+	}
+	n, ok := CoerceInt(v)
+	if !ok || n < 0 {
+		panic(fmt.Sprintf("Parallel: invalid policy %v", v))
+	}
+	return n
+}
+
+func (this *Parallel) OnOpen(tick *Tick) {
+	this.CompositeHelper.OnOpen(tick)
+	tick.Blackboard.Set("childStatus", nil, tick.GetTree().GetID(), this.GetID())
 }
 
 /**
@@ -20,6 +94,9 @@ type Parallel struct {
  * @return {Constant} A state constant.
 **/
 func (this *Parallel) OnTick(tick *Tick) b3.Status {
+	if this.policies {
+		return this.tickPolicies(tick)
+	}
 	//fmt.Println("tick Parallel :", this.GetTitle())
 	count := this.GetChildCount()
 	maxN := count
@@ -40,3 +117,67 @@ func (this *Parallel) OnTick(tick *Tick) b3.Status {
 	}
 	return b3.FAILURE
 }
+
+func (this *Parallel) tickPolicies(tick *Tick) b3.Status {
+	treeID, nodeID := tick.GetTree().GetID(), this.GetID()
+	count := this.GetChildCount()
+	done, _ := tick.Blackboard.Get("childStatus", treeID, nodeID).([]b3.Status)
+	if len(done) != count {
+		done = make([]b3.Status, count)
+	} else {
+		done = append([]b3.Status(nil), done...)
+	}
+
+	success, failure := this.success, this.failure
+	if success <= 0 || success > count {
+		success = count
+	}
+	if failure <= 0 || failure > count {
+		failure = count
+	}
+
+	successes, failures := 0, 0
+	for _, status := range done {
+		switch status {
+		case 0: // still running
+		case b3.SUCCESS:
+			successes++
+		default:
+			failures++
+		}
+	}
+	decide := func() b3.Status {
+		switch {
+		case successes >= success:
+			return b3.SUCCESS
+		case failures >= failure || count-failures < success:
+			return b3.FAILURE
+		}
+		return b3.RUNNING
+	}
+
+	result := decide()
+	for i := 0; i < count && result == b3.RUNNING; i++ {
+		if done[i] != 0 {
+			continue
+		}
+		switch status := this.GetChild(i).Execute(tick); status {
+		case b3.RUNNING:
+			continue
+		case b3.SUCCESS:
+			successes++
+			done[i] = status
+		default:
+			failures++
+			done[i] = status
+		}
+		result = decide()
+	}
+	if result == b3.RUNNING {
+		tick.Blackboard.Set("childStatus", done, treeID, nodeID)
+		return result
+	}
+	this.HaltChildrenAfter(tick, -1)
+	tick.Blackboard.Set("childStatus", nil, treeID, nodeID)
+	return result
+}
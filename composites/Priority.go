@@ -1,12 +1,117 @@
 package composites
 
 import (
+	"sort"
+
 	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
 	. "github.com/youngtrips/behavior3go/core"
 )
 
+/**
+ * Priority ticks its children in order until one does not fail. All the
+ * children before a RUNNING one are ticked again on every tick, so it
+ * always reacts to higher branches.
+ *
+ * Observer aborts (see AbortMode) control how the RUNNING branch is
+ * stopped: a higher branch with the "abort" property "lowerPriority" or
+ * "both" has its guard checked first, and once it succeeds the running
+ * branch is halted before the higher one ticks; a running branch with
+ * "self" or "both" is halted once its guard fails. A guard checked this
+ * way is not evaluated again when its branch ticks.
+ *
+ * The order can be data driven: children with a "priority" property (a
+ * number) or a "priorityKey" property (a global key holding the number)
+ * are sorted by decreasing priority each time the node opens, children
+ * without either counting as 0 and keeping their relative order.
+ *
+ * @module b3
+ * @class Priority
+ * @extends CompositeHelper
+**/
 type Priority struct {
-	Composite
+	CompositeHelper
+}
+
+// priorityProps returns the properties of a child declaring a priority.
+func priorityProps(child IBaseNode) (map[string]interface{}, bool) {
+	holder, ok := child.(interface{ GetConfig() *BTNodeCfg })
+	if !ok || holder.GetConfig() == nil {
+		return nil, false
+	}
+	props := holder.GetConfig().Properties
+	if _, ok := props["priorityKey"].(string); ok {
+		return props, true
+	}
+	_, ok = props["priority"]
+	return props, ok
+}
+
+// childPriority returns the priority of a child, 0 if it declares none.
+func childPriority(tick *Tick, child IBaseNode) float64 {
+	props, ok := priorityProps(child)
+	if !ok {
+		return 0
+	}
+	if key, ok := props["priorityKey"].(string); ok {
+		p, _ := tick.Blackboard.GetFloatCoerced(key, "", "")
+		return p
+	}
+	p, _ := CoerceFloat64(props["priority"])
+	return p
+}
+
+// ordered reports whether a child declares a priority, so the order of
+// the children is kept in the node memory.
+func (this *Priority) ordered() bool {
+	for i := 0; i < this.GetChildCount(); i++ {
+		if _, ok := priorityProps(this.GetChild(i)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// observing reports whether a child declares an abort mode, so the
+// running child is kept in the node memory.
+func (this *Priority) observing() bool {
+	for i := 0; i < this.GetChildCount(); i++ {
+		if AbortModeOf(this.GetChild(i)) != AbortNone {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * Open method.
+ * @method open
+ * @param {b3.Tick} tick A tick instance.
+**/
+func (this *Priority) OnOpen(tick *Tick) {
+	if this.observing() {
+		this.CompositeHelper.OnOpen(tick)
+	}
+	if !this.ordered() {
+		return
+	}
+	count := this.GetChildCount()
+	priorities := make([]float64, count)
+	order := make([]int, count)
+	for i := 0; i < count; i++ {
+		priorities[i] = childPriority(tick, this.GetChild(i))
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return priorities[order[a]] > priorities[order[b]]
+	})
+	tick.Blackboard.Set("order", order, tick.GetTree().GetID(), this.GetID())
+}
+
+func (this *Priority) OnClose(tick *Tick) {
+	if this.observing() {
+		this.CompositeHelper.OnClose(tick)
+	}
 }
 
 /**
@@ -16,11 +121,52 @@ type Priority struct {
  * @return {Constant} A state constant.
 **/
 func (this *Priority) OnTick(tick *Tick) b3.Status {
-	for i := 0; i < this.GetChildCount(); i++ {
-		var status = this.GetChild(i).Execute(tick)
+	var order []int
+	if this.ordered() {
+		order, _ = tick.Blackboard.Get("order", tick.GetTree().GetID(), this.GetID()).([]int)
+	}
+	observing := this.observing()
+	running := -1
+	if observing {
+		running = this.RunningChildIndex(tick)
+		if running >= 0 && AbortModeOf(this.GetChild(running)).AbortsSelf() &&
+			!GuardPasses(tick, this.GetChild(running)) {
+			this.HaltChild(tick, running)
+			running = -1
+		}
+	}
+	for n := 0; n < this.GetChildCount(); n++ {
+		i := n
+		if n < len(order) {
+			i = order[n]
+		}
+		child := this.GetChild(i)
+		if running >= 0 && i != running && AbortModeOf(child).AbortsLowerPriority() &&
+			GuardPasses(tick, child) {
+			this.HaltChild(tick, running)
+			running = -1
+		}
+		var status = child.Execute(tick)
 		if status != b3.FAILURE {
+			tick.SetResult(this, i)
+			if observing {
+				if running >= 0 && running != i {
+					this.HaltChild(tick, running)
+				}
+				if status == b3.RUNNING {
+					this.SetRunningChildIndex(tick, i)
+				} else {
+					this.SetRunningChildIndex(tick, -1)
+				}
+			}
 			return status
 		}
+		if i == running {
+			running = -1
+		}
+	}
+	if observing {
+		this.SetRunningChildIndex(tick, -1)
 	}
 	return b3.FAILURE
 }
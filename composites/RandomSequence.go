@@ -0,0 +1,56 @@
+package composites
+
+import (
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * RandomSequence is a MemSequence whose children order is shuffled each
+ * time it opens, with the random generator of the agent (see
+ * Blackboard.SetSeed): the steps run in a different order each time but
+ * all must succeed, as in a sequence.
+ *
+ * @module b3
+ * @class RandomSequence
+ * @extends Composite
+**/
+type RandomSequence struct {
+	Composite
+}
+
+/**
+ * Open method.
+ * @method open
+ * @param {b3.Tick} tick A tick instance.
+**/
+func (this *RandomSequence) OnOpen(tick *Tick) {
+	tick.Blackboard.Set("runningChild", 0, tick.GetTree().GetID(), this.GetID())
+	tick.Blackboard.Set("order", tick.Rand().Perm(this.GetChildCount()), tick.GetTree().GetID(), this.GetID())
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (this *RandomSequence) OnTick(tick *Tick) b3.Status {
+	var child = tick.Blackboard.GetInt("runningChild", tick.GetTree().GetID(), this.GetID())
+	order, _ := tick.Blackboard.Get("order", tick.GetTree().GetID(), this.GetID()).([]int)
+	if len(order) != this.GetChildCount() {
+		return b3.ERROR
+	}
+	for n := child; n < len(order); n++ {
+		var status = this.GetChild(order[n]).Execute(tick)
+
+		if status != b3.SUCCESS {
+			if status == b3.RUNNING {
+				tick.Blackboard.Set("runningChild", n, tick.GetTree().GetID(), this.GetID())
+			}
+
+			return status
+		}
+	}
+	return b3.SUCCESS
+}
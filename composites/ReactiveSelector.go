@@ -0,0 +1,36 @@
+package composites
+
+import (
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * ReactiveSelector ticks its children in order until one does not fail,
+ * starting from the first child on every tick: while a later child is
+ * RUNNING, the earlier ones are checked again and can steal the execution.
+ * Unlike Priority, which leaves the previous running child to be closed
+ * at the end of the tick, the child losing the execution is halted right
+ * after the earlier child returned, before the parent goes on.
+ *
+ * @module b3
+ * @class ReactiveSelector
+ * @extends CompositeHelper
+**/
+type ReactiveSelector struct {
+	CompositeHelper
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (this *ReactiveSelector) OnTick(tick *Tick) b3.Status {
+	i, status := this.TickChildrenFrom(tick, 0, b3.FAILURE)
+	if status != b3.FAILURE {
+		tick.SetResult(this, i)
+	}
+	return status
+}
@@ -0,0 +1,101 @@
+package composites
+
+import (
+	"fmt"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * Switch executes the child whose "case" property matches the value of a
+ * global blackboard key, and returns its status; a child with the case
+ * "default" (or without case) is executed when no case matches. Values
+ * and cases are compared as text, so the case "2" matches the value 2.
+ * When the value changes while a child is RUNNING, that child is halted.
+ * The node fails if no child matches.
+ *
+ * @module b3
+ * @class Switch
+ * @extends CompositeHelper
+**/
+type Switch struct {
+	CompositeHelper
+	key string
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **key** (*String*) Global key holding the value to switch on.
+ *
+ * Child settings:
+ *
+ * - **case** (*String*) Value selecting the child, or "default".
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *Switch) Initialize(setting *BTNodeCfg) {
+	this.CompositeHelper.Initialize(setting)
+	this.key = setting.GetPropertyAsString("key")
+}
+
+func caseOf(node IBaseNode) (string, bool) {
+	holder, ok := node.(interface{ GetConfig() *BTNodeCfg })
+	if !ok || holder.GetConfig() == nil {
+		return "", false
+	}
+	v, ok := holder.GetConfig().Properties["case"]
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprint(v), true
+}
+
+func (this *Switch) match(tick *Tick) int {
+	value := tick.Blackboard.GetMem(this.key)
+	def := -1
+	for i := 0; i < this.GetChildCount(); i++ {
+		c, ok := caseOf(this.GetChild(i))
+		if !ok || c == "default" {
+			if def < 0 {
+				def = i
+			}
+			continue
+		}
+		if value != nil && c == fmt.Sprint(value) {
+			return i
+		}
+	}
+	return def
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (this *Switch) OnTick(tick *Tick) b3.Status {
+	i := this.match(tick)
+	if running := this.RunningChildIndex(tick); running >= 0 && running != i {
+		this.HaltChild(tick, running)
+		this.SetRunningChildIndex(tick, -1)
+	}
+	if i < 0 {
+		return b3.FAILURE
+	}
+	status := this.GetChild(i).Execute(tick)
+	if status == b3.RUNNING {
+		this.SetRunningChildIndex(tick, i)
+	} else {
+		this.SetRunningChildIndex(tick, -1)
+	}
+	tick.SetResult(this, i)
+	return status
+}
@@ -0,0 +1,135 @@
+package composites
+
+import (
+	"sort"
+	"strings"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * UtilitySelector ticks its children by decreasing desirability, like a
+ * Priority whose order is computed each tick. A child reports its score by
+ * implementing IScorer, or the score is read from a global blackboard key.
+ *
+ * Scores are smoothed over time (momentum) and the child chosen last keeps
+ * its place until another one beats it by a margin (hysteresis), so two
+ * behaviors with near-equal scores do not alternate on every tick.
+ * Children scoring 0 or less are skipped. A RUNNING child losing its
+ * place is halted.
+ *
+ * @module b3
+ * @class UtilitySelector
+ * @extends CompositeHelper
+**/
+type UtilitySelector struct {
+	CompositeHelper
+	keys       []string
+	momentum   float64
+	hysteresis float64
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **keys**       (*String*) Comma separated global keys holding the
+ *                             scores of the children, in child order;
+ *                             ignored for children implementing IScorer.
+ * - **momentum**   (*Number*) Weight of the previous score, 0 to 1
+ *                             (default 0, no smoothing).
+ * - **hysteresis** (*Number*) Bonus of the child chosen last (default 0).
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *UtilitySelector) Initialize(setting *BTNodeCfg) {
+	this.CompositeHelper.Initialize(setting)
+	if _, ok := setting.Properties["keys"]; ok {
+		for _, key := range strings.Split(setting.GetPropertyAsString("keys"), ",") {
+			this.keys = append(this.keys, strings.TrimSpace(key))
+		}
+	}
+	if _, ok := setting.Properties["momentum"]; ok {
+		this.momentum = setting.GetProperty("momentum")
+	}
+	if _, ok := setting.Properties["hysteresis"]; ok {
+		this.hysteresis = setting.GetProperty("hysteresis")
+	}
+}
+
+func (this *UtilitySelector) rawScore(tick *Tick, i int) float64 {
+	if scorer, ok := this.GetChild(i).(IScorer); ok {
+		return scorer.Score(tick)
+	}
+	if i >= len(this.keys) || this.keys[i] == "" {
+		return 0
+	}
+	score, _ := tick.Blackboard.GetFloatCoerced(this.keys[i], "", "")
+	return score
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (this *UtilitySelector) OnTick(tick *Tick) b3.Status {
+	treeID, nodeID := tick.GetTree().GetID(), this.GetID()
+	previous, _ := tick.Blackboard.Get("scores", treeID, nodeID).([]float64)
+	current := -1
+	if v, ok := tick.Blackboard.Get("current", treeID, nodeID).(int); ok {
+		current = v
+	}
+
+	count := this.GetChildCount()
+	scores := make([]float64, count)
+	effective := make([]float64, count)
+	order := make([]int, 0, count)
+	for i := 0; i < count; i++ {
+		scores[i] = this.rawScore(tick, i)
+		if i < len(previous) {
+			scores[i] = SmoothScore(previous[i], scores[i], this.momentum)
+		}
+		effective[i] = scores[i]
+		if i == current {
+			effective[i] += this.hysteresis
+		}
+		if scores[i] > 0 {
+			order = append(order, i)
+		}
+	}
+	tick.Blackboard.Set("scores", scores, treeID, nodeID)
+	sort.SliceStable(order, func(a, b int) bool {
+		return effective[order[a]] > effective[order[b]]
+	})
+
+	running := this.RunningChildIndex(tick)
+	for _, i := range order {
+		status := this.GetChild(i).Execute(tick)
+		if status == b3.FAILURE {
+			continue
+		}
+		if running >= 0 && running != i {
+			this.HaltChild(tick, running)
+		}
+		if status == b3.RUNNING {
+			this.SetRunningChildIndex(tick, i)
+		} else {
+			this.SetRunningChildIndex(tick, -1)
+		}
+		tick.Blackboard.Set("current", i, treeID, nodeID)
+		return status
+	}
+	if running >= 0 {
+		this.HaltChild(tick, running)
+	}
+	this.SetRunningChildIndex(tick, -1)
+	tick.Blackboard.Set("current", nil, treeID, nodeID)
+	return b3.FAILURE
+}
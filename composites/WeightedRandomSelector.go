@@ -0,0 +1,152 @@
+package composites
+
+import (
+	"strconv"
+	"strings"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * WeightedRandomSelector picks one child at random, each child being
+ * chosen with a probability proportional to its weight, like a loot
+ * table. A RUNNING child is resumed on the next ticks. If the chosen child
+ * fails and reroll is on, another one is drawn among the children not
+ * tried yet in this tick. Children with a weight of 0 or less are never
+ * chosen. The random generator of the agent is used (see
+ * Blackboard.SetSeed).
+ *
+ * @module b3
+ * @class WeightedRandomSelector
+ * @extends CompositeHelper
+**/
+type WeightedRandomSelector struct {
+	CompositeHelper
+	weights    []float64
+	weightKeys []string
+	reroll     bool
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **weights**    (*String*)  Comma separated weights, in child order.
+ * - **weightKeys** (*String*)  Comma separated global keys holding the
+ *                              weights, in child order; a key left empty
+ *                              uses the weight of weights.
+ * - **reroll**     (*Boolean*) Draw again when the chosen child fails.
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *WeightedRandomSelector) Initialize(setting *BTNodeCfg) {
+	this.CompositeHelper.Initialize(setting)
+	if _, ok := setting.Properties["weights"]; ok {
+		for _, s := range strings.Split(setting.GetPropertyAsString("weights"), ",") {
+			w, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			if err != nil {
+				panic("WeightedRandomSelector: invalid weight " + s)
+			}
+			this.weights = append(this.weights, w)
+		}
+	}
+	if _, ok := setting.Properties["weightKeys"]; ok {
+		for _, key := range strings.Split(setting.GetPropertyAsString("weightKeys"), ",") {
+			this.weightKeys = append(this.weightKeys, strings.TrimSpace(key))
+		}
+	}
+	this.reroll = setting.GetPropertyAsBool("reroll")
+}
+
+func (this *WeightedRandomSelector) weight(tick *Tick, i int) float64 {
+	if i < len(this.weightKeys) && this.weightKeys[i] != "" {
+		w, _ := tick.Blackboard.GetFloatCoerced(this.weightKeys[i], "", "")
+		return w
+	}
+	if i < len(this.weights) {
+		return this.weights[i]
+	}
+	return 0
+}
+
+// draw picks a child among the candidates by weight, -1 if none has a
+// positive weight.
+func (this *WeightedRandomSelector) draw(tick *Tick, candidates []int) int {
+	total := 0.0
+	weights := make([]float64, len(candidates))
+	for j, i := range candidates {
+		if w := this.weight(tick, i); w > 0 {
+			weights[j] = w
+			total += w
+		}
+	}
+	if total <= 0 {
+		return -1
+	}
+	r := tick.Rand().Float64() * total
+	for j, w := range weights {
+		if w <= 0 {
+			continue
+		}
+		if r < w {
+			return j
+		}
+		r -= w
+	}
+	for j := len(weights) - 1; j >= 0; j-- {
+		if weights[j] > 0 {
+			return j
+		}
+	}
+	return -1
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (this *WeightedRandomSelector) OnTick(tick *Tick) b3.Status {
+	failed := -1
+	if running := this.RunningChildIndex(tick); running >= 0 {
+		status := this.GetChild(running).Execute(tick)
+		if status != b3.RUNNING {
+			this.SetRunningChildIndex(tick, -1)
+		}
+		if status != b3.FAILURE || !this.reroll {
+			tick.SetResult(this, running)
+			return status
+		}
+		failed = running
+	}
+
+	candidates := make([]int, 0, this.GetChildCount())
+	for i := 0; i < this.GetChildCount(); i++ {
+		if i != failed {
+			candidates = append(candidates, i)
+		}
+	}
+	for len(candidates) > 0 {
+		j := this.draw(tick, candidates)
+		if j < 0 {
+			break
+		}
+		i := candidates[j]
+		status := this.GetChild(i).Execute(tick)
+		if status == b3.RUNNING {
+			this.SetRunningChildIndex(tick, i)
+		}
+		if status != b3.FAILURE || !this.reroll {
+			tick.SetResult(this, i)
+			return status
+		}
+		candidates = append(candidates[:j], candidates[j+1:]...)
+	}
+	return b3.FAILURE
+}
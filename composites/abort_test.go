@@ -0,0 +1,129 @@
+package composites_test
+
+import (
+	"testing"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+// abortTree is a priority composite of a guarded branch g, ticking Runner
+// while the guard holds, and a fallback f; the guard checks "alarm".
+func abortTree(composite, abort, fallback string) string {
+	return `{
+		"id": "t", "title": "abort", "root": "p",
+		"nodes": {
+			"p": {"id": "p", "name": "` + composite + `", "category": "composite", "children": ["g", "f"]},
+			"g": {"id": "g", "name": "Sequence", "category": "composite", "children": ["alarm", "r"],
+				"properties": {"abort": "` + abort + `"}},
+			"alarm": {"id": "alarm", "name": "Alarm", "category": "condition"},
+			"r": {"id": "r", "name": "Runner", "category": "action"},
+			"f": {"id": "f", "name": "` + fallback + `", "category": "action"}
+		}
+	}`
+}
+
+// invertedAbortTree is abortTree with the guard holding while "alarm" is
+// not set, through an Inverter.
+func invertedAbortTree(composite, abort, fallback string) string {
+	return `{
+		"id": "t", "title": "abort", "root": "p",
+		"nodes": {
+			"p": {"id": "p", "name": "` + composite + `", "category": "composite", "children": ["g", "f"]},
+			"g": {"id": "g", "name": "Sequence", "category": "composite", "children": ["i", "r"],
+				"properties": {"abort": "` + abort + `"}},
+			"i": {"id": "i", "name": "Inverter", "category": "decorator", "child": "alarm"},
+			"alarm": {"id": "alarm", "name": "Alarm", "category": "condition"},
+			"r": {"id": "r", "name": "Runner", "category": "action"},
+			"f": {"id": "f", "name": "` + fallback + `", "category": "action"}
+		}
+	}`
+}
+
+func TestObserverAborts(t *testing.T) {
+	type step struct {
+		alarm  bool
+		status b3.Status
+		// open node ids after the tick
+		open []string
+	}
+	tests := []struct {
+		name  string
+		tree  string
+		steps []step
+	}{
+		{"MemPriority lowerPriority", abortTree("MemPriority", "lowerPriority", "Runner"), []step{
+			{false, b3.RUNNING, []string{"p", "f"}},
+			{true, b3.RUNNING, []string{"p", "g", "r"}},
+		}},
+		{"MemPriority none", abortTree("MemPriority", "none", "Runner"), []step{
+			{false, b3.RUNNING, []string{"p", "f"}},
+			{true, b3.RUNNING, []string{"p", "f"}},
+		}},
+		{"MemPriority self", abortTree("MemPriority", "self", "Succeeder"), []step{
+			{true, b3.RUNNING, []string{"p", "g", "r"}},
+			{false, b3.SUCCESS, nil},
+		}},
+		{"Priority lowerPriority", abortTree("Priority", "lowerPriority", "Runner"), []step{
+			{false, b3.RUNNING, []string{"p", "f"}},
+			{true, b3.RUNNING, []string{"p", "g", "r"}},
+			{false, b3.RUNNING, []string{"p", "f"}},
+		}},
+		{"Priority self", abortTree("Priority", "self", "Succeeder"), []step{
+			{true, b3.RUNNING, []string{"p", "g", "r"}},
+			{false, b3.SUCCESS, nil},
+		}},
+		{"Priority lowerPriority through Inverter", invertedAbortTree("Priority", "lowerPriority", "Runner"), []step{
+			{true, b3.RUNNING, []string{"p", "f"}},
+			{true, b3.RUNNING, []string{"p", "f"}},
+			{false, b3.RUNNING, []string{"p", "g", "r"}},
+			{true, b3.RUNNING, []string{"p", "f"}},
+		}},
+		{"MemPriority self through Inverter", invertedAbortTree("MemPriority", "self", "Succeeder"), []step{
+			{false, b3.RUNNING, []string{"p", "g", "r"}},
+			{false, b3.RUNNING, []string{"p", "g", "r"}},
+			{true, b3.SUCCESS, nil},
+		}},
+	}
+	for _, test := range tests {
+		maps := b3.NewRegisterStructMaps()
+		evaluations := 0
+		RegisterCondition(maps, "Alarm", func(tick *Tick, cfg *BTNodeCfg) bool {
+			evaluations++
+			return tick.Blackboard.GetBool("alarm", "", "")
+		})
+		tree := loadTreeWith(t, test.tree, maps)
+		board := NewBlackboard(nil)
+		for i, s := range test.steps {
+			board.SetMem("alarm", s.alarm)
+			evaluations = 0
+			if status := tree.Tick(0, board); status != s.status {
+				t.Errorf("%s: tick %d: status %v, want %v", test.name, i+1, status, s.status)
+			}
+			// a guard taking over or aborting is evaluated once
+			if evaluations > 1 {
+				t.Errorf("%s: tick %d: guard evaluated %d times", test.name, i+1, evaluations)
+			}
+			var open []string
+			for _, node := range tree.OpenNodes(board) {
+				open = append(open, node.ID)
+			}
+			if !equalIDs(open, s.open) {
+				t.Errorf("%s: tick %d: open %v, want %v", test.name, i+1, open, s.open)
+			}
+		}
+	}
+}
+
+func equalIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+package composites_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+// fetch is a work child: its work waits for the "delay" property (ms),
+// then returns the "result" property; it fails on a work error.
+type fetch struct {
+	Action
+	works *int64
+	errs  *[]error
+}
+
+func (this *fetch) PrepareWork(tick *Tick) interface{} {
+	return this.GetConfig()
+}
+
+func (this *fetch) Work(ctx context.Context, input interface{}) (interface{}, error) {
+	atomic.AddInt64(this.works, 1)
+	cfg := input.(*BTNodeCfg)
+	select {
+	case <-time.After(time.Duration(cfg.GetPropertyAsInt("delay")) * time.Millisecond):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return cfg.GetPropertyAsString("result"), nil
+}
+
+func (this *fetch) OnTick(tick *Tick) b3.Status {
+	value, err, ok := tick.WorkResult(this)
+	if !ok {
+		return b3.ERROR
+	}
+	if err != nil {
+		*this.errs = append(*this.errs, err)
+		return b3.FAILURE
+	}
+	if value == "ok" {
+		return b3.SUCCESS
+	}
+	return b3.FAILURE
+}
+
+func TestConcurrent(t *testing.T) {
+	const children = `
+		"ok": {"id": "ok", "name": "Fetch", "category": "action", "properties": {"delay": 1, "result": "ok"}},
+		"ok2": {"id": "ok2", "name": "Fetch", "category": "action", "properties": {"delay": 1, "result": "ok"}},
+		"bad": {"id": "bad", "name": "Fetch", "category": "action", "properties": {"delay": 1, "result": "bad"}},
+		"slow": {"id": "slow", "name": "Fetch", "category": "action", "properties": {"delay": 5000, "result": "ok"}},
+		"w": {"id": "w", "name": "Wait", "category": "action", "properties": {"milliseconds": 1}},
+		"long": {"id": "long", "name": "Wait", "category": "action", "properties": {"milliseconds": 100}}`
+	tests := []struct {
+		name     string
+		props    string
+		children string
+		// statuses of the ticks
+		want  []b3.Status
+		works int64
+		// works ending with context.DeadlineExceeded
+		timeouts int
+		open     int
+	}{
+		{"all succeed", `{}`, `"ok", "ok2"`, []b3.Status{b3.SUCCESS}, 2, 0, 0},
+		{"finished children kept", `{}`, `"ok", "w"`, []b3.Status{b3.RUNNING, b3.SUCCESS}, 1, 0, 0},
+		{"one success", `{"successPolicy": "one"}`, `"bad", "ok"`, []b3.Status{b3.SUCCESS}, 2, 0, 0},
+		{"one failure halts", `{"failurePolicy": "one"}`, `"bad", "long"`, []b3.Status{b3.FAILURE}, 1, 0, 0},
+		{"success out of reach", `{}`, `"bad", "long"`, []b3.Status{b3.FAILURE}, 1, 0, 0},
+		{"timeout", `{"timeout": 10}`, `"slow", "ok"`, []b3.Status{b3.FAILURE}, 2, 1, 0},
+	}
+	for _, test := range tests {
+		var works int64
+		var errs []error
+		maps := b3.NewRegisterStructMaps()
+		RegisterNodeFactory(maps, "Fetch", func() IBaseNode {
+			return &fetch{works: &works, errs: &errs}
+		})
+		tree := loadTreeWith(t, `{
+			"id": "t", "title": "concurrent", "root": "c",
+			"nodes": {
+				"c": {"id": "c", "name": "Concurrent", "category": "composite",
+					"properties": `+test.props+`, "children": [`+test.children+`]},`+children+`
+			}
+		}`, maps)
+		board, clock := newBoard(2 * time.Millisecond)
+		start := time.Now()
+		for i, want := range test.want {
+			if status := tree.Tick(0, board); status != want {
+				t.Errorf("%s: tick %d: %v, want %v", test.name, i+1, status, want)
+			}
+			clock.Step()
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%s: took %v", test.name, elapsed)
+		}
+		if works := atomic.LoadInt64(&works); works != test.works {
+			t.Errorf("%s: %d works run, want %d", test.name, works, test.works)
+		}
+		timeouts := 0
+		for _, err := range errs {
+			if err == context.DeadlineExceeded {
+				timeouts++
+			}
+		}
+		if timeouts != test.timeouts {
+			t.Errorf("%s: errors %v, want %d timeouts", test.name, errs, test.timeouts)
+		}
+		if open := len(tree.OpenNodes(board)); open != test.open {
+			t.Errorf("%s: %d open nodes, want %d", test.name, open, test.open)
+		}
+	}
+}
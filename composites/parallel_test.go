@@ -0,0 +1,95 @@
+package composites_test
+
+import (
+	"testing"
+	"time"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+	"github.com/youngtrips/behavior3go/loader"
+)
+
+func loadTree(t *testing.T, data string) *BehaviorTree {
+	t.Helper()
+	return loadTreeWith(t, data, nil)
+}
+
+// loadTreeWith loads a tree whose custom nodes are registered in maps.
+func loadTreeWith(t *testing.T, data string, maps *b3.RegisterStructMaps) *BehaviorTree {
+	t.Helper()
+	treeConfig, err := LoadTreeCfgFromBytes([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := loader.TryCreateBevTreeFromConfig(treeConfig, maps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+// newBoard returns a blackboard whose clock advances by step on Step.
+func newBoard(step time.Duration) (*Blackboard, *StepClock) {
+	board := NewBlackboard(nil)
+	clock := NewStepClock(time.Unix(0, 0), step)
+	board.SetClock(clock)
+	return board, clock
+}
+
+func TestParallelLegacyKeepsRunningChildren(t *testing.T) {
+	tree := loadTree(t, `{
+		"id": "t", "title": "parallel", "root": "p",
+		"nodes": {
+			"p": {"id": "p", "name": "Parallel", "category": "composite", "children": ["w", "s"]},
+			"w": {"id": "w", "name": "Wait", "category": "action", "properties": {"milliseconds": 1}},
+			"s": {"id": "s", "name": "Succeeder", "category": "action"}
+		}
+	}`)
+	board, clock := newBoard(2 * time.Millisecond)
+	if status := tree.Tick(0, board); status != b3.FAILURE {
+		t.Fatal("tick 1:", status)
+	}
+	if len(tree.OpenNodes(board)) != 1 {
+		t.Fatal("Wait not left open:", tree.OpenNodes(board))
+	}
+	clock.Step()
+	if status := tree.Tick(0, board); status != b3.SUCCESS {
+		t.Fatal("tick 2:", status)
+	}
+}
+
+func TestParallelPolicies(t *testing.T) {
+	tests := []struct {
+		name     string
+		policies string
+		children string
+		want     b3.Status
+		open     int
+	}{
+		{"one success halts the others", `"successPolicy": "one"`, `"w", "s"`, b3.SUCCESS, 0},
+		{"all waits for the running child", `"successPolicy": "all"`, `"w", "s"`, b3.RUNNING, 2},
+		{"one failure fails", `"failurePolicy": "one"`, `"w", "f"`, b3.FAILURE, 0},
+		{"success out of reach fails", `"successPolicy": 2`, `"f", "w"`, b3.FAILURE, 0},
+		{"failure count not reached", `"successPolicy": "one", "failurePolicy": 2`, `"w", "f"`, b3.RUNNING, 2},
+	}
+	for _, test := range tests {
+		tree := loadTree(t, `{
+			"id": "t", "title": "parallel", "root": "p",
+			"nodes": {
+				"p": {"id": "p", "name": "Parallel", "category": "composite",
+					"properties": {`+test.policies+`}, "children": [`+test.children+`]},
+				"w": {"id": "w", "name": "Wait", "category": "action", "properties": {"milliseconds": 100}},
+				"s": {"id": "s", "name": "Succeeder", "category": "action"},
+				"f": {"id": "f", "name": "Failer", "category": "action"}
+			}
+		}`)
+		board, _ := newBoard(time.Millisecond)
+		if status := tree.Tick(0, board); status != test.want {
+			t.Errorf("%s: status %v, want %v", test.name, status, test.want)
+		}
+		if open := len(tree.OpenNodes(board)); open != test.open {
+			t.Errorf("%s: %d open nodes, want %d", test.name, open, test.open)
+		}
+	}
+}
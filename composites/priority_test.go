@@ -0,0 +1,61 @@
+package composites_test
+
+import (
+	"testing"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+// hasMemory reports whether key is stored in the memory of node.
+func hasMemory(board *Blackboard, tree *BehaviorTree, node, key string) bool {
+	for _, e := range board.Export().Entries {
+		if e.TreeScope == tree.GetID() && e.NodeScope == node && e.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPriorityOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		children string
+		winner   string
+		ordered  bool
+	}{
+		{"static order", `
+			"a": {"id": "a", "name": "Cond", "category": "condition"},
+			"b": {"id": "b", "name": "Cond", "category": "condition"}`, "a", false},
+		{"priority property", `
+			"a": {"id": "a", "name": "Cond", "category": "condition"},
+			"b": {"id": "b", "name": "Cond", "category": "condition", "properties": {"priority": 1}}`, "b", true},
+		{"priority key", `
+			"a": {"id": "a", "name": "Cond", "category": "condition", "properties": {"priority": 1}},
+			"b": {"id": "b", "name": "Cond", "category": "condition", "properties": {"priorityKey": "urge"}}`, "b", true},
+	}
+	for _, test := range tests {
+		maps := b3.NewRegisterStructMaps()
+		var ticked []string
+		RegisterCondition(maps, "Cond", func(tick *Tick, cfg *BTNodeCfg) bool {
+			ticked = append(ticked, cfg.Id)
+			return true
+		})
+		tree := loadTreeWith(t, `{
+			"id": "t", "title": "priority", "root": "p",
+			"nodes": {
+				"p": {"id": "p", "name": "Priority", "category": "composite", "children": ["a", "b"]},`+test.children+`
+			}
+		}`, maps)
+		board := NewBlackboard(nil)
+		board.SetMem("urge", 2)
+		tree.Tick(0, board)
+		if len(ticked) != 1 || ticked[0] != test.winner {
+			t.Errorf("%s: ticked %v, want %s", test.name, ticked, test.winner)
+		}
+		if ordered := hasMemory(board, tree, "p", "order"); ordered != test.ordered {
+			t.Errorf("%s: order stored %v, want %v", test.name, ordered, test.ordered)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package conditions
+
+import (
+	"time"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * CooldownElapsed returns `SUCCESS` when the time stamped at a key of the
+ * global memory is at least milliseconds old, or when the key is not set,
+ * `FAILURE` otherwise. Paired with the StampTime action, which stamps the
+ * key once the guarded action ran, it expresses a cooldown in data:
+ *
+ *     Sequence
+ *     |—CooldownElapsed key=lastFireball milliseconds=5000
+ *     |—CastFireball
+ *     |—StampTime key=lastFireball
+ *
+ * The stamp is a time.Time or a number of milliseconds since the epoch,
+ * compared with the clock of the blackboard (see Blackboard.SetClock).
+ *
+ * @module b3
+ * @class CooldownElapsed
+ * @extends Condition
+**/
+type CooldownElapsed struct {
+	Condition
+	key          string
+	milliseconds int64
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **key**          (*String*)  The key of the stamp.
+ * - **milliseconds** (*Integer*) The cooldown duration.
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *CooldownElapsed) Initialize(setting *BTNodeCfg) {
+	this.Condition.Initialize(setting)
+	this.key = setting.GetPropertyAsString("key")
+	if this.key == "" {
+		panic("key parameter in CooldownElapsed condition is an obligatory parameter")
+	}
+	this.milliseconds = setting.GetPropertyAsInt64("milliseconds")
+}
+
+func (this *CooldownElapsed) OnTick(tick *Tick) b3.Status {
+	var stamp time.Time
+	switch v := tick.Blackboard.GetMem(this.key).(type) {
+	case nil:
+		return b3.SUCCESS
+	case time.Time:
+		stamp = v
+	default:
+		ms, ok := CoerceFloat64(v)
+		if !ok {
+			return b3.FAILURE
+		}
+		stamp = time.Unix(0, int64(ms)*int64(time.Millisecond))
+	}
+	if tick.Now().Sub(stamp) >= time.Duration(this.milliseconds)*time.Millisecond {
+		return b3.SUCCESS
+	}
+	return b3.FAILURE
+}
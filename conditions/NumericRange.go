@@ -0,0 +1,96 @@
+package conditions
+
+import (
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+// rangeBound is a bound of a NumericRange, a number or the value of a
+// key of the global memory.
+type rangeBound struct {
+	set   bool
+	value float64
+	key   string
+}
+
+func (this *rangeBound) init(setting *BTNodeCfg, name string) {
+	*this = rangeBound{}
+	if _, ok := setting.Properties[name+"Key"]; ok {
+		this.set, this.key = true, setting.GetPropertyAsString(name+"Key")
+	} else if _, ok := setting.Properties[name]; ok {
+		this.set, this.value = true, setting.GetProperty(name)
+	}
+}
+
+// resolve returns the bound, false when its key does not hold a number.
+func (this *rangeBound) resolve(tick *Tick) (float64, bool) {
+	if this.key == "" {
+		return this.value, true
+	}
+	return CoerceFloat64(tick.Blackboard.GetMem(this.key))
+}
+
+/**
+ * NumericRange returns `SUCCESS` when the number at a key of the global
+ * memory lies within [min, max], `FAILURE` otherwise or when the key or
+ * a bound key does not hold a number. Each bound is a number, read from
+ * another key with minKey and maxKey, or omitted to leave the range
+ * open on that side. Numbers of any type and numeric strings are
+ * accepted (see CoerceFloat64).
+ *
+ * @module b3
+ * @class NumericRange
+ * @extends Condition
+**/
+type NumericRange struct {
+	Condition
+	key      string
+	min, max rangeBound
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **key**    (*String*) The key of the value.
+ * - **min**    (*Number*) Lower bound, inclusive.
+ * - **minKey** (*String*) Key of the lower bound.
+ * - **max**    (*Number*) Upper bound, inclusive.
+ * - **maxKey** (*String*) Key of the upper bound.
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *NumericRange) Initialize(setting *BTNodeCfg) {
+	this.Condition.Initialize(setting)
+	this.key = setting.GetPropertyAsString("key")
+	if this.key == "" {
+		panic("key parameter in NumericRange condition is an obligatory parameter")
+	}
+	this.min.init(setting, "min")
+	this.max.init(setting, "max")
+	if !this.min.set && !this.max.set {
+		panic("NumericRange condition: min or max is required")
+	}
+}
+
+func (this *NumericRange) OnTick(tick *Tick) b3.Status {
+	value, ok := CoerceFloat64(tick.Blackboard.GetMem(this.key))
+	if !ok {
+		return b3.FAILURE
+	}
+	if this.min.set {
+		if min, ok := this.min.resolve(tick); !ok || value < min {
+			return b3.FAILURE
+		}
+	}
+	if this.max.set {
+		if max, ok := this.max.resolve(tick); !ok || value > max {
+			return b3.FAILURE
+		}
+	}
+	return b3.SUCCESS
+}
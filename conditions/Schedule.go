@@ -0,0 +1,242 @@
+package conditions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+// timeWindow is a window of the day, in minutes since midnight; from > to
+// spans midnight.
+type timeWindow struct {
+	from, to int
+}
+
+func parseTimeWindow(s string) (timeWindow, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 2 {
+		return timeWindow{}, fmt.Errorf("window %q: want HH:MM-HH:MM", s)
+	}
+	var w timeWindow
+	for i, part := range parts {
+		t, err := time.Parse("15:04", strings.TrimSpace(part))
+		if err != nil {
+			return timeWindow{}, fmt.Errorf("window %q: %v", s, err)
+		}
+		minutes := t.Hour()*60 + t.Minute()
+		if i == 0 {
+			w.from = minutes
+		} else {
+			w.to = minutes
+		}
+	}
+	return w, nil
+}
+
+func (this timeWindow) contains(minutes int) bool {
+	if this.from <= this.to {
+		return minutes >= this.from && minutes < this.to
+	}
+	return minutes >= this.from || minutes < this.to
+}
+
+// cronSchedule is a parsed "minute hour day-of-month month day-of-week"
+// expression, a set of allowed values per field.
+type cronSchedule struct {
+	fields [5]map[int]bool
+	// whether the day fields are restricted, see matches
+	domStar, dowStar bool
+}
+
+var cronRanges = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+func parseCron(s string) (*cronSchedule, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron %q: want 5 fields", s)
+	}
+	this := &cronSchedule{domStar: fields[2] == "*", dowStar: fields[4] == "*"}
+	for i, field := range fields {
+		set, err := parseCronField(field, cronRanges[i][0], cronRanges[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("cron %q: %v", s, err)
+		}
+		this.fields[i] = set
+	}
+	// 7 is Sunday too
+	if this.fields[4][7] {
+		this.fields[4][0] = true
+	}
+	return this, nil
+}
+
+// parseCronField parses a comma separated list of "*", "n" or "a-b",
+// each optionally followed by "/step".
+func parseCronField(field string, min, max int) (map[int]bool, error) {
+	set := make(map[int]bool)
+	for _, item := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.IndexByte(item, '/'); i >= 0 {
+			n, err := strconv.Atoi(item[i+1:])
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("bad step in %q", item)
+			}
+			step, item = n, item[:i]
+		}
+		from, to := min, max
+		if item != "*" {
+			bounds := strings.SplitN(item, "-", 2)
+			var err error
+			if from, err = strconv.Atoi(bounds[0]); err != nil {
+				return nil, fmt.Errorf("bad value %q", item)
+			}
+			to = from
+			if len(bounds) == 2 {
+				if to, err = strconv.Atoi(bounds[1]); err != nil {
+					return nil, fmt.Errorf("bad value %q", item)
+				}
+			}
+			if from < min || to > max || from > to {
+				return nil, fmt.Errorf("%q out of range %d-%d", item, min, max)
+			}
+		}
+		for v := from; v <= to; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+// matches tells whether t falls in a minute of the schedule. As in cron,
+// a day matches either day field when both are restricted.
+func (this *cronSchedule) matches(t time.Time) bool {
+	if !this.fields[0][t.Minute()] || !this.fields[1][t.Hour()] || !this.fields[3][int(t.Month())] {
+		return false
+	}
+	dom, dow := this.fields[2][t.Day()], this.fields[4][int(t.Weekday())]
+	if !this.domStar && !this.dowStar {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+/**
+ * Schedule returns `SUCCESS` within the configured times and `FAILURE`
+ * outside, e.g. to switch NPCs between day and night behaviors. The
+ * times are windows of the day, "HH:MM-HH:MM" with the end excluded
+ * ("22:00-06:00" spans midnight), optionally restricted to some days of
+ * the week, and/or a cron expression ("0,30 9-17 * * 1-5"), matched
+ * during the whole minute; both must hold when both are set.
+ *
+ * The time is read from the clock of the blackboard (see
+ * Blackboard.SetClock), in the location of the node.
+ *
+ * @module b3
+ * @class Schedule
+ * @extends Condition
+**/
+type Schedule struct {
+	Condition
+	windows  []timeWindow
+	days     map[time.Weekday]bool
+	cron     *cronSchedule
+	location *time.Location
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **windows**  (*Array*)  Windows of the day, "HH:MM-HH:MM".
+ * - **days**     (*Array*)  Days of the windows, "mon" to "sun" (default
+ *                           every day).
+ * - **cron**     (*String*) Cron expression, 5 fields.
+ * - **location** (*String*) Time zone name, e.g. "Europe/Paris" (default
+ *                           local).
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *Schedule) Initialize(setting *BTNodeCfg) {
+	this.Condition.Initialize(setting)
+	this.windows, this.days, this.cron = nil, nil, nil
+	if windows, ok := setting.Properties["windows"].([]interface{}); ok {
+		for _, v := range windows {
+			s, _ := v.(string)
+			w, err := parseTimeWindow(s)
+			if err != nil {
+				panic("Schedule condition: " + err.Error())
+			}
+			this.windows = append(this.windows, w)
+		}
+	}
+	if days, ok := setting.Properties["days"].([]interface{}); ok {
+		this.days = make(map[time.Weekday]bool)
+		for _, v := range days {
+			s, _ := v.(string)
+			day, ok := weekdays[strings.ToLower(s)]
+			if !ok {
+				panic(fmt.Sprintf("Schedule condition: unknown day %q", s))
+			}
+			this.days[day] = true
+		}
+	}
+	if _, ok := setting.Properties["cron"]; ok {
+		cron, err := parseCron(setting.GetPropertyAsString("cron"))
+		if err != nil {
+			panic("Schedule condition: " + err.Error())
+		}
+		this.cron = cron
+	}
+	if len(this.windows) == 0 && this.days == nil && this.cron == nil {
+		panic("Schedule condition: windows, days or cron is required")
+	}
+	this.location = time.Local
+	if _, ok := setting.Properties["location"]; ok {
+		location, err := time.LoadLocation(setting.GetPropertyAsString("location"))
+		if err != nil {
+			panic("Schedule condition: " + err.Error())
+		}
+		this.location = location
+	}
+}
+
+func (this *Schedule) OnTick(tick *Tick) b3.Status {
+	if this.Contains(tick.Now()) {
+		return b3.SUCCESS
+	}
+	return b3.FAILURE
+}
+
+// Contains tells whether t is within the schedule.
+func (this *Schedule) Contains(t time.Time) bool {
+	t = t.In(this.location)
+	if this.days != nil && !this.days[t.Weekday()] {
+		return false
+	}
+	if len(this.windows) > 0 {
+		minutes, in := t.Hour()*60+t.Minute(), false
+		for _, w := range this.windows {
+			if w.contains(minutes) {
+				in = true
+				break
+			}
+		}
+		if !in {
+			return false
+		}
+	}
+	return this.cron == nil || this.cron.matches(t)
+}
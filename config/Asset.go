@@ -0,0 +1,148 @@
+package config
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+)
+
+// assetMagic starts the assets encrypted by EncryptAsset.
+var assetMagic = []byte("B3E1")
+
+var ErrAssetKey = errors.New("config: encrypted asset needs a key")
+
+/**
+ * EncryptAsset encrypts data with AES-GCM for DecodeAsset, e.g. in the
+ * build pipeline of the game assets. key is 16, 24 or 32 bytes long
+ * (AES-128, -192 or -256). Compress before encrypting: encrypted data does
+ * not compress.
+ *
+ * This keeps the trees from being trivially read or edited by players,
+ * not from a determined attacker: the key ships with the game.
+**/
+func EncryptAsset(data []byte, key []byte) ([]byte, error) {
+	gcm, err := newAssetCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	out := append(append([]byte(nil), assetMagic...), nonce...)
+	return gcm.Seal(out, nonce, data, assetMagic), nil
+}
+
+/**
+ * DecodeAsset returns the content of an asset: data decrypted with key
+ * when encrypted by EncryptAsset (ErrAssetKey without key), then
+ * decompressed when gzipped. Other data is returned as is, so plain and
+ * encoded assets can be mixed.
+**/
+func DecodeAsset(data []byte, key []byte) ([]byte, error) {
+	if bytes.HasPrefix(data, assetMagic) {
+		if key == nil {
+			return nil, ErrAssetKey
+		}
+		gcm, err := newAssetCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		data = data[len(assetMagic):]
+		if len(data) < gcm.NonceSize() {
+			return nil, errors.New("config: truncated encrypted asset")
+		}
+		nonce := data[:gcm.NonceSize()]
+		if data, err = gcm.Open(nil, nonce, data[gcm.NonceSize():], assetMagic); err != nil {
+			return nil, fmt.Errorf("config: cannot decrypt asset: %v", err)
+		}
+	}
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return ioutil.ReadAll(r)
+	}
+	return data, nil
+}
+
+func newAssetCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// LoadRawProjectCfgFromAsset decodes a raw project from an asset, see
+// DecodeAsset; key may be nil for unencrypted assets.
+func LoadRawProjectCfgFromAsset(data []byte, key []byte) (*RawProjectCfg, error) {
+	data, err := DecodeAsset(data, key)
+	if err != nil {
+		return nil, err
+	}
+	return LoadRawProjectCfgFromBytes(data)
+}
+
+// LoadTreeCfgFromAsset decodes a tree config from an asset.
+func LoadTreeCfgFromAsset(data []byte, key []byte) (*BTTreeCfg, error) {
+	data, err := DecodeAsset(data, key)
+	if err != nil {
+		return nil, err
+	}
+	return LoadTreeCfgFromBytes(data)
+}
+
+/**
+ * LoadRawProjectsZip loads every .b3 raw project of a zip archive, itself
+ * possibly an encrypted or gzipped asset (see DecodeAsset), as
+ * LoadRawProjectsFS does for a directory. The files of the archive may
+ * be encoded assets too, decoded with the same key.
+**/
+func LoadRawProjectsZip(data []byte, key []byte) ([]string, map[string]*RawProjectCfg, error) {
+	data, err := DecodeAsset(data, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, nil, err
+	}
+	var paths []string
+	projects := make(map[string]*RawProjectCfg)
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() || path.Ext(file.Name) != ".b3" {
+			continue
+		}
+		project, err := loadZipProject(file, key)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %v", file.Name, err)
+		}
+		paths = append(paths, file.Name)
+		projects[file.Name] = project
+	}
+	return paths, projects, nil
+}
+
+func loadZipProject(file *zip.File, key []byte) (*RawProjectCfg, error) {
+	r, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return LoadRawProjectCfgFromAsset(data, key)
+}
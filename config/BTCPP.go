@@ -0,0 +1,244 @@
+package config
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+)
+
+// xmlElement is any element of a BehaviorTree.CPP document.
+type xmlElement struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr   `xml:",any,attr"`
+	Children []xmlElement `xml:",any"`
+}
+
+func (this *xmlElement) attr(name string) (string, bool) {
+	for _, a := range this.Attrs {
+		if a.Name.Local == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// btcppNode is the node of this package a standard BehaviorTree.CPP node
+// maps to, with the ports renamed to properties.
+type btcppNode struct {
+	name     string
+	category string
+	// port -> property, converted to a number
+	numbers map[string]string
+}
+
+var btcppNodes = map[string]btcppNode{
+	"Sequence":             {name: "Sequence", category: "composite"},
+	"SequenceStar":         {name: "MemSequence", category: "composite"},
+	"SequenceWithMemory":   {name: "MemSequence", category: "composite"},
+	"Fallback":             {name: "Priority", category: "composite"},
+	"ReactiveFallback":     {name: "ReactiveSelector", category: "composite"},
+	"Parallel":             {name: "Parallel", category: "composite"},
+	"Inverter":             {name: "Inverter", category: "decorator"},
+	"Repeat":               {name: "Repeater", category: "decorator", numbers: map[string]string{"num_cycles": "maxLoop"}},
+	"RetryUntilSuccessful": {name: "RepeatUntilSuccess", category: "decorator", numbers: map[string]string{"num_attempts": "maxLoop"}},
+	"RetryUntilSuccesful":  {name: "RepeatUntilSuccess", category: "decorator", numbers: map[string]string{"num_attempts": "maxLoop"}},
+	"Timeout":              {name: "Timeout", category: "decorator", numbers: map[string]string{"msec": "milliseconds"}},
+	"AlwaysSuccess":        {name: "Succeeder", category: "action"},
+	"AlwaysFailure":        {name: "Failer", category: "action"},
+	"SubTree":              {category: "tree"},
+	"SubTreePlus":          {category: "tree"},
+	"Action":               {category: "action"},
+	"Condition":            {category: "condition"},
+	"Decorator":            {category: "decorator"},
+	"Control":              {category: "composite"},
+}
+
+// btcppImporter converts one document, numbering the nodes per tree.
+type btcppImporter struct {
+	// categories declared in TreeNodesModel, by node ID
+	model map[string]string
+	tree  *BTTreeCfg
+	count int
+}
+
+/**
+ * LoadProjectCfgFromBTCPP converts a BehaviorTree.CPP (or Groot) XML
+ * document, formats 3 and 4, to a project holding one tree per
+ * <BehaviorTree>, the main_tree_to_execute being the selected one.
+ *
+ * The standard nodes become their counterparts of this package: Sequence,
+ * SequenceStar/SequenceWithMemory (MemSequence), Fallback (Priority),
+ * ReactiveFallback (ReactiveSelector), Parallel (with success_count and
+ * failure_count, or their _threshold forms, as policies), Inverter,
+ * Repeat (Repeater), RetryUntilSuccessful (RepeatUntilSuccess), Timeout,
+ * AlwaysSuccess (Succeeder), AlwaysFailure (Failer), and SubTree, a
+ * subtree node named after the tree ID. Any other node keeps its name
+ * (the ID of <Action ID="..."> and the like) and gets its ports as string
+ * properties, to be registered as a custom node; its category comes from
+ * the TreeNodesModel when declared there.
+ *
+ * Node ids are "<tree ID>/<n>" and titles the name attribute, when set.
+**/
+func LoadProjectCfgFromBTCPP(data []byte) (*BTProjectCfg, error) {
+	var root xmlElement
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if root.XMLName.Local != "root" {
+		return nil, fmt.Errorf("btcpp: root element is <%s>, want <root>", root.XMLName.Local)
+	}
+	importer := &btcppImporter{model: make(map[string]string)}
+	for _, e := range root.Children {
+		if e.XMLName.Local != "TreeNodesModel" {
+			continue
+		}
+		for _, m := range e.Children {
+			id, _ := m.attr("ID")
+			if node, ok := btcppNodes[m.XMLName.Local]; ok && id != "" {
+				importer.model[id] = node.category
+			}
+		}
+	}
+
+	project := &BTProjectCfg{}
+	project.Select, _ = root.attr("main_tree_to_execute")
+	for _, e := range root.Children {
+		if e.XMLName.Local != "BehaviorTree" {
+			continue
+		}
+		tree, err := importer.importTree(&e)
+		if err != nil {
+			return nil, err
+		}
+		project.Trees = append(project.Trees, *tree)
+	}
+	if len(project.Trees) == 0 {
+		return nil, fmt.Errorf("btcpp: no <BehaviorTree>")
+	}
+	if project.Select == "" {
+		project.Select = project.Trees[0].ID
+	}
+	return project, nil
+}
+
+// LoadProjectBTCPP loads a project from a BehaviorTree.CPP XML file, see
+// LoadProjectCfgFromBTCPP.
+func LoadProjectBTCPP(path string) (*BTProjectCfg, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	project, err := LoadProjectCfgFromBTCPP(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return project, nil
+}
+
+func (this *btcppImporter) importTree(e *xmlElement) (*BTTreeCfg, error) {
+	id, _ := e.attr("ID")
+	if id == "" {
+		return nil, fmt.Errorf("btcpp: <BehaviorTree> without ID")
+	}
+	if len(e.Children) != 1 {
+		return nil, fmt.Errorf("btcpp: tree %s has %d root nodes, want 1", id, len(e.Children))
+	}
+	this.tree = &BTTreeCfg{ID: id, Title: id, Nodes: make(map[string]BTNodeCfg)}
+	this.count = 0
+	root, err := this.importNode(&e.Children[0])
+	if err != nil {
+		return nil, fmt.Errorf("btcpp: tree %s: %v", id, err)
+	}
+	this.tree.Root = root
+	return this.tree, nil
+}
+
+// importNode adds e and its children to the tree and returns its id.
+func (this *btcppImporter) importNode(e *xmlElement) (string, error) {
+	tag := e.XMLName.Local
+	this.count++
+	node := BTNodeCfg{
+		Id:         fmt.Sprintf("%s/%d", this.tree.ID, this.count),
+		Name:       tag,
+		Properties: make(map[string]interface{}),
+	}
+	std, ok := btcppNodes[tag]
+	if ok {
+		node.Category = std.category
+		if std.name != "" {
+			node.Name = std.name
+		}
+	} else if category, ok := this.model[tag]; ok {
+		node.Category = category
+	} else if len(e.Children) == 0 {
+		node.Category = "action"
+	} else {
+		node.Category = "composite"
+	}
+
+	for _, a := range e.Attrs {
+		switch name := a.Name.Local; {
+		case name == "name":
+			node.Title = a.Value
+		case name == "ID" && ok && std.name == "":
+			// <Action ID="..."/> and <SubTree ID="..."/>
+			node.Name = a.Value
+			if category, ok := this.model[a.Value]; ok && node.Category != "tree" {
+				node.Category = category
+			}
+		case std.numbers[name] != "":
+			f, err := strconv.ParseFloat(a.Value, 64)
+			if err != nil {
+				return "", fmt.Errorf("<%s %s=%q>: not a number", tag, name, a.Value)
+			}
+			node.Properties[std.numbers[name]] = NumberValue(strconv.FormatFloat(f, 'f', -1, 64))
+		case tag == "Parallel":
+			if policy := btcppPolicy(name); policy != "" {
+				node.Properties[policy] = a.Value
+				if a.Value == "-1" {
+					node.Properties[policy] = "all"
+				}
+				break
+			}
+			node.Properties[name] = a.Value
+		default:
+			node.Properties[name] = a.Value
+		}
+	}
+	if node.Title == "" {
+		node.Title = node.Name
+	}
+
+	for i := range e.Children {
+		child, err := this.importNode(&e.Children[i])
+		if err != nil {
+			return "", err
+		}
+		node.Children = append(node.Children, child)
+	}
+	switch {
+	case node.Category == "decorator":
+		if len(node.Children) != 1 {
+			return "", fmt.Errorf("<%s> has %d children, want 1", tag, len(node.Children))
+		}
+		node.Child, node.Children = node.Children[0], nil
+	case len(node.Children) == 1 && std.name == "":
+		// a custom node with one child may be a decorator, only known
+		// once registered: set both
+		node.Child = node.Children[0]
+	}
+	this.tree.Nodes[node.Id] = node
+	return node.Id, nil
+}
+
+// btcppPolicy returns the Parallel property of a port, "" for the others.
+func btcppPolicy(port string) string {
+	switch port {
+	case "success_count", "success_threshold", "threshold":
+		return "successPolicy"
+	case "failure_count", "failure_threshold":
+		return "failurePolicy"
+	}
+	return ""
+}
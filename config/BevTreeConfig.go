@@ -1,9 +1,9 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"strconv"
 )
 
 //编辑器地址@http://editor.behavior3.com/#/editor
@@ -18,6 +18,8 @@ type BTNodeCfg struct {
 	Child       string                 `json:"child"`
 	Parameters  map[string]interface{} `json:"parameters"`
 	Properties  map[string]interface{} `json:"properties"`
+	// editor layout (position), kept for the round trip
+	Display map[string]interface{} `json:"display,omitempty"`
 }
 
 func (this *BTNodeCfg) GetProperty(name string) float64 {
@@ -26,7 +28,7 @@ func (this *BTNodeCfg) GetProperty(name string) float64 {
 		panic("GetProperty err ,no vlaue:" + name)
 		return 0
 	}
-	f64, fok := v.(float64)
+	f64, fok := toFloat64(v)
 	if !fok {
 		fmt.Println("GetProperty err ,format not fload64:", name, v)
 		panic("GetProperty err ,format not fload64:" + name)
@@ -41,9 +43,16 @@ func (this *BTNodeCfg) GetPropertyAsInt(name string) int {
 	return i
 }
 func (this *BTNodeCfg) GetPropertyAsInt64(name string) int64 {
-	v := this.GetProperty(name)
-	i := int64(v)
-	return i
+	//整数(含数字字符串)不经过float64, 与GetPropertyAsUint64一致
+	text := this.numberProperty(name)
+	if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return i
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		panic("GetProperty err ,format not int64:" + name)
+	}
+	return int64(f)
 }
 func (this *BTNodeCfg) GetPropertyAsBool(name string) bool {
 	v, ok := this.Properties[name]
@@ -87,23 +96,24 @@ type BTTreeCfg struct {
 	Root        string                 `json:"root"`
 	Properties  map[string]interface{} `json:"properties"`
 	Nodes       map[string]BTNodeCfg   `json:"nodes"`
+	// editor layout (camera, root position), kept for the round trip
+	Display map[string]interface{} `json:"display,omitempty"`
 }
 
 //加载
 func LoadTreeCfg(path string) (*BTTreeCfg, bool) {
 
-	var tree BTTreeCfg
 	file, err := ioutil.ReadFile(path)
 	if err != nil {
 		fmt.Println("fail:", err)
 		return nil, false
 	}
-	err = json.Unmarshal(file, &tree)
+	tree, err := LoadTreeCfgFromBytes(file)
 	if err != nil {
 		fmt.Println("fail, ummarshal:", err, len(file))
 		return nil, false
 	}
 
 	//fmt.Println("load tree:", tree.Title, " nodes:", len(tree.Nodes))
-	return &tree, true
+	return tree, true
 }
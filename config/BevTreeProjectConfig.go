@@ -1,9 +1,9 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"sort"
 )
 
 //工程json类型
@@ -12,23 +12,72 @@ type BTProjectCfg struct {
 	Select string                 `json:"selectedTree"`
 	Scope        string                 `json:"scope"`
 	Trees       []BTTreeCfg   `json:"trees"`
+	// ids of the trees ticked together per agent, in order (see RunOrder)
+	Roots []string `json:"roots,omitempty"`
+	// the custom nodes declared in the editor
+	CustomNodes []BTCustomNodeCfg `json:"custom_nodes,omitempty"`
+}
+
+// BTCustomNodeCfg is a custom node declared in a behavior3editor project,
+// with the default values of its properties.
+type BTCustomNodeCfg struct {
+	Name        string                 `json:"name"`
+	Category    string                 `json:"category"`
+	Title       string                 `json:"title"`
+	Description string                 `json:"description,omitempty"`
+	Properties  map[string]interface{} `json:"properties,omitempty"`
+}
+
+/**
+ * RunOrder returns the ids of the trees of the project meant to run
+ * together per agent, in tick order: the "roots" of the project if set,
+ * otherwise the trees having a numeric "runOrder" property (which the
+ * editor can set), sorted by it, otherwise the selected tree.
+**/
+func (this *BTProjectCfg) RunOrder() []string {
+	if len(this.Roots) > 0 {
+		return this.Roots
+	}
+	type ordered struct {
+		id    string
+		order float64
+	}
+	var trees []ordered
+	for _, tree := range this.Trees {
+		if order, ok := toFloat64(tree.Properties["runOrder"]); ok {
+			trees = append(trees, ordered{tree.ID, order})
+		}
+	}
+	if len(trees) == 0 {
+		if this.Select != "" {
+			return []string{this.Select}
+		}
+		return nil
+	}
+	sort.SliceStable(trees, func(i, j int) bool {
+		return trees[i].order < trees[j].order
+	})
+	ids := make([]string, len(trees))
+	for i, t := range trees {
+		ids[i] = t.id
+	}
+	return ids
 }
 
 //加载
 func LoadProjectCfg(path string) (*BTProjectCfg, bool) {
 
-	var project BTProjectCfg
 	file, err := ioutil.ReadFile(path)
 	if err != nil {
 		fmt.Println("LoadProjectCfg fail:", err)
 		return nil, false
 	}
-	err = json.Unmarshal(file, &project)
+	project, err := LoadProjectCfgFromBytes(file)
 	if err != nil {
 		fmt.Println("LoadProjectCfg fail, ummarshal:", err, len(file))
 		return nil, false
 	}
 
 	//fmt.Println("load tree:", tree.Title, " nodes:", len(tree.Nodes))
-	return &project, true
+	return project, true
 }
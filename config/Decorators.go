@@ -0,0 +1,135 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+)
+
+/**
+ * ExpandDecorators returns a copy of tree where the "decorators" property
+ * of the nodes is turned into decorator nodes, for editors unable to nest
+ * decorators deeply. The property lists the decorators wrapping the node,
+ * outermost first, each one a node name or an object with name, title and
+ * properties:
+ *
+ *     "properties": {
+ *         "decorators": ["Inverter", {"name": "Timeout", "properties": {"milliseconds": 500}}]
+ *     }
+ *
+ * makes Inverter(Timeout(node)). The decorators get the ids
+ * "<node id>.decorators.<index>" and take the place of the node in its
+ * parent (or as root). Trees without the property are returned as is.
+**/
+func ExpandDecorators(tree *BTTreeCfg) (*BTTreeCfg, error) {
+	expand := false
+	for _, node := range tree.Nodes {
+		if _, ok := node.Properties["decorators"]; ok {
+			expand = true
+			break
+		}
+	}
+	if !expand {
+		return tree, nil
+	}
+
+	// node id -> its decorators, and the id of the outermost one
+	chains := make(map[string][]BTNodeCfg)
+	outer := make(map[string]string)
+	for id, node := range tree.Nodes {
+		list, ok := node.Properties["decorators"]
+		if !ok {
+			continue
+		}
+		decorators, err := parseDecorators(list)
+		if err != nil {
+			return nil, fmt.Errorf("node %s(%s): decorators: %v", node.Title, id, err)
+		}
+		child := id
+		for i := len(decorators) - 1; i >= 0; i-- {
+			dec := &decorators[i]
+			dec.Id = id + ".decorators." + strconv.Itoa(i)
+			dec.Category = "decorator"
+			dec.Child = child
+			if _, exists := tree.Nodes[dec.Id]; exists {
+				return nil, fmt.Errorf("node %s(%s): decorators: id %s already used", node.Title, id, dec.Id)
+			}
+			child = dec.Id
+		}
+		chains[id] = decorators
+		outer[id] = child
+	}
+	wrap := func(id string) string {
+		if o, ok := outer[id]; ok {
+			return o
+		}
+		return id
+	}
+
+	out := *tree
+	out.Root = wrap(tree.Root)
+	out.Nodes = make(map[string]BTNodeCfg, len(tree.Nodes))
+	for id, node := range tree.Nodes {
+		if _, ok := chains[id]; ok {
+			props := make(map[string]interface{}, len(node.Properties))
+			for k, v := range node.Properties {
+				if k != "decorators" {
+					props[k] = v
+				}
+			}
+			node.Properties = props
+		}
+		if node.Child != "" {
+			node.Child = wrap(node.Child)
+		}
+		if node.Children != nil {
+			children := make([]string, len(node.Children))
+			for i, child := range node.Children {
+				children[i] = wrap(child)
+			}
+			node.Children = children
+		}
+		out.Nodes[id] = node
+	}
+	for _, decorators := range chains {
+		for _, dec := range decorators {
+			out.Nodes[dec.Id] = dec
+		}
+	}
+	return &out, nil
+}
+
+func parseDecorators(list interface{}) ([]BTNodeCfg, error) {
+	items, ok := list.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("not a list: %v", list)
+	}
+	decorators := make([]BTNodeCfg, 0, len(items))
+	for i, item := range items {
+		var dec BTNodeCfg
+		switch item := item.(type) {
+		case string:
+			dec.Name = item
+		case map[string]interface{}:
+			dec.Name, _ = item["name"].(string)
+			dec.Title, _ = item["title"].(string)
+			if props, ok := item["properties"]; ok {
+				if dec.Properties, ok = props.(map[string]interface{}); !ok {
+					return nil, fmt.Errorf("#%d: properties is not an object", i)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("#%d: not a name or an object: %v", i, item)
+		}
+		if dec.Name == "" {
+			return nil, fmt.Errorf("#%d: no name", i)
+		}
+		if dec.Title == "" {
+			dec.Title = dec.Name
+		}
+		if dec.Properties == nil {
+			dec.Properties = map[string]interface{}{}
+		}
+		decorators = append(decorators, dec)
+	}
+	return decorators, nil
+}
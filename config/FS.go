@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+)
+
+/**
+ * LoadRawProjectsFS loads every .b3 raw project found under root in fsys,
+ * recursively, e.g. from an embed.FS so the trees ship inside the binary:
+ *
+ *     //go:embed ai
+ *     var aiFiles embed.FS
+ *
+ *     projects, err := config.LoadRawProjectsFS(aiFiles, "ai")
+ *
+ * It returns the paths of the projects, sorted, and the projects by path.
+**/
+func LoadRawProjectsFS(fsys fs.FS, root string) ([]string, map[string]*RawProjectCfg, error) {
+	var paths []string
+	projects := make(map[string]*RawProjectCfg)
+	err := fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(name) != ".b3" {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		project, err := LoadRawProjectCfgFromBytes(data)
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		paths = append(paths, name)
+		projects[name] = project
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(paths)
+	return paths, projects, nil
+}
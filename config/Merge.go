@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+)
+
+/**
+ * MergeProjects merges projects, e.g. the editor projects of several
+ * teams, into one: their trees and custom nodes share one namespace. A
+ * tree ID used by two projects is an error, and so is a custom node
+ * declared twice differently; identical declarations are kept once. The id,
+ * selected tree and scope are those of the first project setting them,
+ * the roots are appended in order. names label the projects in the
+ * errors, e.g. their paths, and may be nil.
+ *
+ * The trees are copied, the projects are not modified.
+**/
+func MergeProjects(names []string, projects ...*BTProjectCfg) (*BTProjectCfg, error) {
+	label := func(i int) string {
+		if i < len(names) {
+			return names[i]
+		}
+		if projects[i].ID != "" {
+			return "project " + projects[i].ID
+		}
+		return fmt.Sprintf("project #%d", i)
+	}
+	merged := &BTProjectCfg{}
+	treeFrom := make(map[string]int)
+	nodeFrom := make(map[string]int)
+	for i, project := range projects {
+		if merged.ID == "" {
+			merged.ID = project.ID
+		}
+		if merged.Select == "" {
+			merged.Select = project.Select
+		}
+		if merged.Scope == "" {
+			merged.Scope = project.Scope
+		}
+		for _, tree := range project.Trees {
+			if other, ok := treeFrom[tree.ID]; ok {
+				return nil, fmt.Errorf("%s: tree %s already defined by %s", label(i), tree.ID, label(other))
+			}
+			treeFrom[tree.ID] = i
+			merged.Trees = append(merged.Trees, tree)
+		}
+		merged.Roots = append(merged.Roots, project.Roots...)
+		for _, node := range project.CustomNodes {
+			if other, ok := nodeFrom[node.Name]; ok {
+				if !reflect.DeepEqual(node, *findCustomNode(merged, node.Name)) {
+					return nil, fmt.Errorf("%s: custom node %s declared differently by %s", label(i), node.Name, label(other))
+				}
+				continue
+			}
+			nodeFrom[node.Name] = i
+			merged.CustomNodes = append(merged.CustomNodes, node)
+		}
+	}
+	return merged, nil
+}
+
+func findCustomNode(project *BTProjectCfg, name string) *BTCustomNodeCfg {
+	for i := range project.CustomNodes {
+		if project.CustomNodes[i].Name == name {
+			return &project.CustomNodes[i]
+		}
+	}
+	return nil
+}
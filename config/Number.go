@@ -0,0 +1,79 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+var useNumber bool
+
+// SetUseNumber makes the configs decode the numbers of the properties as
+// json.Number instead of float64, so large integer properties (ids,
+// amounts) keep every digit through GetPropertyAsInt64 and
+// GetPropertyAsUint64. Nodes asserting Properties[name].(float64) must
+// then use the accessors instead.
+func SetUseNumber(enable bool) {
+	useNumber = enable
+}
+
+// Unmarshal decodes configuration JSON, the numbers as float64 or, after
+// SetUseNumber(true), as json.Number.
+func Unmarshal(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if useNumber {
+		dec.UseNumber()
+	}
+	return dec.Decode(v)
+}
+
+// NumberValue returns the number of text as Unmarshal decodes it, for the
+// properties written by other means than JSON.
+func NumberValue(text string) interface{} {
+	if useNumber {
+		return json.Number(text)
+	}
+	f, _ := strconv.ParseFloat(text, 64)
+	return f
+}
+
+// toFloat64 accepts the numbers of both decodings, float64 and
+// json.Number.
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func (this *BTNodeCfg) numberProperty(name string) string {
+	v, ok := this.Properties[name]
+	if !ok {
+		panic("GetProperty err ,no vlaue:" + name)
+	}
+	switch n := v.(type) {
+	case json.Number:
+		return n.String()
+	case float64:
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	case string:
+		return n
+	}
+	panic(fmt.Sprintf("GetProperty err ,format not number:%s %v", name, v))
+}
+
+// GetPropertyAsUint64 returns an unsigned integer property, a number or
+// numeric string, without going through float64 for json.Number; it
+// panics if the property is missing or not an unsigned integer.
+func (this *BTNodeCfg) GetPropertyAsUint64(name string) uint64 {
+	u, err := strconv.ParseUint(this.numberProperty(name), 10, 64)
+	if err != nil {
+		panic("GetProperty err ,format not uint64:" + name)
+	}
+	return u
+}
@@ -0,0 +1,359 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sort"
+
+	"github.com/youngtrips/behavior3go/internal/pbwire"
+)
+
+/**
+ * MarshalTreeProto encodes a tree config as the Tree message of
+ * config.proto, e.g. to ship it through a protobuf asset pipeline or
+ * gRPC. The properties become google.protobuf.Struct values, whose
+ * numbers are doubles: integers beyond 2^53 lose precision. The output is
+ * deterministic, nodes and properties being sorted.
+**/
+func MarshalTreeProto(tree *BTTreeCfg) ([]byte, error) {
+	e := pbwire.NewEncoder()
+	if err := encodeTree(e, tree); err != nil {
+		return nil, fmt.Errorf("config: tree %s: %v", tree.ID, err)
+	}
+	return e.Bytes(), nil
+}
+
+// MarshalProjectProto encodes a project config as the Project message of
+// config.proto.
+func MarshalProjectProto(project *BTProjectCfg) ([]byte, error) {
+	e := pbwire.NewEncoder()
+	e.OptString(1, project.ID)
+	e.OptString(2, project.Select)
+	e.OptString(3, project.Scope)
+	for i := range project.Trees {
+		tree := &project.Trees[i]
+		var err error
+		e.Message(4, func(e *pbwire.Encoder) {
+			err = encodeTree(e, tree)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("config: tree %s: %v", tree.ID, err)
+		}
+	}
+	for _, id := range project.Roots {
+		e.String(5, id)
+	}
+	return e.Bytes(), nil
+}
+
+// LoadTreeCfgFromProto decodes a Tree message, the numbers of the
+// properties decoded as with the JSON configs, see SetUseNumber.
+func LoadTreeCfgFromProto(data []byte) (*BTTreeCfg, error) {
+	tree, err := decodeTree(data)
+	if err != nil {
+		return nil, fmt.Errorf("config: %v", err)
+	}
+	return tree, nil
+}
+
+// LoadProjectCfgFromProto decodes a Project message.
+func LoadProjectCfgFromProto(data []byte) (*BTProjectCfg, error) {
+	project := &BTProjectCfg{}
+	err := pbwire.Parse(data, func(f pbwire.Field) error {
+		switch f.Num {
+		case 1:
+			project.ID = f.String()
+		case 2:
+			project.Select = f.String()
+		case 3:
+			project.Scope = f.String()
+		case 4:
+			tree, err := decodeTree(f.Data)
+			if err != nil {
+				return err
+			}
+			project.Trees = append(project.Trees, *tree)
+		case 5:
+			project.Roots = append(project.Roots, f.String())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("config: %v", err)
+	}
+	return project, nil
+}
+
+// LoadProjectProto loads a project from a file holding a Project message.
+func LoadProjectProto(path string) (*BTProjectCfg, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	project, err := LoadProjectCfgFromProto(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return project, nil
+}
+
+// SaveProjectProto writes project to a file as a Project message.
+func SaveProjectProto(path string, project *BTProjectCfg) error {
+	data, err := MarshalProjectProto(project)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// ------------------------encoding-------------------------
+
+func encodeTree(e *pbwire.Encoder, tree *BTTreeCfg) error {
+	e.OptString(1, tree.ID)
+	e.OptString(2, tree.Title)
+	e.OptString(3, tree.Description)
+	e.OptString(4, tree.Root)
+	if err := encodeStruct(e, 5, tree.Properties); err != nil {
+		return err
+	}
+	ids := make([]string, 0, len(tree.Nodes))
+	for id := range tree.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		node := tree.Nodes[id]
+		var err error
+		e.Message(6, func(e *pbwire.Encoder) {
+			err = encodeNode(e, &node)
+		})
+		if err != nil {
+			return fmt.Errorf("node %s: %v", id, err)
+		}
+	}
+	return nil
+}
+
+func encodeNode(e *pbwire.Encoder, node *BTNodeCfg) error {
+	e.OptString(1, node.Id)
+	e.OptString(2, node.Name)
+	e.OptString(3, node.Category)
+	e.OptString(4, node.Title)
+	e.OptString(5, node.Description)
+	for _, id := range node.Children {
+		e.String(6, id)
+	}
+	e.OptString(7, node.Child)
+	if err := encodeStruct(e, 8, node.Parameters); err != nil {
+		return err
+	}
+	return encodeStruct(e, 9, node.Properties)
+}
+
+// encodeStruct writes m as a google.protobuf.Struct field, omitted when
+// nil.
+func encodeStruct(e *pbwire.Encoder, num int, m map[string]interface{}) error {
+	if m == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var err error
+	e.Message(num, func(e *pbwire.Encoder) {
+		for _, k := range keys {
+			e.Message(1, func(e *pbwire.Encoder) {
+				e.String(1, k)
+				e.Message(2, func(e *pbwire.Encoder) {
+					if verr := encodeValue(e, m[k]); verr != nil && err == nil {
+						err = fmt.Errorf("%s: %v", k, verr)
+					}
+				})
+			})
+		}
+	})
+	return err
+}
+
+// encodeValue writes the fields of a google.protobuf.Value.
+func encodeValue(e *pbwire.Encoder, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		e.Uint64(1, 0)
+	case string:
+		e.String(3, v)
+	case bool:
+		e.Bool(4, v)
+	case map[string]interface{}:
+		if v == nil {
+			e.Uint64(1, 0)
+			return nil
+		}
+		return encodeStruct(e, 5, v)
+	case []interface{}:
+		var err error
+		e.Message(6, func(e *pbwire.Encoder) {
+			for i, item := range v {
+				e.Message(1, func(e *pbwire.Encoder) {
+					if verr := encodeValue(e, item); verr != nil && err == nil {
+						err = fmt.Errorf("[%d]: %v", i, verr)
+					}
+				})
+			}
+		})
+		return err
+	default:
+		f, ok := toNumber(v)
+		if !ok {
+			return fmt.Errorf("unsupported value %T", value)
+		}
+		e.Double(2, f)
+	}
+	return nil
+}
+
+// toNumber accepts the numbers of the decoded configs and of the configs
+// built in code.
+func toNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	}
+	return toFloat64(v)
+}
+
+// ------------------------decoding-------------------------
+
+func decodeTree(data []byte) (*BTTreeCfg, error) {
+	tree := &BTTreeCfg{Nodes: make(map[string]BTNodeCfg)}
+	err := pbwire.Parse(data, func(f pbwire.Field) error {
+		var err error
+		switch f.Num {
+		case 1:
+			tree.ID = f.String()
+		case 2:
+			tree.Title = f.String()
+		case 3:
+			tree.Description = f.String()
+		case 4:
+			tree.Root = f.String()
+		case 5:
+			tree.Properties, err = decodeStruct(f.Data)
+		case 6:
+			var node BTNodeCfg
+			if node, err = decodeNode(f.Data); err == nil {
+				tree.Nodes[node.Id] = node
+			}
+		}
+		return err
+	})
+	return tree, err
+}
+
+func decodeNode(data []byte) (BTNodeCfg, error) {
+	var node BTNodeCfg
+	err := pbwire.Parse(data, func(f pbwire.Field) error {
+		var err error
+		switch f.Num {
+		case 1:
+			node.Id = f.String()
+		case 2:
+			node.Name = f.String()
+		case 3:
+			node.Category = f.String()
+		case 4:
+			node.Title = f.String()
+		case 5:
+			node.Description = f.String()
+		case 6:
+			node.Children = append(node.Children, f.String())
+		case 7:
+			node.Child = f.String()
+		case 8:
+			node.Parameters, err = decodeStruct(f.Data)
+		case 9:
+			node.Properties, err = decodeStruct(f.Data)
+		}
+		return err
+	})
+	return node, err
+}
+
+func decodeStruct(data []byte) (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+	err := pbwire.Parse(data, func(f pbwire.Field) error {
+		if f.Num != 1 {
+			return nil
+		}
+		var key string
+		var value interface{}
+		err := pbwire.Parse(f.Data, func(f pbwire.Field) error {
+			var err error
+			switch f.Num {
+			case 1:
+				key = f.String()
+			case 2:
+				value, err = decodeValue(f.Data)
+			}
+			return err
+		})
+		m[key] = value
+		return err
+	})
+	return m, err
+}
+
+func decodeValue(data []byte) (interface{}, error) {
+	var value interface{}
+	err := pbwire.Parse(data, func(f pbwire.Field) error {
+		var err error
+		switch f.Num {
+		case 1:
+			value = nil
+		case 2:
+			value = jsonNumber(f.Double())
+		case 3:
+			value = f.String()
+		case 4:
+			value = f.Bool()
+		case 5:
+			value, err = decodeStruct(f.Data)
+		case 6:
+			list := []interface{}{}
+			err = pbwire.Parse(f.Data, func(f pbwire.Field) error {
+				if f.Num != 1 {
+					return nil
+				}
+				item, err := decodeValue(f.Data)
+				list = append(list, item)
+				return err
+			})
+			value = list
+		}
+		return err
+	})
+	return value, err
+}
+
+// jsonNumber returns f as Unmarshal would decode it.
+func jsonNumber(f float64) interface{} {
+	if !useNumber || math.IsNaN(f) || math.IsInf(f, 0) {
+		return f
+	}
+	data, _ := json.Marshal(f)
+	return json.Number(data)
+}
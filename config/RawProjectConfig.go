@@ -1,7 +1,6 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
 	"io/ioutil"
 )
@@ -16,18 +15,17 @@ type RawProjectCfg struct {
 //加载原生工程
 func LoadRawProjectCfg(path string) (*RawProjectCfg, bool) {
 
-	var project RawProjectCfg
 	file, err := ioutil.ReadFile(path)
 	if err != nil {
 		fmt.Println("LoadRawProjectCfg fail:", err)
 		return nil, false
 	}
-	err = json.Unmarshal(file, &project)
+	project, err := LoadRawProjectCfgFromBytes(file)
 	if err != nil {
 		fmt.Println("LoadRawProjectCfg fail, ummarshal:", err, len(file))
 		return nil, false
 	}
 
 	//fmt.Println("load tree:", tree.Title, " nodes:", len(tree.Nodes))
-	return &project, true
+	return project, true
 }
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// LoadTreeCfgFromBytes decodes a tree config, as exported by the editor,
+// e.g. from an embedded asset or a database.
+func LoadTreeCfgFromBytes(data []byte) (*BTTreeCfg, error) {
+	var tree BTTreeCfg
+	if err := Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	return &tree, nil
+}
+
+// LoadTreeCfgFromReader decodes a tree config read from r until EOF.
+func LoadTreeCfgFromReader(r io.Reader) (*BTTreeCfg, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return LoadTreeCfgFromBytes(data)
+}
+
+// LoadProjectCfgFromBytes decodes a project config.
+func LoadProjectCfgFromBytes(data []byte) (*BTProjectCfg, error) {
+	var project BTProjectCfg
+	if err := Unmarshal(data, &project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// LoadProjectCfgFromReader decodes a project config read from r until EOF.
+func LoadProjectCfgFromReader(r io.Reader) (*BTProjectCfg, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return LoadProjectCfgFromBytes(data)
+}
+
+// LoadRawProjectCfgFromBytes decodes a raw project, the .b3 file of the
+// editor.
+func LoadRawProjectCfgFromBytes(data []byte) (*RawProjectCfg, error) {
+	var project RawProjectCfg
+	if err := Unmarshal(data, &project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// LoadRawProjectCfgFromReader decodes a raw project read from r until EOF.
+func LoadRawProjectCfgFromReader(r io.Reader) (*RawProjectCfg, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return LoadRawProjectCfgFromBytes(data)
+}
+
+// ReadTreeCfg is LoadTreeCfg returning the error.
+func ReadTreeCfg(path string) (*BTTreeCfg, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := LoadTreeCfgFromBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return tree, nil
+}
+
+// ReadProjectCfg is LoadProjectCfg returning the error.
+func ReadProjectCfg(path string) (*BTProjectCfg, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	project, err := LoadProjectCfgFromBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return project, nil
+}
+
+// ReadRawProjectCfg is LoadRawProjectCfg returning the error.
+func ReadRawProjectCfg(path string) (*RawProjectCfg, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	project, err := LoadRawProjectCfgFromBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return project, nil
+}
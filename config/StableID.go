@@ -0,0 +1,81 @@
+package config
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+)
+
+/**
+ * StableNodeIDs returns a copy of tree where every node id is derived from
+ * its content instead of the GUID of the editor: the tree title, the path
+ * of the node from the root (child indexes), its name and its properties.
+ * Re-exporting an unchanged tree then gives the same ids, so node memories
+ * saved on blackboards, snapshots and recorded traces stay valid across
+ * exports and hot reloads.
+ *
+ * Editing a node (renaming it, changing a property or moving it) gives it
+ * a new id, like deleting and recreating it would. Nodes not reachable
+ * from the root keep their id.
+**/
+func StableNodeIDs(tree *BTTreeCfg) *BTTreeCfg {
+	ids := make(map[string]string, len(tree.Nodes))
+	var walk func(id string, path string)
+	walk = func(id string, path string) {
+		node, ok := tree.Nodes[id]
+		if !ok {
+			return
+		}
+		if _, seen := ids[id]; seen {
+			return
+		}
+		ids[id] = stableNodeID(tree.Title, path, &node)
+		if node.Child != "" {
+			walk(node.Child, path+"/0")
+		}
+		for i, child := range node.Children {
+			walk(child, path+"/"+strconv.Itoa(i))
+		}
+	}
+	walk(tree.Root, "")
+
+	rename := func(id string) string {
+		if s, ok := ids[id]; ok {
+			return s
+		}
+		return id
+	}
+	out := *tree
+	out.Root = rename(tree.Root)
+	out.Nodes = make(map[string]BTNodeCfg, len(tree.Nodes))
+	for id, node := range tree.Nodes {
+		node.Id = rename(id)
+		if node.Child != "" {
+			node.Child = rename(node.Child)
+		}
+		if node.Children != nil {
+			children := make([]string, len(node.Children))
+			for i, child := range node.Children {
+				children[i] = rename(child)
+			}
+			node.Children = children
+		}
+		out.Nodes[node.Id] = node
+	}
+	return &out
+}
+
+func stableNodeID(treeTitle string, path string, node *BTNodeCfg) string {
+	h := sha1.New()
+	h.Write([]byte(treeTitle))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write([]byte(node.Name))
+	h.Write([]byte{0})
+	// json sorts map keys, so equal properties always hash the same
+	props, _ := json.Marshal(node.Properties)
+	h.Write(props)
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
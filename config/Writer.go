@@ -0,0 +1,76 @@
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+)
+
+/**
+ * MarshalTreeJSON encodes a tree config as behavior3editor JSON, which
+ * LoadTreeCfgFromBytes reads back: ids, titles, properties and the
+ * display (layout) of the editor are kept. The nil properties and
+ * parameters are written as empty objects, as the editor does.
+**/
+func MarshalTreeJSON(tree *BTTreeCfg) ([]byte, error) {
+	return json.MarshalIndent(editorTree(tree), "", "  ")
+}
+
+// MarshalProjectJSON encodes a project config as behavior3editor JSON.
+func MarshalProjectJSON(project *BTProjectCfg) ([]byte, error) {
+	return json.MarshalIndent(editorProject(project), "", "  ")
+}
+
+// MarshalRawProjectJSON encodes a raw project as a .b3 file of
+// behavior3editor.
+func MarshalRawProjectJSON(raw *RawProjectCfg) ([]byte, error) {
+	copied := *raw
+	copied.Data = *editorProject(&raw.Data)
+	return json.MarshalIndent(&copied, "", "  ")
+}
+
+// SaveTreeCfg writes tree to a file as behavior3editor JSON.
+func SaveTreeCfg(path string, tree *BTTreeCfg) error {
+	data, err := MarshalTreeJSON(tree)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// SaveRawProjectCfg writes raw to a .b3 file.
+func SaveRawProjectCfg(path string, raw *RawProjectCfg) error {
+	data, err := MarshalRawProjectJSON(raw)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+func editorProject(project *BTProjectCfg) *BTProjectCfg {
+	copied := *project
+	copied.Trees = make([]BTTreeCfg, len(project.Trees))
+	for i := range project.Trees {
+		copied.Trees[i] = *editorTree(&project.Trees[i])
+	}
+	return &copied
+}
+
+// editorTree returns a copy of tree with the maps the editor expects.
+func editorTree(tree *BTTreeCfg) *BTTreeCfg {
+	copied := *tree
+	copied.Properties = emptyIfNil(tree.Properties)
+	copied.Nodes = make(map[string]BTNodeCfg, len(tree.Nodes))
+	for id, node := range tree.Nodes {
+		node.Properties = emptyIfNil(node.Properties)
+		node.Parameters = emptyIfNil(node.Parameters)
+		copied.Nodes[id] = node
+	}
+	return &copied
+}
+
+func emptyIfNil(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return map[string]interface{}{}
+	}
+	return m
+}
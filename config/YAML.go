@@ -0,0 +1,110 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v3"
+)
+
+/**
+ * YAMLToJSON converts a YAML document to JSON, so YAML configs share the
+ * structures and decoding of the JSON ones: the keys are the JSON names
+ * ("root", "nodes", "selectedTree"...). A tree reads:
+ *
+ *     id: patrol
+ *     title: Patrol
+ *     root: seq
+ *     nodes:
+ *       seq:
+ *         id: seq
+ *         name: Sequence
+ *         category: composite
+ *         children: [walk, wait]
+ *       walk:
+ *         id: walk
+ *         name: Log
+ *         properties:
+ *           info: walking
+ *       wait:
+ *         id: wait
+ *         name: Wait
+ *         properties:
+ *           milliseconds: 500
+**/
+func YAMLToJSON(data []byte) ([]byte, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonValue(doc))
+}
+
+// jsonValue converts the maps with non string keys yaml can decode to
+// maps json can encode.
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = jsonValue(e)
+		}
+		return v
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = jsonValue(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range v {
+			v[i] = jsonValue(e)
+		}
+		return v
+	}
+	return v
+}
+
+// LoadTreeCfgFromYAML decodes a tree config written in YAML.
+func LoadTreeCfgFromYAML(data []byte) (*BTTreeCfg, error) {
+	data, err := YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return LoadTreeCfgFromBytes(data)
+}
+
+// LoadProjectCfgFromYAML decodes a project config written in YAML.
+func LoadProjectCfgFromYAML(data []byte) (*BTProjectCfg, error) {
+	data, err := YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return LoadProjectCfgFromBytes(data)
+}
+
+// LoadTreeYAML loads a tree config from a YAML file.
+func LoadTreeYAML(path string) (*BTTreeCfg, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := LoadTreeCfgFromYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return tree, nil
+}
+
+// LoadProjectYAML loads a project config from a YAML file.
+func LoadProjectYAML(path string) (*BTProjectCfg, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	project, err := LoadProjectCfgFromYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return project, nil
+}
@@ -0,0 +1,82 @@
+package config_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/youngtrips/behavior3go/config"
+)
+
+const numberTree = `{
+	"id": "t", "title": "numbers", "root": "a",
+	"nodes": {
+		"a": {"id": "a", "name": "Wait", "category": "action",
+			"properties": {"big": 9007199254740993, "neg": -9007199254740993,
+				"ms": 1.5, "text": "42", "neg_text": "-42", "huge": 18446744073709551615}}
+	}
+}`
+
+func loadNode(t *testing.T) *BTNodeCfg {
+	t.Helper()
+	tree, err := LoadTreeCfgFromBytes([]byte(numberTree))
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := tree.Nodes["a"]
+	return &node
+}
+
+func TestNumbersDecodeAsFloat64ByDefault(t *testing.T) {
+	node := loadNode(t)
+	if _, ok := node.Properties["ms"].(float64); !ok {
+		t.Fatalf("ms decoded as %T", node.Properties["ms"])
+	}
+	if got := node.GetProperty("ms"); got != 1.5 {
+		t.Error("GetProperty:", got)
+	}
+	if got := node.GetPropertyAsInt64("text"); got != 42 {
+		t.Error("GetPropertyAsInt64 of a string:", got)
+	}
+	if got := node.GetPropertyAsInt64("neg_text"); got != -42 {
+		t.Error("GetPropertyAsInt64 of a negative string:", got)
+	}
+	if got := node.GetPropertyAsUint64("text"); got != 42 {
+		t.Error("GetPropertyAsUint64 of a string:", got)
+	}
+	if got := node.GetPropertyAsInt64("ms"); got != 1 {
+		t.Error("GetPropertyAsInt64 truncates:", got)
+	}
+}
+
+func TestUseNumberKeepsDigits(t *testing.T) {
+	SetUseNumber(true)
+	defer SetUseNumber(false)
+	node := loadNode(t)
+	if _, ok := node.Properties["big"].(json.Number); !ok {
+		t.Fatalf("big decoded as %T", node.Properties["big"])
+	}
+	if got := node.GetPropertyAsInt64("big"); got != 9007199254740993 {
+		t.Error("GetPropertyAsInt64:", got)
+	}
+	if got := node.GetPropertyAsInt64("neg"); got != -9007199254740993 {
+		t.Error("GetPropertyAsInt64 of a negative number:", got)
+	}
+	if got := node.GetPropertyAsUint64("huge"); got != 18446744073709551615 {
+		t.Error("GetPropertyAsUint64:", got)
+	}
+	if got := node.GetPropertyAsInt64("text"); got != 42 {
+		t.Error("GetPropertyAsInt64 of a string:", got)
+	}
+	if got := node.GetProperty("ms"); got != 1.5 {
+		t.Error("GetProperty:", got)
+	}
+	if got := NumberValue("7"); got != json.Number("7") {
+		t.Errorf("NumberValue: %#v", got)
+	}
+}
+
+func TestNumberValueFollowsUnmarshal(t *testing.T) {
+	if got := NumberValue("7"); got != 7.0 {
+		t.Errorf("NumberValue: %#v", got)
+	}
+}
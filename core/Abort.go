@@ -0,0 +1,128 @@
+package core
+
+import (
+	"strings"
+
+	b3 "github.com/youngtrips/behavior3go"
+)
+
+/**
+ * AbortMode tells when a branch of a priority composite is re-evaluated
+ * while a branch is RUNNING (observer aborts). It is read from the "abort"
+ * property of the branch: "none" (default), "self", "lowerPriority" or
+ * "both".
+**/
+type AbortMode int
+
+const (
+	// the branch is not re-evaluated
+	AbortNone AbortMode = iota
+	// while running, the branch is aborted once its guard fails
+	AbortSelf
+	// while a lower branch runs, it is aborted once this guard succeeds
+	AbortLowerPriority
+	AbortBoth
+)
+
+func ParseAbortMode(s string) AbortMode {
+	switch strings.ToLower(s) {
+	case "self":
+		return AbortSelf
+	case "lowerpriority", "lower_priority", "lower":
+		return AbortLowerPriority
+	case "both":
+		return AbortBoth
+	}
+	return AbortNone
+}
+
+func (this AbortMode) AbortsSelf() bool {
+	return this == AbortSelf || this == AbortBoth
+}
+
+func (this AbortMode) AbortsLowerPriority() bool {
+	return this == AbortLowerPriority || this == AbortBoth
+}
+
+// GetAbortMode returns the abort mode of the branch rooted at the node.
+func (this *BaseNode) GetAbortMode() AbortMode {
+	return this.abortMode
+}
+
+func (this *BaseNode) SetAbortMode(mode AbortMode) {
+	this.abortMode = mode
+}
+
+/**
+ * AbortGuard returns the guard of the branch rooted at node: node itself
+ * if it is a condition, else the first one found following first children
+ * (a Sequence starting with a condition). A condition under decorators
+ * (Inverter, Cooldown...) is guarded through them, so the outermost of
+ * those decorators is returned and evaluated as the guard. It returns nil
+ * if the branch has no guard, or if a decorator wraps more than a
+ * condition: its result is not one of a guard.
+**/
+func AbortGuard(node IBaseNode) IBaseNode {
+	for node != nil {
+		switch node.GetCategory() {
+		case b3.CONDITION:
+			return node
+		case b3.COMPOSITE:
+			comp := node.(IComposite)
+			if comp.GetChildCount() == 0 {
+				return nil
+			}
+			node = comp.GetChild(0)
+		case b3.DECORATOR:
+			child := node.(IDecorator).GetChild()
+			for child != nil && child.GetCategory() == b3.DECORATOR {
+				child = child.(IDecorator).GetChild()
+			}
+			if child == nil || child.GetCategory() != b3.CONDITION {
+				return nil
+			}
+			return node
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+/**
+ * GuardPasses executes the guard of the branch rooted at node and reports
+ * whether it succeeds, true if the branch has none. Its status is kept
+ * for the rest of the tick: when the branch is ticked next, the guard
+ * returns it again instead of being evaluated twice.
+**/
+func GuardPasses(tick *Tick, node IBaseNode) bool {
+	guard := AbortGuard(node)
+	if guard == nil {
+		return true
+	}
+	status := guard.Execute(tick)
+	if tick._guards == nil {
+		tick._guards = make(map[string]b3.Status)
+	}
+	tick._guards[guard.GetID()] = status
+	return status == b3.SUCCESS
+}
+
+// takeGuard returns and forgets the status kept by GuardPasses for the
+// node id.
+func (this *Tick) takeGuard(id string) (b3.Status, bool) {
+	status, ok := this._guards[id]
+	if ok {
+		delete(this._guards, id)
+	}
+	return status, ok
+}
+
+// AbortModeOf returns the abort mode of the branch rooted at node,
+// AbortNone if it does not embed a BaseNode.
+func AbortModeOf(node IBaseNode) AbortMode {
+	if base := toBaseNode(node); base != nil {
+		return base.abortMode
+	}
+	return AbortNone
+}
@@ -0,0 +1,193 @@
+package core
+
+import (
+	"sort"
+	"sync"
+
+	b3 "github.com/youngtrips/behavior3go"
+)
+
+type managedAgent struct {
+	id         string
+	target     interface{}
+	blackboard *Blackboard
+	phase      int
+	status     b3.Status
+}
+
+/**
+ * AgentManager runs one tree for many agents, each with its own
+ * blackboard, the usual server pattern:
+ *
+ *     manager := NewAgentManager(tree, 4, 8)
+ *     manager.Add("npc-1", npc)
+ *     for {
+ *         manager.Tick() // once per frame
+ *     }
+ *
+ * With a period above 1, each agent is ticked once every period frames
+ * and the agents are spread over the frames, so a frame only ticks a
+ * slice of the population. The agents of a frame are ticked by up to
+ * workers goroutines: the tree is stateless, but custom nodes must not
+ * keep agent state in their fields for that (see BehaviorTree.Clone).
+ *
+ * For shards with their own goroutines and command queues, see the
+ * agents package.
+ *
+ * @class AgentManager
+**/
+type AgentManager struct {
+	tree    *BehaviorTree
+	period  int
+	workers int
+
+	mutex  sync.Mutex
+	agents map[string]*managedAgent
+	frame  int
+	next   int
+}
+
+// NewAgentManager creates a manager ticking tree; period and workers
+// below 1 mean 1.
+func NewAgentManager(tree *BehaviorTree, period int, workers int) *AgentManager {
+	if period < 1 {
+		period = 1
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return &AgentManager{tree: tree, period: period, workers: workers, agents: make(map[string]*managedAgent)}
+}
+
+// Add adds an agent with a new blackboard and returns the blackboard.
+func (this *AgentManager) Add(id string, target interface{}) *Blackboard {
+	blackboard := NewBlackboard(nil)
+	this.AddWithBlackboard(id, target, blackboard)
+	return blackboard
+}
+
+// AddWithBlackboard adds an agent with its blackboard, e.g. a restored
+// one; an agent already added with id is replaced.
+func (this *AgentManager) AddWithBlackboard(id string, target interface{}, blackboard *Blackboard) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.agents[id] = &managedAgent{id: id, target: target, blackboard: blackboard, phase: this.next % this.period}
+	this.next++
+}
+
+// Remove removes an agent, aborting the tree (see BehaviorTree.Abort) so
+// its running nodes are closed. It returns false for an unknown id.
+func (this *AgentManager) Remove(id string) bool {
+	this.mutex.Lock()
+	agent, ok := this.agents[id]
+	delete(this.agents, id)
+	this.mutex.Unlock()
+	if ok {
+		this.tree.Abort(agent.target, agent.blackboard)
+	}
+	return ok
+}
+
+// Blackboard returns the blackboard of an agent, nil for an unknown id.
+func (this *AgentManager) Blackboard(id string) *Blackboard {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if agent, ok := this.agents[id]; ok {
+		return agent.blackboard
+	}
+	return nil
+}
+
+// Status returns the status of the last tick of an agent.
+func (this *AgentManager) Status(id string) (b3.Status, bool) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if agent, ok := this.agents[id]; ok {
+		return agent.status, true
+	}
+	return 0, false
+}
+
+func (this *AgentManager) Len() int {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return len(this.agents)
+}
+
+// IDs returns the ids of the agents, sorted.
+func (this *AgentManager) IDs() []string {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	ids := make([]string, 0, len(this.agents))
+	for id := range this.agents {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+/**
+ * Tick runs one frame: the agents due in this frame are ticked and the
+ * number of agents ticked is returned. Do not add or remove agents from
+ * the nodes while it runs.
+**/
+func (this *AgentManager) Tick() int {
+	this.mutex.Lock()
+	frame := this.frame % this.period
+	this.frame++
+	var due []*managedAgent
+	for _, agent := range this.agents {
+		if agent.phase == frame {
+			due = append(due, agent)
+		}
+	}
+	this.mutex.Unlock()
+
+	this.tickAgents(due)
+	return len(due)
+}
+
+// TickAll ticks every agent once, whatever the period, and returns their
+// number.
+func (this *AgentManager) TickAll() int {
+	this.mutex.Lock()
+	all := make([]*managedAgent, 0, len(this.agents))
+	for _, agent := range this.agents {
+		all = append(all, agent)
+	}
+	this.mutex.Unlock()
+
+	this.tickAgents(all)
+	return len(all)
+}
+
+func (this *AgentManager) tickAgents(agents []*managedAgent) {
+	tick := func(agent *managedAgent) {
+		status := this.tree.Tick(agent.target, agent.blackboard)
+		this.mutex.Lock()
+		agent.status = status
+		this.mutex.Unlock()
+	}
+	if this.workers == 1 || len(agents) < 2 {
+		for _, agent := range agents {
+			tick(agent)
+		}
+		return
+	}
+	var wg sync.WaitGroup
+	queue := make(chan *managedAgent)
+	for i := 0; i < this.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for agent := range queue {
+				tick(agent)
+			}
+		}()
+	}
+	for _, agent := range agents {
+		queue <- agent
+	}
+	close(queue)
+	wg.Wait()
+}
@@ -0,0 +1,134 @@
+package core
+
+import (
+	"sort"
+
+	b3 "github.com/youngtrips/behavior3go"
+)
+
+// EntryCondition tells whether a tree of an Arbiter wants to run.
+type EntryCondition func(target interface{}, blackboard *Blackboard) bool
+
+// WhenKey is an EntryCondition true while the global key holds true.
+func WhenKey(key string) EntryCondition {
+	return func(target interface{}, blackboard *Blackboard) bool {
+		v, _ := blackboard.GetMem(key).(bool)
+		return v
+	}
+}
+
+// ArbiterEntry is one tree of an Arbiter.
+type ArbiterEntry struct {
+	Name     string
+	Tree     *BehaviorTree
+	Priority int
+	// nil means always willing to run, for the default (lowest) tree
+	Enter EntryCondition
+}
+
+/**
+ * Arbiter runs one of several trees of an agent, chosen by priority: the
+ * running tree is interrupted as soon as the entry condition of a tree of
+ * higher priority holds ("combat overrides patrol"). A tree that finishes
+ * (does not return RUNNING) gives way to the best candidate on the next
+ * tick.
+ *
+ *     arbiter := NewArbiter("ai")
+ *     arbiter.Add(ArbiterEntry{Name: "patrol", Tree: patrol})
+ *     arbiter.Add(ArbiterEntry{Name: "combat", Tree: combat, Priority: 10, Enter: WhenKey("enemyVisible")})
+ *     status := arbiter.Tick(npc, blackboard)
+ *
+ * The interrupted tree is halted: its open nodes are closed, deepest
+ * first. The state of the arbiter is kept in the global memory of the
+ * blackboard, under "<name>.current" (running tree), "<name>.previous"
+ * and "<name>.switches" (number of transitions), so one arbiter serves
+ * many agents.
+ *
+ * @class Arbiter
+**/
+type Arbiter struct {
+	name     string
+	entries  []ArbiterEntry
+	onSwitch func(target interface{}, blackboard *Blackboard, from string, to string)
+}
+
+func NewArbiter(name string) *Arbiter {
+	return &Arbiter{name: name}
+}
+
+// Add registers a tree; entries are kept sorted by decreasing priority.
+func (this *Arbiter) Add(entry ArbiterEntry) {
+	this.entries = append(this.entries, entry)
+	sort.SliceStable(this.entries, func(i, j int) bool {
+		return this.entries[i].Priority > this.entries[j].Priority
+	})
+}
+
+// OnSwitch sets a callback invoked on every transition, after the
+// interrupted tree was halted; from is empty on the first one.
+func (this *Arbiter) OnSwitch(f func(target interface{}, blackboard *Blackboard, from string, to string)) {
+	this.onSwitch = f
+}
+
+// Current returns the name of the tree running for blackboard.
+func (this *Arbiter) Current(blackboard *Blackboard) string {
+	name, _ := blackboard.GetMem(this.name + ".current").(string)
+	return name
+}
+
+func (this *Arbiter) entry(name string) *ArbiterEntry {
+	for i := range this.entries {
+		if this.entries[i].Name == name {
+			return &this.entries[i]
+		}
+	}
+	return nil
+}
+
+// choose returns the entry to tick: the best willing entry, unless the
+// current one is still running and not outranked.
+func (this *Arbiter) choose(target interface{}, blackboard *Blackboard, current *ArbiterEntry) *ArbiterEntry {
+	running, _ := blackboard.GetMem(this.name + ".running").(bool)
+	for i := range this.entries {
+		e := &this.entries[i]
+		if current != nil && running && e.Priority <= current.Priority {
+			return current
+		}
+		if e.Enter == nil || e.Enter(target, blackboard) {
+			return e
+		}
+	}
+	if current != nil && running {
+		return current
+	}
+	return nil
+}
+
+/**
+ * Tick runs the chosen tree, switching trees if needed, and returns its
+ * status; FAILURE if no tree wants to run.
+**/
+func (this *Arbiter) Tick(target interface{}, blackboard *Blackboard) b3.Status {
+	current := this.entry(this.Current(blackboard))
+	next := this.choose(target, blackboard, current)
+	if next == nil {
+		return b3.FAILURE
+	}
+	if next != current {
+		from := ""
+		if current != nil {
+			current.Tree.halt(target, blackboard)
+			from = current.Name
+		}
+		switches, _ := blackboard.GetMem(this.name + ".switches").(int)
+		blackboard.SetMem(this.name+".previous", from)
+		blackboard.SetMem(this.name+".current", next.Name)
+		blackboard.SetMem(this.name+".switches", switches+1)
+		if this.onSwitch != nil {
+			this.onSwitch(target, blackboard, from, next.Name)
+		}
+	}
+	status := next.Tree.Tick(target, blackboard)
+	blackboard.SetMem(this.name+".running", status == b3.RUNNING)
+	return status
+}
@@ -21,6 +21,7 @@ type IBaseNode interface {
 	Ctor()
 	Initialize(params *BTNodeCfg)
 	GetCategory() string
+	GetID() string
 	Execute(tick *Tick) b3.Status
 	GetName() string
 	GetTitle() string
@@ -117,6 +118,12 @@ type BaseNode struct {
 	 * @readonly
 	**/
 	properties map[string]interface{}
+
+	// see AbortMode, from the "abort" property
+	abortMode AbortMode
+
+	// the config the node was initialized with, see GetConfig
+	config *BTNodeCfg
 }
 
 func (this *BaseNode) Ctor() {
@@ -155,7 +162,23 @@ func (this *BaseNode) Initialize(params *BTNodeCfg) {
 	this.title = params.Title             //|| node.title;
 	this.description = params.Description // || node.description;
 	this.properties = params.Properties   //|| node.properties;
+	this.config = params
+	if mode, ok := params.Properties["abort"].(string); ok {
+		this.abortMode = ParseAbortMode(mode)
+	}
+
+}
 
+// baseNode gives core access to the embedded BaseNode of any node, which
+// is the value recorded in the open node lists.
+func (this *BaseNode) baseNode() *BaseNode {
+	return this
+}
+
+// GetConfig returns the config the node was initialized with, for
+// parents reading per child properties (e.g. the "case" of Switch).
+func (this *BaseNode) GetConfig() *BTNodeCfg {
+	return this.config
 }
 
 func (this *BaseNode) GetCategory() string {
@@ -200,6 +223,11 @@ func (this *BaseNode) GetProperty(name string) (string, bool) {
 **/
 func (this *BaseNode) _execute(tick *Tick) b3.Status {
 	//fmt.Println("_execute :", this.title)
+	// a guard already evaluated this tick, see GuardPasses
+	if status, ok := tick.takeGuard(this.id); ok {
+		return status
+	}
+
 	// ENTER
 	this._enter(tick)
 
@@ -258,8 +286,9 @@ func (this *BaseNode) _open(tick *Tick) {
 **/
 func (this *BaseNode) _tick(tick *Tick) b3.Status {
 	//fmt.Println("_tick :", this.title)
-	tick._tickNode(this)
-	return this.OnTick(tick)
+	var status = this.OnTick(tick)
+	tick._tickNode(this, status)
+	return status
 }
 
 /**
@@ -2,6 +2,8 @@ package core
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	b3 "github.com/youngtrips/behavior3go"
 	"github.com/youngtrips/behavior3go/config"
@@ -112,7 +114,26 @@ type BehaviorTree struct {
 	**/
 	debug interface{}
 
+	debugDraw IDebugDraw
+
+	// see AddListener
+	listeners []Listener
+
+	// see SetProfileSink
+	profileSink ProfileSink
+
+	// see SetLogger
+	logger Logger
+
 	dumpInfo *config.BTTreeCfg
+	// node maps of Load, see Clone
+	maps, extMaps *b3.RegisterStructMaps
+
+	// checksum of the structure, see Version
+	version string
+
+	// declared blackboard keys, see Schema
+	schema *Schema
 }
 
 func NewBeTree() *BehaviorTree {
@@ -139,6 +160,12 @@ func (this *BehaviorTree) GetID() string {
 	return this.id
 }
 
+// SetID replaces the random id of the tree, which scopes its memory in
+// the blackboards; set it before the first tick.
+func (this *BehaviorTree) SetID(id string) {
+	this.id = id
+}
+
 func (this *BehaviorTree) GetTitile() string {
 	return this.title
 }
@@ -151,6 +178,41 @@ func (this *BehaviorTree) GetRoot() IBaseNode {
 	return this.root
 }
 
+// OpenNode describes a node left open (RUNNING) on a blackboard.
+type OpenNode struct {
+	ID    string
+	Name  string
+	Title string
+	// Title with its placeholders resolved, see RenderTitle
+	Display string
+}
+
+/**
+ * OpenNodes returns the nodes this tree left open on blackboard after the
+ * last tick, outermost first (so the last one is what the agent is doing
+ * right now). Nodes of subtrees are included. The result is a copy and
+ * the blackboard is not modified.
+ *
+ * @method OpenNodes
+ * @param {b3.Blackboard} blackboard The blackboard of the agent.
+**/
+func (this *BehaviorTree) OpenNodes(blackboard *Blackboard) []OpenNode {
+	treeMem, ok := blackboard._treeMemory[this.id]
+	if !ok {
+		return nil
+	}
+	nodes := make([]OpenNode, 0, len(treeMem._treeData.OpenNodes))
+	for _, node := range treeMem._treeData.OpenNodes {
+		nodes = append(nodes, OpenNode{
+			ID:      node.GetID(),
+			Name:    node.GetName(),
+			Title:   node.GetTitle(),
+			Display: RenderTitle(node.GetTitle(), blackboard, this.id, node.GetID()),
+		})
+	}
+	return nodes
+}
+
 /**
  * This method loads a Behavior Tree from a data structure, populating this
  * object with the provided data. Notice that, the data structure must
@@ -184,11 +246,29 @@ func (this *BehaviorTree) Load(data *config.BTTreeCfg, maps *b3.RegisterStructMa
 	this.description = data.Description // || this.description;
 	this.properties = data.Properties   // || this.properties;
 	this.dumpInfo = data
+	this.maps, this.extMaps = maps, extMaps
+	this.schema = nil
+	if decl, ok := data.Properties["schema"]; ok {
+		schema, err := ParseSchema(decl)
+		if err != nil {
+			panic(&LoadError{TreeID: data.ID, Path: data.Title, Reason: err.Error()})
+		}
+		this.schema = schema
+	}
+	// the flat decorator lists of the nodes, see config.ExpandDecorators
+	expanded, err := config.ExpandDecorators(data)
+	if err != nil {
+		panic(&LoadError{TreeID: data.ID, Path: data.Title, Reason: err.Error()})
+	}
+	data = expanded
 	nodes := make(map[string]IBaseNode)
+	paths := NodePaths(data)
 
 	// Create the node list (without connection between them)
 
 	for id, s := range data.Nodes {
+		// one variable per node: GetConfig keeps the pointer
+		s := s
 		spec := &s
 		var node IBaseNode
 
@@ -211,13 +291,11 @@ func (this *BehaviorTree) Load(data *config.BTTreeCfg, maps *b3.RegisterStructMa
 
 		if node == nil {
 			// Invalid node name
-			panic("BehaviorTree.load: Invalid node name:" + spec.Name + ",title:" + spec.Title)
+			panic(this.loadError(paths, id, "invalid node name "+spec.Name))
 
 		}
 
-		node.Ctor()
-		node.Initialize(spec)
-		node.SetBaseNodeWorker(node.(IBaseWorker))
+		this.initNode(node, spec, paths)
 		nodes[id] = node
 	}
 
@@ -228,16 +306,53 @@ func (this *BehaviorTree) Load(data *config.BTTreeCfg, maps *b3.RegisterStructMa
 		if node.GetCategory() == b3.COMPOSITE && spec.Children != nil {
 			for i := 0; i < len(spec.Children); i++ {
 				var cid = spec.Children[i]
+				if nodes[cid] == nil {
+					panic(this.loadError(paths, id, "missing child "+cid))
+				}
 				comp := node.(IComposite)
 				comp.AddChild(nodes[cid])
 			}
 		} else if node.GetCategory() == b3.DECORATOR && len(spec.Child) > 0 {
+			if nodes[spec.Child] == nil {
+				panic(this.loadError(paths, id, "missing child "+spec.Child))
+			}
 			dec := node.(IDecorator)
 			dec.SetChild(nodes[spec.Child])
 		}
 	}
 
+	for id, node := range nodes {
+		if v, ok := node.(IValidator); ok {
+			if err := v.Validate(); err != nil {
+				panic(this.loadError(paths, id, err.Error()))
+			}
+		}
+	}
+
 	this.root = nodes[data.Root]
+	this.version = this.checksum()
+}
+
+// initNode initializes node, turning a panic (e.g. a missing property)
+// into a LoadError locating the node.
+func (this *BehaviorTree) initNode(node IBaseNode, spec *config.BTNodeCfg, paths map[string]string) {
+	defer func() {
+		if r := recover(); r != nil {
+			panic(this.loadError(paths, spec.Id, fmt.Sprint(r)))
+		}
+	}()
+	node.Ctor()
+	if err := BindProperties(node, spec); err != nil {
+		panic(err)
+	}
+	node.Initialize(spec)
+	node.SetBaseNodeWorker(node.(IBaseWorker))
+	// an action declared as a condition in the editor is a condition
+	if spec.Category == b3.CONDITION && node.GetCategory() == b3.ACTION {
+		if base := toBaseNode(node); base != nil {
+			base.category = b3.CONDITION
+		}
+	}
 }
 
 /**
@@ -253,6 +368,11 @@ func (this *BehaviorTree) dump() *config.BTTreeCfg {
 	return this.dumpInfo
 }
 
+// Dump returns the config the tree was loaded from, nil if built by hand.
+func (this *BehaviorTree) Dump() *config.BTTreeCfg {
+	return this.dump()
+}
+
 /**
  * Propagates the tick signal through the tree, starting from the root.
  *
@@ -276,6 +396,29 @@ func (this *BehaviorTree) dump() *config.BTTreeCfg {
  * @return {Constant} The tick signal state.
 **/
 func (this *BehaviorTree) Tick(target interface{}, blackboard *Blackboard) b3.Status {
+	status, _ := this.tick(target, blackboard, 0)
+	return status
+}
+
+/**
+ * TickDelta is Tick for game loops passing the time elapsed since the
+ * previous frame: tick.DeltaTime is dt and tick.SimTime the sum of the
+ * deltas passed with the blackboard so far, dt included. Nodes like
+ * WaitDelta count this simulation time instead of reading a clock, which
+ * makes fixed timestep loops and replays reproducible.
+ *
+ * @method TickDelta
+ * @param {Object} target A target object.
+ * @param {Blackboard} blackboard An instance of blackboard object.
+ * @param {Duration} dt The time elapsed since the previous tick.
+ * @return {Constant} The tick signal state.
+**/
+func (this *BehaviorTree) TickDelta(target interface{}, blackboard *Blackboard, dt time.Duration) b3.Status {
+	status, _ := this.tick(target, blackboard, dt)
+	return status
+}
+
+func (this *BehaviorTree) tick(target interface{}, blackboard *Blackboard, dt time.Duration) (b3.Status, error) {
 	if blackboard == nil {
 		panic("The blackboard parameter is obligatory and must be an instance of b3.Blackboard")
 	}
@@ -286,6 +429,14 @@ func (this *BehaviorTree) Tick(target interface{}, blackboard *Blackboard) b3.St
 	tick.target = target
 	tick.Blackboard = blackboard
 	tick.tree = this
+	blackboard._tickCount++
+	blackboard._simTime += dt
+	tick.DeltaTime = dt
+	tick.SimTime = blackboard._simTime
+	tick.listeners = this.listeners
+	for _, l := range tick.listeners {
+		l.OnTreeTickStart(tick)
+	}
 
 	/* TICK NODE */
 	var state = this.root._execute(tick)
@@ -306,44 +457,75 @@ func (this *BehaviorTree) Tick(target interface{}, blackboard *Blackboard) b3.St
 
 	// close the nodes
 	for i := len(lastOpenNodes) - 1; i >= start; i-- {
-		lastOpenNodes[i]._close(tick)
+		// skip the nodes already halted during the tick
+		if blackboard.GetBool("isOpen", this.id, lastOpenNodes[i].GetID()) {
+			lastOpenNodes[i]._close(tick)
+		}
 	}
 
 	/* POPULATE BLACKBOARD */
 	blackboard._getTreeData(this.id).OpenNodes = currOpenNodes
 	blackboard.SetTree("nodeCount", tick._nodeCount, this.id)
+	tick.flushDebugDraw()
+	for _, l := range tick.listeners {
+		l.OnTreeTickEnd(tick, state)
+	}
 
-	return state
+	return state, tick.Err()
 }
 
 func (this *BehaviorTree) Print() {
-	printNode(this.root, 0)
+	this.printTree(nil)
 }
 
-func printNode(root IBaseNode, blk int) {
+// PrintWith prints the tree with the titles rendered from blackboard, see
+// RenderTitle.
+func (this *BehaviorTree) PrintWith(blackboard *Blackboard) {
+	this.printTree(func(node IBaseNode) string {
+		return RenderTitle(node.GetTitle(), blackboard, this.id, node.GetID())
+	})
+}
+
+// printTree sends the lines of the tree to the logger, at the info level.
+func (this *BehaviorTree) printTree(title func(IBaseNode) string) {
+	var out strings.Builder
+	printNode(&out, this.root, 0, title)
+	logger := this.Logger()
+	for _, line := range strings.Split(out.String(), "\n") {
+		if line != "" {
+			logger.Info(line)
+		}
+	}
+}
+
+func printNode(out *strings.Builder, root IBaseNode, blk int, title func(IBaseNode) string) {
 
 	//fmt.Println("new node:", root.Name, " children:", len(root.Children), " child:", root.Child)
 	for i := 0; i < blk; i++ {
-		fmt.Print(" ") //缩进
+		out.WriteString(" ") //缩进
 	}
 
 	//fmt.Println("|—<", root.Name, ">") //打印"|—<id>"形式
-	fmt.Print("|—", root.GetTitle())
+	if title != nil {
+		out.WriteString("|—" + title(root))
+	} else {
+		out.WriteString("|—" + root.GetTitle())
+	}
 
 	if root.GetCategory() == b3.DECORATOR {
 		dec := root.(IDecorator)
 		if dec.GetChild() != nil {
 			//fmt.Print("=>")
-			printNode(dec.GetChild(), blk+3)
+			printNode(out, dec.GetChild(), blk+3, title)
 		}
 	}
 
-	fmt.Println("")
+	out.WriteString("\n")
 	if root.GetCategory() == b3.COMPOSITE {
 		comp := root.(IComposite)
 		if comp.GetChildCount() > 0 {
 			for i := 0; i < comp.GetChildCount(); i++ {
-				printNode(comp.GetChild(i), blk+3)
+				printNode(out, comp.GetChild(i), blk+3, title)
 			}
 		}
 	}
@@ -0,0 +1,228 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/youngtrips/behavior3go/config"
+)
+
+// bindField is a field of a node bound to a property, see BindProperties.
+type bindField struct {
+	index    []int
+	property string
+	required bool
+	// default value, as written in the tag
+	def    string
+	hasDef bool
+}
+
+// the fields to bind by node type, []bindField or error
+var bindPlans sync.Map
+
+/**
+ * BindProperties sets the fields of node tagged `b3:"<property>"` from
+ * the properties of cfg, converting the values to the field types: the
+ * strings, bools, integers, floats (from any number or numeric string),
+ * time.Duration (milliseconds, or a string as "1.5s"), slices and maps of
+ * those, and interface{}. The options follow the property name:
+ *
+ *     type Patrol struct {
+ *         Action
+ *         Speed   float64       `b3:"speed,required"`
+ *         Pause   time.Duration `b3:"pause,default=500"`
+ *         Targets []string      `b3:"targets"`
+ *     }
+ *
+ * A missing property keeps the field as is, sets its default, or is an
+ * error when required. The fields of embedded structs are bound as well.
+ *
+ * The loader binds every node before Initialize, so nodes need not copy
+ * their properties by hand; a binding error fails the load.
+**/
+func BindProperties(node interface{}, cfg *BTNodeCfg) error {
+	v := reflect.ValueOf(node)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind: %T is not a pointer to struct", node)
+	}
+	v = v.Elem()
+	fields, err := bindPlan(v.Type())
+	if err != nil {
+		return err
+	}
+	for i := range fields {
+		f := &fields[i]
+		raw, ok := cfg.Properties[f.property]
+		if !ok {
+			switch {
+			case f.required:
+				return fmt.Errorf("property %s is required", f.property)
+			case !f.hasDef:
+				continue
+			}
+			raw = f.def
+		}
+		if err := setField(v.FieldByIndex(f.index), raw); err != nil {
+			return fmt.Errorf("property %s: %v", f.property, err)
+		}
+	}
+	return nil
+}
+
+// bindPlan returns the tagged fields of t, cached.
+func bindPlan(t reflect.Type) ([]bindField, error) {
+	if plan, ok := bindPlans.Load(t); ok {
+		if err, ok := plan.(error); ok {
+			return nil, err
+		}
+		return plan.([]bindField), nil
+	}
+	var fields []bindField
+	err := collectFields(t, nil, &fields)
+	if err != nil {
+		bindPlans.Store(t, err)
+		return nil, err
+	}
+	bindPlans.Store(t, fields)
+	return fields, nil
+}
+
+func collectFields(t reflect.Type, index []int, fields *[]bindField) error {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		fieldIndex := append(append([]int(nil), index...), i)
+		tag, ok := sf.Tag.Lookup("b3")
+		if !ok {
+			if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+				if err := collectFields(sf.Type, fieldIndex, fields); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if sf.PkgPath != "" {
+			return fmt.Errorf("bind: %s.%s is unexported", t.Name(), sf.Name)
+		}
+		parts := strings.Split(tag, ",")
+		f := bindField{index: fieldIndex, property: parts[0]}
+		if f.property == "" {
+			f.property = sf.Name
+		}
+		for _, opt := range parts[1:] {
+			switch {
+			case opt == "required":
+				f.required = true
+			case strings.HasPrefix(opt, "default="):
+				f.def, f.hasDef = strings.TrimPrefix(opt, "default="), true
+			default:
+				return fmt.Errorf("bind: %s.%s: unknown option %q", t.Name(), sf.Name, opt)
+			}
+		}
+		*fields = append(*fields, f)
+	}
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setField sets v to raw converted to its type.
+func setField(v reflect.Value, raw interface{}) error {
+	fail := func() error {
+		return fmt.Errorf("cannot use %v (%T) as %s", raw, raw, v.Type())
+	}
+	if v.Type() == durationType {
+		if s, ok := raw.(string); ok {
+			if d, err := time.ParseDuration(s); err == nil {
+				v.SetInt(int64(d))
+				return nil
+			}
+		}
+		ms, ok := CoerceFloat64(raw)
+		if !ok {
+			return fail()
+		}
+		v.SetInt(int64(ms * float64(time.Millisecond)))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		s, ok := raw.(string)
+		if !ok {
+			return fail()
+		}
+		v.SetString(s)
+	case reflect.Bool:
+		switch b := raw.(type) {
+		case bool:
+			v.SetBool(b)
+		case string:
+			parsed, err := strconv.ParseBool(b)
+			if err != nil {
+				return fail()
+			}
+			v.SetBool(parsed)
+		default:
+			return fail()
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := CoerceInt64(raw)
+		if !ok || v.OverflowInt(i) {
+			return fail()
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, ok := CoerceUint64(raw)
+		if !ok || v.OverflowUint(u) {
+			return fail()
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, ok := CoerceFloat64(raw)
+		if !ok {
+			return fail()
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		list, ok := raw.([]interface{})
+		if !ok {
+			return fail()
+		}
+		s := reflect.MakeSlice(v.Type(), len(list), len(list))
+		for i, item := range list {
+			if err := setField(s.Index(i), item); err != nil {
+				return fmt.Errorf("[%d]: %v", i, err)
+			}
+		}
+		v.Set(s)
+	case reflect.Map:
+		m, ok := raw.(map[string]interface{})
+		if !ok || v.Type().Key().Kind() != reflect.String {
+			return fail()
+		}
+		out := reflect.MakeMapWithSize(v.Type(), len(m))
+		for k, item := range m {
+			e := reflect.New(v.Type().Elem()).Elem()
+			if err := setField(e, item); err != nil {
+				return fmt.Errorf("%s: %v", k, err)
+			}
+			out.SetMapIndex(reflect.ValueOf(k).Convert(v.Type().Key()), e)
+		}
+		v.Set(out)
+	case reflect.Interface:
+		if raw == nil {
+			v.Set(reflect.Zero(v.Type()))
+		} else if reflect.TypeOf(raw).Implements(v.Type()) {
+			v.Set(reflect.ValueOf(raw))
+		} else {
+			return fail()
+		}
+	default:
+		return fail()
+	}
+	return nil
+}
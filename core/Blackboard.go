@@ -2,7 +2,9 @@ package core
 
 import (
 	"fmt"
+	"math/rand"
 	"reflect"
+	"time"
 )
 
 /**
@@ -49,10 +51,12 @@ type TreeData struct {
 	OpenNodes      []IBaseNode
 	TraversalDepth int
 	TraversalCycle int
+	// primitives of the open nodes, see DebugDraw
+	DebugDraws map[string][]DrawPrimitive
 }
 
 func NewTreeData() *TreeData {
-	return &TreeData{NewMemory(), make([]IBaseNode, 0), 0, 0}
+	return &TreeData{NewMemory(), make([]IBaseNode, 0), 0, 0, nil}
 }
 
 //------------------------Memory-------------------------
@@ -96,6 +100,36 @@ type Blackboard struct {
 	_storage    Storage
 	_baseMemory *Memory
 	_treeMemory map[string]*TreeMemory
+
+	// copy mutable values on write, see SetDeepCopy
+	_deepCopy     bool
+	_deepCopyKeys map[string]bool
+
+	_cooldowns *Cooldowns
+
+	// see SetClock and SetSeed
+	_clock Clock
+	_rand  *rand.Rand
+
+	// see Watch
+	_watchers map[watchKey][]*watcher
+	// cancel funcs by node, see AddNodeWatch
+	_nodeWatches map[watchKey][]func()
+
+	// see SetChangeSink
+	_sink      ChangeSink
+	_tickCount uint64
+	_simTime   time.Duration
+
+	// see AddSchema
+	_schema        *Schema
+	_schemaHandler func(err *SchemaError)
+
+	// keys read from a LazyStorage
+	_fetched map[watchKey]bool
+
+	// see Events
+	_events *EventBus
 }
 
 func NewBlackboard(storage Storage) *Blackboard {
@@ -109,15 +143,12 @@ func NewBlackboard(storage Storage) *Blackboard {
 func (this *Blackboard) Initialize() {
 	this._baseMemory = NewMemory()
 	this._treeMemory = make(map[string]*TreeMemory)
-	if this._storage != nil {
+	this._fetched = nil
+	if _, lazy := this._storage.(LazyStorage); this._storage != nil && !lazy {
+		// filled directly: writing the entries back to the storage they
+		// come from would store and broadcast every one of them again
 		this._storage.Foreach(func(key string, value interface{}, treeScope string, nodeScope string) {
-			if treeScope != "" && nodeScope != "" {
-				this.Set(key, value, treeScope, nodeScope)
-			} else if treeScope != "" {
-				this.SetTree(key, value, treeScope)
-			} else {
-				this.SetMem(key, value)
-			}
+			this._getMemory(treeScope, nodeScope).Set(key, value)
 		})
 	}
 }
@@ -202,35 +233,151 @@ func (this *Blackboard) _getMemory(treeScope, nodeScope string) *Memory {
  * @param {String} nodeScope The node id if accessing the node memory.
 **/
 func (this *Blackboard) Set(key string, value interface{}, treeScope, nodeScope string) {
+	if this._schema != nil && treeScope == "" {
+		this.checkSchema(key, value)
+	}
+	value = this.copyValue(key, value)
 	var memory = this._getMemory(treeScope, nodeScope)
+	old := memory.Get(key)
 	memory.Set(key, value)
 	if this._storage != nil {
 		this._storage.Set(key, value, treeScope, nodeScope)
 	}
+	if treeScope == "" {
+		nodeScope = ""
+	}
+	this.notify(key, treeScope, nodeScope, old, value)
 }
 
 func (this *Blackboard) SetMem(key string, value interface{}) {
+	if this._schema != nil {
+		this.checkSchema(key, value)
+	}
+	value = this.copyValue(key, value)
 	var memory = this._getMemory("", "")
+	old := memory.Get(key)
 	memory.Set(key, value)
 	if this._storage != nil {
 		this._storage.Set(key, value, "", "")
 	}
+	this.notify(key, "", "", old, value)
 }
 
 func (this *Blackboard) Remove(key string) {
 	var memory = this._getMemory("", "")
+	old := memory.Get(key)
 	memory.Remove(key)
 	if this._storage != nil {
 		this._storage.Remove(key, "", "")
 	}
+	this.notify(key, "", "", old, nil)
+}
+
+// RemoveKey removes key from the memory of the given scope, see Set; it is
+// Remove for an empty treeScope.
+func (this *Blackboard) RemoveKey(key, treeScope, nodeScope string) {
+	if treeScope == "" {
+		nodeScope = ""
+	}
+	var memory = this._getMemory(treeScope, nodeScope)
+	old := memory.Get(key)
+	memory.Remove(key)
+	if this._storage != nil {
+		this._storage.Remove(key, treeScope, nodeScope)
+	}
+	this.notify(key, treeScope, nodeScope, old, nil)
+}
+
+/**
+ * RemoveTreeScope frees the memory of a tree and of its nodes, e.g. when
+ * the tree is unloaded or the agent stops running it. The keys are
+ * removed from the storage and reported to the watchers and change sink
+ * like removals.
+ *
+ * @method RemoveTreeScope
+ * @param {String} treeScope The tree id.
+**/
+func (this *Blackboard) RemoveTreeScope(treeScope string) {
+	this.unwatchTree(treeScope)
+	treeMem, ok := this._treeMemory[treeScope]
+	if !ok {
+		return
+	}
+	delete(this._treeMemory, treeScope)
+	for nodeScope, memory := range treeMem._nodeMemory {
+		this.removeScope(memory, treeScope, nodeScope)
+	}
+	this.removeScope(treeMem.Memory, treeScope, "")
+}
+
+// RemoveNodeScope frees the memory of a node, see RemoveTreeScope.
+func (this *Blackboard) RemoveNodeScope(treeScope, nodeScope string) {
+	this.UnwatchNode(treeScope, nodeScope)
+	treeMem, ok := this._treeMemory[treeScope]
+	if !ok {
+		return
+	}
+	memory, ok := treeMem._nodeMemory[nodeScope]
+	if !ok {
+		return
+	}
+	delete(treeMem._nodeMemory, nodeScope)
+	this.removeScope(memory, treeScope, nodeScope)
 }
+
+func (this *Blackboard) removeScope(memory *Memory, treeScope, nodeScope string) {
+	for key, old := range memory._memory {
+		if this._storage != nil {
+			this._storage.Remove(key, treeScope, nodeScope)
+		}
+		delete(this._fetched, watchKey{key, treeScope, nodeScope})
+		this.notify(key, treeScope, nodeScope, old, nil)
+	}
+}
+
 func (this *Blackboard) SetTree(key string, value interface{}, treeScope string) {
+	value = this.copyValue(key, value)
 	var memory = this._getMemory(treeScope, "")
+	old := memory.Get(key)
 	memory.Set(key, value)
 
 	if this._storage != nil {
 		this._storage.Set(key, value, treeScope, "")
 	}
+	this.notify(key, treeScope, "", old, value)
+}
+
+/**
+ * SetDeepCopy makes every write store a deep copy of the value (see
+ * DeepCopy), so two nodes writing the same slice or map do not share it
+ * through the blackboard. Reads still return the stored value itself.
+**/
+func (this *Blackboard) SetDeepCopy(enable bool) {
+	this._deepCopy = enable
+}
+
+// SetDeepCopyKey enables or disables deep copy for key, in every scope,
+// whatever the mode set by SetDeepCopy.
+func (this *Blackboard) SetDeepCopyKey(key string, enable bool) {
+	if this._deepCopyKeys == nil {
+		this._deepCopyKeys = make(map[string]bool)
+	}
+	this._deepCopyKeys[key] = enable
+}
+
+func (this *Blackboard) copyValue(key string, value interface{}) interface{} {
+	enable, ok := this._deepCopyKeys[key]
+	if !ok {
+		enable = this._deepCopy
+	}
+	if !enable {
+		return value
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Slice, reflect.Map, reflect.Ptr, reflect.Struct, reflect.Array, reflect.Interface:
+		return DeepCopy(value)
+	}
+	return value
 }
 
 func (this *Blackboard) _getTreeData(treeScope string) *TreeData {
@@ -255,11 +402,16 @@ func (this *Blackboard) _getTreeData(treeScope string) *TreeData {
  * @return {Object} The value stored or undefined.
 **/
 func (this *Blackboard) Get(key, treeScope, nodeScope string) interface{} {
+	if treeScope == "" {
+		nodeScope = ""
+	}
 	memory := this._getMemory(treeScope, nodeScope)
+	this.fetch(memory, key, treeScope, nodeScope)
 	return memory.Get(key)
 }
 func (this *Blackboard) GetMem(key string) interface{} {
 	memory := this._getMemory("", "")
+	this.fetch(memory, key, "", "")
 	return memory.Get(key)
 }
 func (this *Blackboard) GetFloat64(key, treeScope, nodeScope string) float64 {
@@ -0,0 +1,47 @@
+package core
+
+// GetOr returns the value of key, def if it is not set (or nil).
+func (this *Blackboard) GetOr(key string, def interface{}, treeScope, nodeScope string) interface{} {
+	if v := this.Get(key, treeScope, nodeScope); v != nil {
+		return v
+	}
+	return def
+}
+
+// The typed variants of GetOr return def as well when the value has
+// another type, they never panic.
+
+func (this *Blackboard) GetIntOr(key string, def int, treeScope, nodeScope string) int {
+	if v, ok := this.Get(key, treeScope, nodeScope).(int); ok {
+		return v
+	}
+	return def
+}
+
+func (this *Blackboard) GetInt64Or(key string, def int64, treeScope, nodeScope string) int64 {
+	if v, ok := this.Get(key, treeScope, nodeScope).(int64); ok {
+		return v
+	}
+	return def
+}
+
+func (this *Blackboard) GetFloat64Or(key string, def float64, treeScope, nodeScope string) float64 {
+	if v, ok := this.Get(key, treeScope, nodeScope).(float64); ok {
+		return v
+	}
+	return def
+}
+
+func (this *Blackboard) GetBoolOr(key string, def bool, treeScope, nodeScope string) bool {
+	if v, ok := this.Get(key, treeScope, nodeScope).(bool); ok {
+		return v
+	}
+	return def
+}
+
+func (this *Blackboard) GetStringOr(key string, def string, treeScope, nodeScope string) string {
+	if v, ok := this.Get(key, treeScope, nodeScope).(string); ok {
+		return v
+	}
+	return def
+}
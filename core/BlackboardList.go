@@ -0,0 +1,89 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+)
+
+/**
+ * Collection helpers for slices stored on the blackboard (waypoints,
+ * pending orders...). A missing key is an empty list and a new list is
+ * stored as []interface{}; a list stored by other code can be of any
+ * slice type, as long as the values pushed are assignable to its elements.
+ *
+ * The helpers never modify a stored slice in place: they store a new one
+ * with Set, so a slice previously read by a node is left untouched and an
+ * attached Storage sees every change.
+**/
+
+func (this *Blackboard) getList(key, treeScope, nodeScope string) reflect.Value {
+	v := this.Get(key, treeScope, nodeScope)
+	if v == nil {
+		return reflect.Value{}
+	}
+	list := reflect.ValueOf(v)
+	if list.Kind() != reflect.Slice {
+		panic(fmt.Sprintf("blackboard key %s is not a list: %v", key, reflect.TypeOf(v)))
+	}
+	return list
+}
+
+func appendList(list reflect.Value, value interface{}) reflect.Value {
+	if !list.IsValid() {
+		return reflect.ValueOf([]interface{}{value})
+	}
+	elem := reflect.New(list.Type().Elem()).Elem()
+	if value != nil {
+		elem.Set(reflect.ValueOf(value))
+	}
+	out := reflect.MakeSlice(list.Type(), list.Len(), list.Len()+1)
+	reflect.Copy(out, list)
+	return reflect.Append(out, elem)
+}
+
+// PushBack appends value to the list stored under key.
+func (this *Blackboard) PushBack(key string, value interface{}, treeScope, nodeScope string) {
+	list := this.getList(key, treeScope, nodeScope)
+	this.Set(key, appendList(list, value).Interface(), treeScope, nodeScope)
+}
+
+// PopFront removes and returns the first value of the list stored under
+// key; ok is false if the list is empty.
+func (this *Blackboard) PopFront(key, treeScope, nodeScope string) (value interface{}, ok bool) {
+	list := this.getList(key, treeScope, nodeScope)
+	if !list.IsValid() || list.Len() == 0 {
+		return nil, false
+	}
+	value = list.Index(0).Interface()
+	rest := reflect.MakeSlice(list.Type(), list.Len()-1, list.Len()-1)
+	reflect.Copy(rest, list.Slice(1, list.Len()))
+	this.Set(key, rest.Interface(), treeScope, nodeScope)
+	return value, true
+}
+
+/**
+ * AppendUnique appends value to the list stored under key unless the list
+ * already holds an equal value (compared with reflect.DeepEqual). It
+ * returns whether value was added.
+**/
+func (this *Blackboard) AppendUnique(key string, value interface{}, treeScope, nodeScope string) bool {
+	list := this.getList(key, treeScope, nodeScope)
+	if list.IsValid() {
+		for i := 0; i < list.Len(); i++ {
+			if reflect.DeepEqual(list.Index(i).Interface(), value) {
+				return false
+			}
+		}
+	}
+	this.Set(key, appendList(list, value).Interface(), treeScope, nodeScope)
+	return true
+}
+
+// Len returns the length of the list stored under key, 0 if it is not set.
+func (this *Blackboard) Len(key, treeScope, nodeScope string) int {
+	list := this.getList(key, treeScope, nodeScope)
+	if !list.IsValid() {
+		return 0
+	}
+	return list.Len()
+}
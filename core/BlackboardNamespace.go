@@ -0,0 +1,86 @@
+package core
+
+/**
+ * BlackboardNamespace is a view of a blackboard prefixing every key with
+ * "<name>.", so independent node libraries sharing the global memory do
+ * not collide:
+ *
+ *     combat := tick.Blackboard.Namespace("combat")
+ *     combat.SetMem("target", enemy) // stored as "combat.target"
+ *
+ * Namespaces nest: bb.Namespace("lib").Namespace("combat") prefixes with
+ * "lib.combat.". The view holds no data, creating one is cheap.
+ *
+ * @class BlackboardNamespace
+**/
+type BlackboardNamespace struct {
+	blackboard *Blackboard
+	prefix     string
+}
+
+// Namespace returns a view of the blackboard whose keys are prefixed
+// with "<name>.".
+func (this *Blackboard) Namespace(name string) *BlackboardNamespace {
+	return &BlackboardNamespace{blackboard: this, prefix: name + "."}
+}
+
+func (this *BlackboardNamespace) Namespace(name string) *BlackboardNamespace {
+	return &BlackboardNamespace{blackboard: this.blackboard, prefix: this.prefix + name + "."}
+}
+
+// Key returns the key of the underlying blackboard for key.
+func (this *BlackboardNamespace) Key(key string) string {
+	return this.prefix + key
+}
+
+func (this *BlackboardNamespace) Blackboard() *Blackboard {
+	return this.blackboard
+}
+
+func (this *BlackboardNamespace) Set(key string, value interface{}, treeScope, nodeScope string) {
+	this.blackboard.Set(this.prefix+key, value, treeScope, nodeScope)
+}
+
+func (this *BlackboardNamespace) SetMem(key string, value interface{}) {
+	this.blackboard.SetMem(this.prefix+key, value)
+}
+
+func (this *BlackboardNamespace) SetTree(key string, value interface{}, treeScope string) {
+	this.blackboard.SetTree(this.prefix+key, value, treeScope)
+}
+
+func (this *BlackboardNamespace) Remove(key string) {
+	this.blackboard.Remove(this.prefix + key)
+}
+
+func (this *BlackboardNamespace) Get(key, treeScope, nodeScope string) interface{} {
+	return this.blackboard.Get(this.prefix+key, treeScope, nodeScope)
+}
+
+func (this *BlackboardNamespace) GetMem(key string) interface{} {
+	return this.blackboard.GetMem(this.prefix + key)
+}
+
+func (this *BlackboardNamespace) GetBool(key, treeScope, nodeScope string) bool {
+	return this.blackboard.GetBool(this.prefix+key, treeScope, nodeScope)
+}
+
+func (this *BlackboardNamespace) GetInt(key, treeScope, nodeScope string) int {
+	return this.blackboard.GetInt(this.prefix+key, treeScope, nodeScope)
+}
+
+func (this *BlackboardNamespace) GetInt64(key, treeScope, nodeScope string) int64 {
+	return this.blackboard.GetInt64(this.prefix+key, treeScope, nodeScope)
+}
+
+func (this *BlackboardNamespace) GetFloat64(key, treeScope, nodeScope string) float64 {
+	return this.blackboard.GetFloat64(this.prefix+key, treeScope, nodeScope)
+}
+
+// Watch is Blackboard.Watch on the prefixed key; fn receives the key
+// without the prefix.
+func (this *BlackboardNamespace) Watch(key, treeScope, nodeScope string, fn WatchFunc) (cancel func()) {
+	return this.blackboard.Watch(this.prefix+key, treeScope, nodeScope, func(_ string, old, new interface{}) {
+		fn(key, old, new)
+	})
+}
@@ -0,0 +1,68 @@
+package core
+
+// BlackboardChange describes one write or removal on a blackboard.
+type BlackboardChange struct {
+	Key       string
+	TreeScope string
+	NodeScope string
+	Old       interface{}
+	New       interface{} // nil for a removal
+	// number of tree ticks started on the blackboard before the change
+	Tick uint64
+}
+
+// ChangeSink receives the changes of a blackboard, see SetChangeSink.
+type ChangeSink interface {
+	Change(change BlackboardChange)
+}
+
+// ChangeSinkFunc adapts a function to ChangeSink.
+type ChangeSinkFunc func(change BlackboardChange)
+
+func (f ChangeSinkFunc) Change(change BlackboardChange) {
+	f(change)
+}
+
+/**
+ * ChannelSink is a ChangeSink sending changes to a channel, for a consumer
+ * on another goroutine (analytics, telemetry...). When the channel is full
+ * the change is dropped and counted rather than stalling the tick.
+**/
+type ChannelSink struct {
+	ch      chan<- BlackboardChange
+	dropped uint64
+}
+
+func NewChannelSink(ch chan<- BlackboardChange) *ChannelSink {
+	return &ChannelSink{ch: ch}
+}
+
+func (this *ChannelSink) Change(change BlackboardChange) {
+	select {
+	case this.ch <- change:
+	default:
+		this.dropped++
+	}
+}
+
+// Dropped returns the number of changes dropped on a full channel; read
+// it from the goroutine ticking the blackboard.
+func (this *ChannelSink) Dropped() uint64 {
+	return this.dropped
+}
+
+/**
+ * SetChangeSink forwards every mutation of the blackboard to sink, nil to
+ * stop. Node memory changes made by the framework itself ("isOpen", ...)
+ * are included; a sink only interested in the data of the agent can skip
+ * the changes with a NodeScope. Values are the ones stored, so a sink
+ * keeping them must not modify them.
+**/
+func (this *Blackboard) SetChangeSink(sink ChangeSink) {
+	this._sink = sink
+}
+
+// TickCount returns the number of tree ticks started on the blackboard.
+func (this *Blackboard) TickCount() uint64 {
+	return this._tickCount
+}
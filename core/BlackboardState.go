@@ -0,0 +1,112 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+)
+
+// MemoryEntry is one value of a blackboard, with its scope.
+type MemoryEntry struct {
+	Key       string
+	TreeScope string
+	NodeScope string
+	Value     interface{}
+}
+
+// TreeState is the running state a blackboard keeps for one tree: the ids
+// of the nodes left open (RUNNING) by the last tick, outermost first.
+type TreeState struct {
+	TreeID    string
+	OpenNodes []string
+}
+
+/**
+ * BlackboardState is a plain copy of everything a blackboard holds, used
+ * to save an agent and resume it later (possibly in another process).
+ * Entries are sorted by tree scope, node scope and key so that exporting
+ * the same blackboard twice gives the same result.
+**/
+type BlackboardState struct {
+	Entries []MemoryEntry
+	Trees   []TreeState
+}
+
+/**
+ * Export copies the content of the blackboard. Values are copied as is,
+ * so mutable values (slices, maps, pointers) are shared with the
+ * blackboard.
+**/
+func (this *Blackboard) Export() *BlackboardState {
+	state := &BlackboardState{}
+	for key, value := range this._baseMemory._memory {
+		state.Entries = append(state.Entries, MemoryEntry{Key: key, Value: value})
+	}
+	for treeID, treeMem := range this._treeMemory {
+		for key, value := range treeMem._memory {
+			state.Entries = append(state.Entries, MemoryEntry{Key: key, TreeScope: treeID, Value: value})
+		}
+		for nodeID, nodeMem := range treeMem._nodeMemory {
+			for key, value := range nodeMem._memory {
+				state.Entries = append(state.Entries, MemoryEntry{Key: key, TreeScope: treeID, NodeScope: nodeID, Value: value})
+			}
+		}
+		if len(treeMem._treeData.OpenNodes) > 0 {
+			ts := TreeState{TreeID: treeID}
+			for _, node := range treeMem._treeData.OpenNodes {
+				ts.OpenNodes = append(ts.OpenNodes, node.GetID())
+			}
+			state.Trees = append(state.Trees, ts)
+		}
+	}
+	sort.Slice(state.Entries, func(i, j int) bool {
+		a, b := &state.Entries[i], &state.Entries[j]
+		if a.TreeScope != b.TreeScope {
+			return a.TreeScope < b.TreeScope
+		}
+		if a.NodeScope != b.NodeScope {
+			return a.NodeScope < b.NodeScope
+		}
+		return a.Key < b.Key
+	})
+	sort.Slice(state.Trees, func(i, j int) bool {
+		return state.Trees[i].TreeID < state.Trees[j].TreeID
+	})
+	return state
+}
+
+/**
+ * Import replaces the content of the blackboard with state. trees resolves
+ * a tree id of the state to the loaded tree, whose nodes (subtrees
+ * included) are looked up to rebuild the open node lists. An error is
+ * returned, and the blackboard left empty, if an open node cannot be found.
+ *
+ * Values are written with Set, so an attached Storage sees them.
+**/
+func (this *Blackboard) Import(state *BlackboardState, trees func(treeID string) *BehaviorTree) error {
+	this._baseMemory = NewMemory()
+	this._treeMemory = make(map[string]*TreeMemory)
+
+	for _, e := range state.Entries {
+		this.Set(e.Key, e.Value, e.TreeScope, e.NodeScope)
+	}
+	for _, ts := range state.Trees {
+		tree := trees(ts.TreeID)
+		if tree == nil {
+			this._baseMemory = NewMemory()
+			this._treeMemory = make(map[string]*TreeMemory)
+			return fmt.Errorf("blackboard import: unknown tree %s", ts.TreeID)
+		}
+		openNodes := make([]IBaseNode, 0, len(ts.OpenNodes))
+		for _, id := range ts.OpenNodes {
+			node := toBaseNode(tree.FindNode(id))
+			if node == nil {
+				this._baseMemory = NewMemory()
+				this._treeMemory = make(map[string]*TreeMemory)
+				return fmt.Errorf("blackboard import: tree %s has no node %s", ts.TreeID, id)
+			}
+			openNodes = append(openNodes, node)
+		}
+		this._getTreeData(ts.TreeID).OpenNodes = openNodes
+	}
+	return nil
+}
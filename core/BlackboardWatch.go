@@ -0,0 +1,125 @@
+package core
+
+import (
+	"reflect"
+)
+
+// WatchFunc is called after a watched key is written or removed, with the
+// previous and the new value (nil when removed).
+type WatchFunc func(key string, old interface{}, new interface{})
+
+type watchKey struct {
+	key       string
+	treeScope string
+	nodeScope string
+}
+
+type watcher struct {
+	fn WatchFunc
+	// only called when the value changes, see WatchChanges
+	changes bool
+}
+
+/**
+ * Watch calls fn after every write (Set, SetMem, SetTree) or removal of
+ * key in the given scope, even if the value did not change. It returns a
+ * function removing the watch. Watchers run synchronously on the writing
+ * goroutine and may write to the blackboard themselves.
+ *
+ * @method Watch
+ * @param {String} key The key to watch.
+ * @param {String} treeScope The tree id, empty for the global memory.
+ * @param {String} nodeScope The node id, empty for the tree memory.
+**/
+func (this *Blackboard) Watch(key, treeScope, nodeScope string, fn WatchFunc) (cancel func()) {
+	return this.watch(key, treeScope, nodeScope, &watcher{fn: fn})
+}
+
+/**
+ * WatchChanges is Watch only calling fn when the value changed, compared
+ * with reflect.DeepEqual; writing the same value again is not reported.
+**/
+func (this *Blackboard) WatchChanges(key, treeScope, nodeScope string, fn WatchFunc) (cancel func()) {
+	return this.watch(key, treeScope, nodeScope, &watcher{fn: fn, changes: true})
+}
+
+// Unwatch removes all the watchers of key in the given scope.
+func (this *Blackboard) Unwatch(key, treeScope, nodeScope string) {
+	delete(this._watchers, watchKey{key, treeScope, nodeScope})
+}
+
+func (this *Blackboard) watch(key, treeScope, nodeScope string, w *watcher) (cancel func()) {
+	if this._watchers == nil {
+		this._watchers = make(map[watchKey][]*watcher)
+	}
+	wk := watchKey{key, treeScope, nodeScope}
+	this._watchers[wk] = append(this._watchers[wk], w)
+	return func() {
+		list := this._watchers[wk]
+		for i, other := range list {
+			if other == w {
+				this._watchers[wk] = append(list[:i:i], list[i+1:]...)
+				break
+			}
+		}
+		if len(this._watchers[wk]) == 0 {
+			delete(this._watchers, wk)
+		}
+	}
+}
+
+// notify runs the change sink and the watchers of key after a write.
+func (this *Blackboard) notify(key, treeScope, nodeScope string, old, new interface{}) {
+	if this._sink != nil {
+		this._sink.Change(BlackboardChange{key, treeScope, nodeScope, old, new, this._tickCount})
+	}
+	if len(this._watchers) == 0 {
+		return
+	}
+	for _, w := range this._watchers[watchKey{key, treeScope, nodeScope}] {
+		if w.changes && reflect.DeepEqual(old, new) {
+			continue
+		}
+		w.fn(key, old, new)
+	}
+}
+
+/**
+ * AddNodeWatch ties cancel, as returned by Watch or WatchChanges, to the
+ * memory of a node: UnwatchNode calls it, and so does the removal of the
+ * node or tree memory (RemoveNodeScope, RemoveTreeScope). The function is
+ * kept off the memory, out of the snapshots, storages and change sinks,
+ * which cannot hold it.
+**/
+func (this *Blackboard) AddNodeWatch(treeScope, nodeScope string, cancel func()) {
+	if this._nodeWatches == nil {
+		this._nodeWatches = make(map[watchKey][]func())
+	}
+	wk := watchKey{"", treeScope, nodeScope}
+	this._nodeWatches[wk] = append(this._nodeWatches[wk], cancel)
+}
+
+// HasNodeWatches tells whether watches are tied to the node, see
+// AddNodeWatch.
+func (this *Blackboard) HasNodeWatches(treeScope, nodeScope string) bool {
+	return len(this._nodeWatches[watchKey{"", treeScope, nodeScope}]) > 0
+}
+
+// UnwatchNode cancels the watches tied to the node, see AddNodeWatch.
+func (this *Blackboard) UnwatchNode(treeScope, nodeScope string) {
+	wk := watchKey{"", treeScope, nodeScope}
+	cancels := this._nodeWatches[wk]
+	delete(this._nodeWatches, wk)
+	for _, cancel := range cancels {
+		cancel()
+	}
+}
+
+// unwatchTree cancels the watches tied to the nodes of a tree.
+func (this *Blackboard) unwatchTree(treeScope string) {
+	for wk := range this._nodeWatches {
+		if wk.treeScope == treeScope {
+			this.UnwatchNode(wk.treeScope, wk.nodeScope)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package core
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Clock gives the time seen by the nodes of an agent, see
+// Blackboard.SetClock.
+type Clock interface {
+	Now() time.Time
+}
+
+type wallClock struct{}
+
+func (wallClock) Now() time.Time {
+	return time.Now()
+}
+
+/**
+ * StepClock is a Clock advancing by a fixed step when told to, for fixed
+ * timestep simulations: call Step once per frame, before ticking.
+ *
+ *     clock := NewStepClock(time.Unix(0, 0), 50*time.Millisecond)
+ *     blackboard.SetClock(clock)
+ *     for {
+ *         clock.Step()
+ *         tree.Tick(npc, blackboard)
+ *     }
+ *
+ * @class StepClock
+**/
+type StepClock struct {
+	now  time.Time
+	step time.Duration
+}
+
+func NewStepClock(start time.Time, step time.Duration) *StepClock {
+	return &StepClock{now: start, step: step}
+}
+
+func (this *StepClock) Now() time.Time {
+	return this.now
+}
+
+// Step advances the clock by one step.
+func (this *StepClock) Step() {
+	this.now = this.now.Add(this.step)
+}
+
+// Advance advances the clock by d, which need not be a multiple of the
+// step.
+func (this *StepClock) Advance(d time.Duration) {
+	this.now = this.now.Add(d)
+}
+
+var (
+	deterministic   bool
+	determinismHook = func(reason string) {
+		panic("b3 determinism: " + reason)
+	}
+	determinismLock sync.RWMutex
+)
+
+/**
+ * SetDeterministic turns the determinism mode on or off. In that mode the
+ * built-in nodes must not read the wall clock nor an unseeded random
+ * generator: every blackboard ticked needs a Clock (SetClock) and a seed
+ * (SetSeed), so lockstep simulations replaying the same inputs get the
+ * same decisions. A violation calls the determinism hook, which panics by
+ * default.
+ *
+ * Custom nodes get the same guarantee by using tick.Now and tick.Rand
+ * instead of time.Now and math/rand.
+**/
+func SetDeterministic(on bool) {
+	determinismLock.Lock()
+	deterministic = on
+	determinismLock.Unlock()
+}
+
+func Deterministic() bool {
+	determinismLock.RLock()
+	defer determinismLock.RUnlock()
+	return deterministic
+}
+
+// SetDeterminismHook replaces the function called on a determinism
+// violation, to log or count them instead of panicking.
+func SetDeterminismHook(f func(reason string)) {
+	determinismLock.Lock()
+	determinismHook = f
+	determinismLock.Unlock()
+}
+
+// AssertDeterministic reports reason to the determinism hook when the
+// determinism mode is on; custom nodes call it before using a source the
+// mode forbids.
+func AssertDeterministic(reason string) {
+	determinismLock.RLock()
+	on, hook := deterministic, determinismHook
+	determinismLock.RUnlock()
+	if on && hook != nil {
+		hook(reason)
+	}
+}
+
+// SetClock sets the clock of the agent; nil restores the wall clock.
+func (this *Blackboard) SetClock(clock Clock) {
+	this._clock = clock
+}
+
+// Now returns the time of the clock of the agent.
+func (this *Blackboard) Now() time.Time {
+	if this._clock == nil {
+		AssertDeterministic("wall clock read, no Clock set on the blackboard")
+		return time.Now()
+	}
+	return this._clock.Now()
+}
+
+// SetSeed gives the agent a random generator seeded with seed.
+func (this *Blackboard) SetSeed(seed int64) {
+	this._rand = rand.New(rand.NewSource(seed))
+}
+
+// Rand returns the random generator of the agent, seeded from the time if
+// SetSeed was not called.
+func (this *Blackboard) Rand() *rand.Rand {
+	if this._rand == nil {
+		AssertDeterministic("random generator used, no seed set on the blackboard")
+		this._rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return this._rand
+}
+
+// Now returns the time of the agent, see Blackboard.SetClock.
+func (this *Tick) Now() time.Time {
+	return this.Blackboard.Now()
+}
+
+// Rand returns the random generator of the agent, see Blackboard.SetSeed.
+func (this *Tick) Rand() *rand.Rand {
+	return this.Blackboard.Rand()
+}
@@ -0,0 +1,45 @@
+package core
+
+import (
+	"github.com/youngtrips/behavior3go/config"
+)
+
+/**
+ * Clone returns an isolated copy of a loaded tree: every node is created
+ * and initialized again from a deep copy of the config, so node struct
+ * fields (custom actions keeping internal state) are not shared with the
+ * original. The clone keeps the id of the tree, its memory in a
+ * blackboard stays valid, together with its debug, debug draw,
+ * listeners and profile sink. SubTree nodes still resolve shared trees by name.
+ *
+ * It panics on a tree not built by Load.
+ *
+ * @method Clone
+ * @return {BehaviorTree} The copy.
+**/
+func (this *BehaviorTree) Clone() *BehaviorTree {
+	if this.dumpInfo == nil {
+		panic("Clone: tree " + this.id + " was not loaded from a config")
+	}
+	tree := NewBeTree()
+	tree.Load(DeepCopy(this.dumpInfo).(*config.BTTreeCfg), this.maps, this.extMaps)
+	tree.id = this.id
+	tree.debug = this.debug
+	tree.debugDraw = this.debugDraw
+	tree.listeners = append([]Listener(nil), this.listeners...)
+	tree.profileSink = this.profileSink
+	tree.logger = this.logger
+	// keep the subtrees bound by BindSubTrees
+	bound := make(map[string]*BehaviorTree)
+	eachSubTree(this.root, func(sub *SubTree) {
+		if sub.tree != nil {
+			bound[sub.GetName()] = sub.tree
+		}
+	})
+	if len(bound) > 0 {
+		tree.BindSubTrees(func(name string) *BehaviorTree {
+			return bound[name]
+		})
+	}
+	return tree
+}
@@ -0,0 +1,158 @@
+package core
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+)
+
+/**
+ * CoerceInt64 converts a number of any type (signed and unsigned
+ * integers, floats, json.Number or a numeric string) to int64. It fails,
+ * rather than panicking or truncating, on other types, on out of range
+ * values and on floats with a fractional part.
+**/
+func CoerceInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int8:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint, uint8, uint16, uint32, uint64, uintptr:
+		u, _ := CoerceUint64(n)
+		if u > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(u), true
+	case float32:
+		return floatToInt64(float64(n))
+	case float64:
+		return floatToInt64(n)
+	case json.Number:
+		return stringToInt64(n.String())
+	case string:
+		return stringToInt64(n)
+	}
+	return 0, false
+}
+
+func floatToInt64(f float64) (int64, bool) {
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+func stringToInt64(s string) (int64, bool) {
+	s = strings.TrimSpace(s)
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i, true
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return floatToInt64(f)
+	}
+	return 0, false
+}
+
+// CoerceUint64 is CoerceInt64 for uint64; negative values fail.
+func CoerceUint64(v interface{}) (uint64, bool) {
+	switch n := v.(type) {
+	case uint:
+		return uint64(n), true
+	case uint8:
+		return uint64(n), true
+	case uint16:
+		return uint64(n), true
+	case uint32:
+		return uint64(n), true
+	case uint64:
+		return n, true
+	case uintptr:
+		return uint64(n), true
+	case json.Number:
+		if u, err := strconv.ParseUint(n.String(), 10, 64); err == nil {
+			return u, true
+		}
+	case string:
+		if u, err := strconv.ParseUint(strings.TrimSpace(n), 10, 64); err == nil {
+			return u, true
+		}
+	}
+	i, ok := CoerceInt64(v)
+	if !ok || i < 0 {
+		return 0, false
+	}
+	return uint64(i), true
+}
+
+// CoerceInt is CoerceInt64 for int.
+func CoerceInt(v interface{}) (int, bool) {
+	i, ok := CoerceInt64(v)
+	if !ok || int64(int(i)) != i {
+		return 0, false
+	}
+	return int(i), true
+}
+
+// CoerceInt32 is CoerceInt64 for int32.
+func CoerceInt32(v interface{}) (int32, bool) {
+	i, ok := CoerceInt64(v)
+	if !ok || i < math.MinInt32 || i > math.MaxInt32 {
+		return 0, false
+	}
+	return int32(i), true
+}
+
+// CoerceFloat64 converts a number of any type, json.Number or a numeric
+// string to float64.
+func CoerceFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	}
+	if i, ok := CoerceInt64(v); ok {
+		return float64(i), true
+	}
+	if u, ok := CoerceUint64(v); ok {
+		return float64(u), true
+	}
+	return 0, false
+}
+
+// The coerced getters read a number stored with any type, see
+// CoerceInt64; ok is false if the key is not set or not a number.
+
+func (this *Blackboard) GetIntCoerced(key, treeScope, nodeScope string) (int, bool) {
+	return CoerceInt(this.Get(key, treeScope, nodeScope))
+}
+
+func (this *Blackboard) GetInt32Coerced(key, treeScope, nodeScope string) (int32, bool) {
+	return CoerceInt32(this.Get(key, treeScope, nodeScope))
+}
+
+func (this *Blackboard) GetInt64Coerced(key, treeScope, nodeScope string) (int64, bool) {
+	return CoerceInt64(this.Get(key, treeScope, nodeScope))
+}
+
+func (this *Blackboard) GetUint64Coerced(key, treeScope, nodeScope string) (uint64, bool) {
+	return CoerceUint64(this.Get(key, treeScope, nodeScope))
+}
+
+func (this *Blackboard) GetFloatCoerced(key, treeScope, nodeScope string) (float64, bool) {
+	return CoerceFloat64(this.Get(key, treeScope, nodeScope))
+}
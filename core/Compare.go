@@ -0,0 +1,64 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+/**
+ * Compare compares a and b with op: "==", "!=", "<", "<=", ">", ">=" (or
+ * "eq", "ne", "lt", "le", "gt", "ge"). Two numbers of any type, numeric
+ * strings included (see CoerceFloat64), are compared by value, two strings
+ * in lexical order; otherwise only == and != apply and compare the printed
+ * values, so true equals "true".
+**/
+func Compare(a interface{}, op string, b interface{}) (bool, error) {
+	var c int
+	fa, aok := CoerceFloat64(a)
+	fb, bok := CoerceFloat64(b)
+	sa, saok := a.(string)
+	sb, sbok := b.(string)
+	switch {
+	case aok && bok:
+		switch {
+		case fa < fb:
+			c = -1
+		case fa > fb:
+			c = 1
+		}
+	case saok && sbok:
+		c = strings.Compare(sa, sb)
+	default:
+		c = 2 // not equal, not ordered
+		if (a == nil) == (b == nil) && fmt.Sprint(a) == fmt.Sprint(b) {
+			c = 0
+		}
+	}
+
+	switch strings.ToLower(strings.TrimSpace(op)) {
+	case "==", "=", "eq":
+		return c == 0, nil
+	case "!=", "<>", "ne":
+		return c != 0, nil
+	}
+	if c == 2 {
+		return false, fmt.Errorf("cannot order %v (%T) and %v (%T)", a, a, b, b)
+	}
+	switch strings.ToLower(strings.TrimSpace(op)) {
+	case "<", "lt":
+		return c < 0, nil
+	case "<=", "le":
+		return c <= 0, nil
+	case ">", "gt":
+		return c > 0, nil
+	case ">=", "ge":
+		return c >= 0, nil
+	}
+	return false, fmt.Errorf("unknown operator %q", op)
+}
+
+// ValidCompareOp tells whether Compare knows op.
+func ValidCompareOp(op string) bool {
+	_, err := Compare(0, op, 0)
+	return err == nil
+}
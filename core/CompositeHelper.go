@@ -0,0 +1,101 @@
+package core
+
+import (
+	b3 "github.com/youngtrips/behavior3go"
+)
+
+/**
+ * CompositeHelper is a base for custom composites that need to remember
+ * their running child and stop children they no longer tick. Embed it
+ * instead of Composite:
+ *
+ *     type MySequence struct {
+ *         CompositeHelper
+ *     }
+ *
+ *     func (this *MySequence) OnTick(tick *Tick) b3.Status {
+ *         start := this.RunningChildIndex(tick)
+ *         if start < 0 {
+ *             start = 0
+ *         }
+ *         _, status := this.TickChildrenFrom(tick, start, b3.SUCCESS)
+ *         return status
+ *     }
+ *
+ * The running child is kept in the node memory and forgotten when the
+ * node opens or closes. A composite defining its own OnOpen or OnClose
+ * must call the ones of CompositeHelper.
+ *
+ * @module b3
+ * @class CompositeHelper
+ * @extends Composite
+**/
+type CompositeHelper struct {
+	Composite
+}
+
+const runningChildKey = "runningChild"
+
+func (this *CompositeHelper) OnOpen(tick *Tick) {
+	this.SetRunningChildIndex(tick, -1)
+}
+
+func (this *CompositeHelper) OnClose(tick *Tick) {
+	this.SetRunningChildIndex(tick, -1)
+}
+
+// RunningChildIndex returns the index of the child left RUNNING by the
+// previous tick, -1 if there is none.
+func (this *CompositeHelper) RunningChildIndex(tick *Tick) int {
+	v := tick.Blackboard.Get(runningChildKey, tick.tree.id, this.id)
+	if i, ok := v.(int); ok {
+		return i
+	}
+	return -1
+}
+
+func (this *CompositeHelper) SetRunningChildIndex(tick *Tick, index int) {
+	tick.Blackboard.Set(runningChildKey, index, tick.tree.id, this.id)
+}
+
+/**
+ * TickChildrenFrom executes the children from start, in order, as long as
+ * they return next (SUCCESS for a sequence, FAILURE for a selector). It
+ * returns the index and status of the first child returning something
+ * else, or (child count, next) if all of them did.
+ *
+ * A RUNNING child is recorded as the running child, and the children
+ * after it still open from a previous tick are halted; any other status
+ * clears the running child.
+**/
+func (this *CompositeHelper) TickChildrenFrom(tick *Tick, start int, next b3.Status) (int, b3.Status) {
+	for i := start; i < len(this.children); i++ {
+		status := this.children[i].Execute(tick)
+		if status == next {
+			continue
+		}
+		if status == b3.RUNNING {
+			this.SetRunningChildIndex(tick, i)
+		} else {
+			this.SetRunningChildIndex(tick, -1)
+		}
+		this.HaltChildrenAfter(tick, i)
+		return i, status
+	}
+	this.SetRunningChildIndex(tick, -1)
+	return len(this.children), next
+}
+
+// HaltChild closes the child at index, and its open descendants, if it is
+// open.
+func (this *CompositeHelper) HaltChild(tick *Tick, index int) {
+	haltNode(tick, this.children[index])
+}
+
+// HaltChildrenAfter halts the open children with an index above index;
+// use -1 to halt all of them.
+func (this *CompositeHelper) HaltChildrenAfter(tick *Tick, index int) {
+	for i := len(this.children) - 1; i > index; i-- {
+		haltNode(tick, this.children[i])
+	}
+}
@@ -0,0 +1,42 @@
+package core
+
+import (
+	"context"
+)
+
+/**
+ * IConcurrentWork is implemented by nodes with a blocking part (I/O, a
+ * remote call...) that a Concurrent composite runs on its own goroutine.
+ *
+ * The blackboard and the tick are not safe for concurrent use, so the
+ * work is split: PrepareWork runs on the ticking goroutine and reads what
+ * the work needs from the blackboard; Work runs on another goroutine with
+ * that input only, and must not touch the tick, the blackboard nor the
+ * node fields; then the node is executed as usual on the ticking
+ * goroutine and reads the outcome with Tick.WorkResult in OnTick.
+**/
+type IConcurrentWork interface {
+	PrepareWork(tick *Tick) interface{}
+	Work(ctx context.Context, input interface{}) (interface{}, error)
+}
+
+type workResult struct {
+	value interface{}
+	err   error
+}
+
+// SetWorkResult records the outcome of the work of node for its
+// execution in this tick; called by the composites running the work.
+func (this *Tick) SetWorkResult(node IBaseNode, value interface{}, err error) {
+	if this._work == nil {
+		this._work = make(map[string]workResult)
+	}
+	this._work[node.GetID()] = workResult{value, err}
+}
+
+// WorkResult returns the outcome of the work of node in this tick; ok is
+// false if no work ran for it.
+func (this *Tick) WorkResult(node IBaseNode) (value interface{}, err error, ok bool) {
+	r, ok := this._work[node.GetID()]
+	return r.value, r.err, ok
+}
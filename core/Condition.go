@@ -9,6 +9,21 @@ type ICondition interface {
 	IBaseNode
 }
 
+/**
+ * Condition is the base class for all condition nodes: leaves checking
+ * the state of the world without changing it, returning `SUCCESS` or
+ * `FAILURE`. They tick like actions, but their category lets the
+ * reactive nodes find the guards of a branch (see AbortGuard) and the
+ * editors tell them apart.
+ *
+ * A node built on Action is a condition as well when its config declares
+ * the "condition" category, so custom conditions written before this type
+ * keep working.
+ *
+ * @module b3
+ * @class Condition
+ * @extends BaseNode
+**/
 type Condition struct {
 	BaseNode
 	BaseWorker
@@ -28,4 +43,11 @@ func (this *Condition) Ctor() {
 func (this *Condition) Initialize(params *BTNodeCfg) {
 	this.BaseNode.Initialize(params)
 	//this.BaseNode.IBaseWorker = this
+	this.parameters = make(map[string]interface{})
+	this.properties = make(map[string]interface{})
+}
+
+// IsCondition tells whether node is a condition.
+func IsCondition(node IBaseNode) bool {
+	return node != nil && node.GetCategory() == b3.CONDITION
 }
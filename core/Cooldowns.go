@@ -0,0 +1,95 @@
+package core
+
+import (
+	"sort"
+	"time"
+)
+
+/**
+ * Cooldowns is the cooldown registry of an agent, keyed by ability name.
+ * It belongs to the blackboard rather than to a tree or node memory, so
+ * "fireball every 5s" still holds when the agent switches trees:
+ *
+ *     cd := tick.Cooldowns()
+ *     if cd.TryStart("fireball", 5*time.Second) {
+ *         // cast
+ *     }
+ *
+ * The registry of a blackboard uses its clock (see SetClock). Like the
+ * blackboard, it is not safe for concurrent use.
+ *
+ * @class Cooldowns
+**/
+type Cooldowns struct {
+	now   func() time.Time
+	until map[string]time.Time
+}
+
+func NewCooldowns() *Cooldowns {
+	return &Cooldowns{now: time.Now, until: make(map[string]time.Time)}
+}
+
+// Ready tells whether name is not cooling down.
+func (this *Cooldowns) Ready(name string) bool {
+	return this.Remaining(name) == 0
+}
+
+// Remaining returns how long name still cools down, 0 if it is ready.
+func (this *Cooldowns) Remaining(name string) time.Duration {
+	until, ok := this.until[name]
+	if !ok {
+		return 0
+	}
+	left := until.Sub(this.now())
+	if left <= 0 {
+		delete(this.until, name)
+		return 0
+	}
+	return left
+}
+
+// Start puts name on cooldown for d, replacing a running cooldown.
+func (this *Cooldowns) Start(name string, d time.Duration) {
+	this.until[name] = this.now().Add(d)
+}
+
+// TryStart starts the cooldown of name if it is ready and tells whether
+// it did.
+func (this *Cooldowns) TryStart(name string, d time.Duration) bool {
+	if !this.Ready(name) {
+		return false
+	}
+	this.Start(name, d)
+	return true
+}
+
+// Reset makes name ready.
+func (this *Cooldowns) Reset(name string) {
+	delete(this.until, name)
+}
+
+// Names returns the names cooling down, sorted.
+func (this *Cooldowns) Names() []string {
+	names := make([]string, 0, len(this.until))
+	for name := range this.until {
+		if this.Remaining(name) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Cooldowns returns the cooldown registry of the agent owning the
+// blackboard.
+func (this *Blackboard) Cooldowns() *Cooldowns {
+	if this._cooldowns == nil {
+		this._cooldowns = NewCooldowns()
+		this._cooldowns.now = this.Now
+	}
+	return this._cooldowns
+}
+
+func (this *Tick) Cooldowns() *Cooldowns {
+	return this.Blackboard.Cooldowns()
+}
@@ -0,0 +1,27 @@
+package core
+
+import (
+	b3 "github.com/youngtrips/behavior3go"
+)
+
+/**
+ * IDebug receives the node callbacks of every tick. Set an implementation
+ * with `BehaviorTree.SetDebug`; debug objects not implementing IDebug are
+ * still carried on the tick but never called.
+ *
+ * The node passed in is the node's BaseNode, which is enough to identify it
+ * (GetID, GetName, GetTitle, GetCategory). The callbacks run on the ticking
+ * goroutine, in the same order as the node callbacks: Enter, Open (only if
+ * the node was not open), Tick (with the returned status), Close (only if
+ * the status is not RUNNING) and Exit.
+ *
+ * @module b3
+ * @class IDebug
+**/
+type IDebug interface {
+	EnterNode(tick *Tick, node IBaseNode)
+	OpenNode(tick *Tick, node IBaseNode)
+	TickNode(tick *Tick, node IBaseNode, status b3.Status)
+	CloseNode(tick *Tick, node IBaseNode)
+	ExitNode(tick *Tick, node IBaseNode)
+}
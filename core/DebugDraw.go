@@ -0,0 +1,142 @@
+package core
+
+import (
+	"sort"
+)
+
+type Vec3 struct {
+	X, Y, Z float64
+}
+
+// DrawKind is the kind of a DrawPrimitive.
+type DrawKind int
+
+const (
+	DrawLine DrawKind = iota
+	DrawSphere
+	DrawText
+)
+
+/**
+ * DrawPrimitive is a shape emitted by a node for the host engine to
+ * render. Lines use From and To, spheres From and Radius, texts From and
+ * Text. Color is 0xRRGGBBAA.
+**/
+type DrawPrimitive struct {
+	NodeID string
+	Kind   DrawKind
+	From   Vec3
+	To     Vec3
+	Radius float64
+	Text   string
+	Color  uint32
+}
+
+/**
+ * IDebugDraw renders the primitives of a tree. Set an implementation with
+ * `BehaviorTree.SetDebugDraw`; it is called at the end of every tick with
+ * the primitives of the nodes still open plus those of the nodes closed
+ * during the tick, so a short lived action still shows for one frame.
+ *
+ * @module b3
+ * @class IDebugDraw
+**/
+type IDebugDraw interface {
+	DebugDraw(target interface{}, primitives []DrawPrimitive)
+}
+
+/**
+ * DebugDraw records the primitives of one node, see Tick.DebugDraw. The
+ * primitives of a node are replaced the first time it draws in a tick and
+ * cleared when it closes.
+ *
+ * @class DebugDraw
+**/
+type DebugDraw struct {
+	tick *Tick
+	node string
+}
+
+/**
+ * DebugDraw returns the recorder of node for this tick. Drawing is a no-op
+ * when the tree has no IDebugDraw; nodes doing expensive work to compute
+ * what to draw can test Enabled first:
+ *
+ *     if draw := tick.DebugDraw(this); draw.Enabled() {
+ *         draw.Line(from, to, 0xff0000ff)
+ *     }
+**/
+func (this *Tick) DebugDraw(node IBaseNode) *DebugDraw {
+	return &DebugDraw{tick: this, node: node.GetID()}
+}
+
+func (this *DebugDraw) Enabled() bool {
+	return this.tick.tree != nil && this.tick.tree.debugDraw != nil
+}
+
+func (this *DebugDraw) Line(from, to Vec3, color uint32) {
+	this.add(DrawPrimitive{Kind: DrawLine, From: from, To: to, Color: color})
+}
+
+func (this *DebugDraw) Sphere(center Vec3, radius float64, color uint32) {
+	this.add(DrawPrimitive{Kind: DrawSphere, From: center, Radius: radius, Color: color})
+}
+
+func (this *DebugDraw) Text(pos Vec3, text string, color uint32) {
+	this.add(DrawPrimitive{Kind: DrawText, From: pos, Text: text, Color: color})
+}
+
+func (this *DebugDraw) add(p DrawPrimitive) {
+	if !this.Enabled() {
+		return
+	}
+	p.NodeID = this.node
+	data := this.tick.Blackboard._getTreeData(this.tick.tree.id)
+	if data.DebugDraws == nil {
+		data.DebugDraws = make(map[string][]DrawPrimitive)
+	}
+	if this.tick._drawn == nil {
+		this.tick._drawn = make(map[string]bool)
+	}
+	if !this.tick._drawn[this.node] {
+		this.tick._drawn[this.node] = true
+		data.DebugDraws[this.node] = nil
+	}
+	data.DebugDraws[this.node] = append(data.DebugDraws[this.node], p)
+}
+
+// clearDebugDraw moves the primitives of a closing node to the frame of
+// the tick.
+func (this *Tick) clearDebugDraw(node IBaseNode) {
+	if this.tree == nil || this.tree.debugDraw == nil {
+		return
+	}
+	data := this.Blackboard._getTreeData(this.tree.id)
+	if prims, ok := data.DebugDraws[node.GetID()]; ok {
+		this._closedDraws = append(this._closedDraws, prims...)
+		delete(data.DebugDraws, node.GetID())
+	}
+}
+
+// flushDebugDraw hands the frame of the tick to the IDebugDraw of the tree.
+func (this *Tick) flushDebugDraw() {
+	draw := this.tree.debugDraw
+	if draw == nil {
+		return
+	}
+	data := this.Blackboard._getTreeData(this.tree.id)
+	frame := this._closedDraws
+	ids := make([]string, 0, len(data.DebugDraws))
+	for id := range data.DebugDraws {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		frame = append(frame, data.DebugDraws[id]...)
+	}
+	draw.DebugDraw(this.target, frame)
+}
+
+func (this *BehaviorTree) SetDebugDraw(draw IDebugDraw) {
+	this.debugDraw = draw
+}
@@ -0,0 +1,209 @@
+package core
+
+import (
+	"strings"
+	"sync"
+
+	b3 "github.com/youngtrips/behavior3go"
+)
+
+/**
+ * DebugFilter is an IDebug forwarding to another one only the callbacks
+ * of the nodes it selects, so a busy server can trace the hotspots it is
+ * interested in without paying for full traces:
+ *
+ *     filter := NewDebugFilter(tracer).
+ *         IncludeCategories(b3.COMPOSITE).
+ *         IncludeTags("combat")
+ *     tree.SetDebug(filter)
+ *
+ * Every include list that is set must match, no exclude list may match.
+ * Tags are read from the "tags" property of the node config, either a
+ * list or a comma separated string. Configure the filter before setting
+ * it on a tree; it is safe to use from several ticking goroutines.
+ *
+ * @class DebugFilter
+**/
+type DebugFilter struct {
+	debug IDebug
+
+	categories        map[string]bool
+	excludeCategories map[string]bool
+	tags              map[string]bool
+	excludeTags       map[string]bool
+	withinNodes       map[string]bool
+	withinTrees       map[string]bool
+
+	mutex    sync.RWMutex
+	nodeTags map[string][]string
+}
+
+func NewDebugFilter(debug IDebug) *DebugFilter {
+	return &DebugFilter{debug: debug, nodeTags: make(map[string][]string)}
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// IncludeCategories keeps only the nodes of the given categories
+// (b3.COMPOSITE, b3.DECORATOR, b3.ACTION, b3.CONDITION).
+func (this *DebugFilter) IncludeCategories(categories ...string) *DebugFilter {
+	this.categories = toSet(categories)
+	return this
+}
+
+func (this *DebugFilter) ExcludeCategories(categories ...string) *DebugFilter {
+	this.excludeCategories = toSet(categories)
+	return this
+}
+
+// IncludeTags keeps only the nodes having one of tags.
+func (this *DebugFilter) IncludeTags(tags ...string) *DebugFilter {
+	this.tags = toSet(tags)
+	return this
+}
+
+func (this *DebugFilter) ExcludeTags(tags ...string) *DebugFilter {
+	this.excludeTags = toSet(tags)
+	return this
+}
+
+// WithinNodes keeps only the given nodes and their descendants.
+func (this *DebugFilter) WithinNodes(ids ...string) *DebugFilter {
+	this.withinNodes = toSet(ids)
+	return this
+}
+
+// WithinTrees keeps only the nodes run inside one of the given subtrees,
+// named by config id or title.
+func (this *DebugFilter) WithinTrees(names ...string) *DebugFilter {
+	this.withinTrees = toSet(names)
+	return this
+}
+
+// Match tells whether the callbacks of node are forwarded.
+func (this *DebugFilter) Match(tick *Tick, node IBaseNode) bool {
+	category := node.GetCategory()
+	if this.categories != nil && !this.categories[category] {
+		return false
+	}
+	if this.excludeCategories[category] {
+		return false
+	}
+	if this.tags != nil || this.excludeTags != nil {
+		found := false
+		for _, tag := range this.tagsOf(tick, node) {
+			if this.excludeTags[tag] {
+				return false
+			}
+			found = found || this.tags[tag]
+		}
+		if this.tags != nil && !found {
+			return false
+		}
+	}
+	if this.withinNodes != nil && !this.within(tick) {
+		return false
+	}
+	if this.withinTrees != nil && !this.inTree(tick) {
+		return false
+	}
+	return true
+}
+
+// within tells whether a selected node is being executed, i.e. is the
+// current node or one of its ancestors.
+func (this *DebugFilter) within(tick *Tick) bool {
+	for _, open := range tick._openNodes {
+		if this.withinNodes[open.GetID()] {
+			return true
+		}
+	}
+	return false
+}
+
+func (this *DebugFilter) inTree(tick *Tick) bool {
+	for _, frame := range tick.SubTreeStack() {
+		if this.withinTrees[frame.Title] {
+			return true
+		}
+		if cfg := frame.Tree.Dump(); cfg != nil && this.withinTrees[cfg.ID] {
+			return true
+		}
+	}
+	return false
+}
+
+// tagsOf reads the tags of node from the config of the tree running it.
+func (this *DebugFilter) tagsOf(tick *Tick, node IBaseNode) []string {
+	this.mutex.RLock()
+	tags, ok := this.nodeTags[node.GetID()]
+	this.mutex.RUnlock()
+	if ok {
+		return tags
+	}
+	stack := tick.SubTreeStack()
+	if len(stack) > 0 {
+		if cfg := stack[len(stack)-1].Tree.Dump(); cfg != nil {
+			tags = parseTags(cfg.Nodes[node.GetID()].Properties["tags"])
+		}
+	}
+	this.mutex.Lock()
+	this.nodeTags[node.GetID()] = tags
+	this.mutex.Unlock()
+	return tags
+}
+
+func parseTags(v interface{}) []string {
+	var tags []string
+	switch t := v.(type) {
+	case string:
+		for _, tag := range strings.Split(t, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	case []interface{}:
+		for _, tag := range t {
+			if s, ok := tag.(string); ok {
+				tags = append(tags, s)
+			}
+		}
+	}
+	return tags
+}
+
+func (this *DebugFilter) EnterNode(tick *Tick, node IBaseNode) {
+	if this.Match(tick, node) {
+		this.debug.EnterNode(tick, node)
+	}
+}
+
+func (this *DebugFilter) OpenNode(tick *Tick, node IBaseNode) {
+	if this.Match(tick, node) {
+		this.debug.OpenNode(tick, node)
+	}
+}
+
+func (this *DebugFilter) TickNode(tick *Tick, node IBaseNode, status b3.Status) {
+	if this.Match(tick, node) {
+		this.debug.TickNode(tick, node, status)
+	}
+}
+
+func (this *DebugFilter) CloseNode(tick *Tick, node IBaseNode) {
+	if this.Match(tick, node) {
+		this.debug.CloseNode(tick, node)
+	}
+}
+
+func (this *DebugFilter) ExitNode(tick *Tick, node IBaseNode) {
+	if this.Match(tick, node) {
+		this.debug.ExitNode(tick, node)
+	}
+}
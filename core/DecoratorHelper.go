@@ -0,0 +1,99 @@
+package core
+
+import (
+	"fmt"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+)
+
+/**
+ * IValidator is implemented by nodes that check their configuration once
+ * the tree is built. BehaviorTree.Load calls Validate on every node after
+ * connecting children and fails with a LoadError on error.
+**/
+type IValidator interface {
+	Validate() error
+}
+
+/**
+ * StatusMap maps the status returned by a child to the status returned by
+ * its decorator; statuses not in the map are returned unchanged.
+**/
+type StatusMap map[b3.Status]b3.Status
+
+func (this StatusMap) Map(status b3.Status) b3.Status {
+	if mapped, ok := this[status]; ok {
+		return mapped
+	}
+	return status
+}
+
+var (
+	InvertStatus = StatusMap{b3.SUCCESS: b3.FAILURE, b3.FAILURE: b3.SUCCESS}
+	ForceSuccess = StatusMap{b3.FAILURE: b3.SUCCESS}
+	ForceFailure = StatusMap{b3.SUCCESS: b3.FAILURE}
+)
+
+/**
+ * DecoratorHelper is a base for custom decorators. Embed it instead of
+ * Decorator:
+ *
+ *     type AlwaysSucceed struct {
+ *         DecoratorHelper
+ *     }
+ *
+ *     func (this *AlwaysSucceed) OnTick(tick *Tick) b3.Status {
+ *         return this.TickChildMapped(tick, ForceSuccess)
+ *     }
+ *
+ * Loading a tree fails if the decorator has no child, or several children
+ * in the config, so OnTick does not have to check for a nil child; the
+ * tick helpers still return ERROR for trees built by hand without one.
+ *
+ * @module b3
+ * @class DecoratorHelper
+ * @extends Decorator
+**/
+type DecoratorHelper struct {
+	Decorator
+	configChildren int
+}
+
+func (this *DecoratorHelper) Initialize(params *BTNodeCfg) {
+	this.Decorator.Initialize(params)
+	this.configChildren = len(params.Children)
+}
+
+func (this *DecoratorHelper) Validate() error {
+	if this.configChildren > 0 {
+		return fmt.Errorf("decorator %s takes a single child, got %d children", this.name, this.configChildren)
+	}
+	if this.child == nil {
+		return fmt.Errorf("decorator %s has no child", this.name)
+	}
+	return nil
+}
+
+// HasChild tells whether a child is set.
+func (this *DecoratorHelper) HasChild() bool {
+	return this.child != nil
+}
+
+// TickChild executes the child, or returns ERROR without one.
+func (this *DecoratorHelper) TickChild(tick *Tick) b3.Status {
+	if this.child == nil {
+		return b3.ERROR
+	}
+	return this.child.Execute(tick)
+}
+
+// TickChildMapped executes the child and maps its status with m.
+func (this *DecoratorHelper) TickChildMapped(tick *Tick, m StatusMap) b3.Status {
+	return m.Map(this.TickChild(tick))
+}
+
+// HaltChild closes the child, and its open descendants, if it is open.
+func (this *DecoratorHelper) HaltChild(tick *Tick) {
+	haltNode(tick, this.child)
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"reflect"
+)
+
+/**
+ * DeepCopy returns a copy of v sharing no slice, map or pointer with it.
+ * Values reachable through unexported struct fields, channels and funcs
+ * are still shared. Cycles and shared pointers are preserved in the copy.
+**/
+func DeepCopy(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	c := &copier{seen: make(map[uintptr]reflect.Value)}
+	return c.copy(reflect.ValueOf(v)).Interface()
+}
+
+type copier struct {
+	// pointers already copied, to keep cycles finite
+	seen map[uintptr]reflect.Value
+}
+
+func (this *copier) copy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		if c, ok := this.seen[v.Pointer()]; ok {
+			return c
+		}
+		c := reflect.New(v.Type().Elem())
+		this.seen[v.Pointer()] = c
+		c.Elem().Set(this.copy(v.Elem()))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(this.copy(v.Elem()))
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(this.copy(v.Index(i)))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(this.copy(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(this.copy(iter.Key()), this.copy(iter.Value()))
+		}
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if f := c.Field(i); f.CanSet() {
+				f.Set(this.copy(v.Field(i)))
+			}
+		}
+		return c
+	}
+	return v
+}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"fmt"
+)
+
+/**
+ * IDryRun is implemented by nodes able to check, without side effects,
+ * that they can run with a blackboard: required keys present, properties
+ * making sense together... See BehaviorTree.DryRun.
+**/
+type IDryRun interface {
+	DryRun(tick *Tick) error
+}
+
+/**
+ * DryRun checks every node of the tree, subtrees included, against
+ * blackboard without ticking anything: the load time checks of the nodes
+ * (IValidator) are run again and the nodes implementing IDryRun are asked
+ * whether they could run. Call it once after loading, with a blackboard
+ * set up like the one of a real agent, to surface configuration problems
+ * before they show up in the game. A panicking check is reported as an
+ * error. The problems are returned in tree order, nil if there are none.
+ *
+ * @method DryRun
+ * @param {b3.Blackboard} blackboard A blackboard, only read.
+**/
+func (this *BehaviorTree) DryRun(blackboard *Blackboard) []*LoadError {
+	tick := NewTick()
+	tick.tree = this
+	tick.Blackboard = blackboard
+
+	paths := make(map[string]string)
+	addPaths := func(tree *BehaviorTree) {
+		if cfg := tree.Dump(); cfg != nil {
+			for id, path := range NodePaths(cfg) {
+				paths[id] = path
+			}
+		}
+	}
+	addPaths(this)
+
+	var errs []*LoadError
+	walkNode(this.root, func(node IBaseNode) bool {
+		for _, tree := range subtreesOf(node) {
+			addPaths(tree)
+		}
+		if err := dryRunNode(tick, node); err != nil {
+			path, ok := paths[node.GetID()]
+			if !ok {
+				path = this.title + " > " + node.GetID()
+			}
+			errs = append(errs, &LoadError{TreeID: this.id, NodeID: node.GetID(), Path: path, Reason: err.Error()})
+		}
+		return true
+	})
+	return errs
+}
+
+func dryRunNode(tick *Tick, node IBaseNode) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	if v, ok := node.(IValidator); ok {
+		if err := v.Validate(); err != nil {
+			return err
+		}
+	}
+	if d, ok := node.(IDryRun); ok {
+		return d.DryRun(tick)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"sync"
+)
+
+// Event is a message published on an EventBus.
+type Event struct {
+	Topic   string
+	Payload interface{}
+}
+
+// EventHandler receives the events of a subscription.
+type EventHandler func(event Event)
+
+type eventSubscription struct {
+	handler EventHandler
+}
+
+/**
+ * EventBus is a publish/subscribe channel between trees and game systems,
+ * by topic: a tree signals "alarm" with the EmitEvent action, the game
+ * subscribed to it reacts, without custom nodes.
+ *
+ * Each blackboard has its bus (Blackboard.Events), for the events of one
+ * agent, and GlobalEvents is shared by all. A bus is safe for concurrent
+ * use; handlers run synchronously on the publishing goroutine, in order of
+ * subscription, so a handler subscribed to a bus published from other
+ * goroutines must not touch a blackboard directly.
+ *
+ * @class EventBus
+**/
+type EventBus struct {
+	mutex sync.RWMutex
+	subs  map[string][]*eventSubscription
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{subs: make(map[string][]*eventSubscription)}
+}
+
+/**
+ * Subscribe calls handler for every event published on topic, or on any
+ * topic for "*", and returns a function removing the subscription.
+**/
+func (this *EventBus) Subscribe(topic string, handler EventHandler) (cancel func()) {
+	sub := &eventSubscription{handler}
+	this.mutex.Lock()
+	this.subs[topic] = append(this.subs[topic], sub)
+	this.mutex.Unlock()
+	return func() {
+		this.mutex.Lock()
+		defer this.mutex.Unlock()
+		list := this.subs[topic]
+		for i, other := range list {
+			if other == sub {
+				this.subs[topic] = append(list[:i:i], list[i+1:]...)
+				break
+			}
+		}
+		if len(this.subs[topic]) == 0 {
+			delete(this.subs, topic)
+		}
+	}
+}
+
+// Publish sends an event to the subscribers of topic and of "*".
+func (this *EventBus) Publish(topic string, payload interface{}) {
+	this.mutex.RLock()
+	subs := make([]*eventSubscription, 0, len(this.subs[topic])+len(this.subs["*"]))
+	subs = append(subs, this.subs[topic]...)
+	if topic != "*" {
+		subs = append(subs, this.subs["*"]...)
+	}
+	this.mutex.RUnlock()
+
+	event := Event{topic, payload}
+	for _, sub := range subs {
+		sub.handler(event)
+	}
+}
+
+// Subscribers returns the number of subscriptions to topic.
+func (this *EventBus) Subscribers(topic string) int {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+	return len(this.subs[topic])
+}
+
+var globalEvents = NewEventBus()
+
+// GlobalEvents returns the bus shared by all the blackboards.
+func GlobalEvents() *EventBus {
+	return globalEvents
+}
+
+// Events returns the event bus of the agent owning the blackboard.
+func (this *Blackboard) Events() *EventBus {
+	if this._events == nil {
+		this._events = NewEventBus()
+	}
+	return this._events
+}
+
+func (this *Tick) Events() *EventBus {
+	return this.Blackboard.Events()
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"fmt"
+
+	b3 "github.com/youngtrips/behavior3go"
+	"github.com/youngtrips/behavior3go/config"
+)
+
+/**
+ * Export returns the config of the tree, to be saved with
+ * config.SaveTreeCfg or MarshalTreeJSON: a copy of the loaded config, so
+ * ids, titles, properties and the editor display are kept, or for a tree
+ * not loaded from a config, one built from its nodes. The nodes without
+ * id are numbered. The subtrees are exported as references, by name.
+**/
+func (this *BehaviorTree) Export() *config.BTTreeCfg {
+	if this.dumpInfo != nil {
+		return DeepCopy(this.dumpInfo).(*config.BTTreeCfg)
+	}
+	data := &config.BTTreeCfg{
+		ID:          this.id,
+		Title:       this.title,
+		Description: this.description,
+		Properties:  DeepCopy(this.properties).(map[string]interface{}),
+		Nodes:       make(map[string]config.BTNodeCfg),
+	}
+	if this.root != nil {
+		data.Root = exportNode(data, this.root)
+	}
+	return data
+}
+
+// exportNode adds node and its children to data and returns its id.
+func exportNode(data *config.BTTreeCfg, node IBaseNode) string {
+	spec := config.BTNodeCfg{
+		Id:       node.GetID(),
+		Name:     node.GetName(),
+		Category: node.GetCategory(),
+		Title:    node.GetTitle(),
+	}
+	if base := toBaseNode(node); base != nil {
+		spec.Description = base.description
+		spec.Properties = DeepCopy(base.properties).(map[string]interface{})
+		if base.config != nil {
+			spec.Display = DeepCopy(base.config.Display).(map[string]interface{})
+		}
+	}
+	if spec.Id == "" {
+		spec.Id = fmt.Sprintf("node-%d", len(data.Nodes)+1)
+	}
+	if _, ok := node.(*SubTree); ok {
+		spec.Category = "tree"
+	}
+	switch node.GetCategory() {
+	case b3.COMPOSITE:
+		comp := node.(IComposite)
+		for i := 0; i < comp.GetChildCount(); i++ {
+			spec.Children = append(spec.Children, exportNode(data, comp.GetChild(i)))
+		}
+	case b3.DECORATOR:
+		if child := node.(IDecorator).GetChild(); child != nil {
+			spec.Child = exportNode(data, child)
+		}
+	}
+	data.Nodes[spec.Id] = spec
+	return spec.Id
+}
@@ -0,0 +1,37 @@
+package core
+
+import (
+	b3 "github.com/youngtrips/behavior3go"
+)
+
+// NodeFactory creates a new node, see RegisterNodeFactory.
+type NodeFactory func() IBaseNode
+
+/**
+ * RegisterNodeFactory registers factory in maps for the nodes named name,
+ * instead of a node type for reflection (b3.RegisterStructMaps.Register).
+ * The factory can pass dependencies to the node:
+ *
+ *     maps := b3.NewRegisterStructMaps()
+ *     core.RegisterNodeFactory(maps, "Attack", func() core.IBaseNode {
+ *         return &Attack{combat: combatService}
+ *     })
+**/
+func RegisterNodeFactory(maps *b3.RegisterStructMaps, name string, factory NodeFactory) {
+	maps.RegisterFactory(name, func() interface{} {
+		return factory()
+	})
+}
+
+// NodeFactories registers nodes by factory, see RegisterNodeFactory.
+type NodeFactories map[string]NodeFactory
+
+// Maps returns the node maps to load trees with, e.g. as the extMap of
+// the loader.
+func (this NodeFactories) Maps() *b3.RegisterStructMaps {
+	maps := b3.NewRegisterStructMaps()
+	for name, factory := range this {
+		RegisterNodeFactory(maps, name, factory)
+	}
+	return maps
+}
@@ -0,0 +1,61 @@
+package core
+
+import (
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+)
+
+// ActionFunc is the tick of an action registered by RegisterAction; cfg
+// is the config of the node, for its properties.
+type ActionFunc func(tick *Tick, cfg *BTNodeCfg) b3.Status
+
+// ConditionFunc is the check of a condition registered by
+// RegisterCondition.
+type ConditionFunc func(tick *Tick, cfg *BTNodeCfg) bool
+
+/**
+ * RegisterAction registers in maps an action named name ticking fn, so a
+ * simple leaf needs no type of its own:
+ *
+ *     core.RegisterAction(maps, "Heal", func(tick *core.Tick, cfg *config.BTNodeCfg) b3.Status {
+ *         tick.GetTarget().(*Monster).Heal(cfg.GetPropertyAsInt("amount"))
+ *         return b3.SUCCESS
+ *     })
+ *
+ * fn is shared by every node of that name: per node state belongs in
+ * the blackboard.
+**/
+func RegisterAction(maps *b3.RegisterStructMaps, name string, fn ActionFunc) {
+	RegisterNodeFactory(maps, name, func() IBaseNode {
+		return &funcAction{fn: fn}
+	})
+}
+
+// RegisterCondition registers in maps a condition named name, returning
+// SUCCESS when fn returns true and FAILURE otherwise.
+func RegisterCondition(maps *b3.RegisterStructMaps, name string, fn ConditionFunc) {
+	RegisterNodeFactory(maps, name, func() IBaseNode {
+		return &funcCondition{fn: fn}
+	})
+}
+
+type funcAction struct {
+	Action
+	fn ActionFunc
+}
+
+func (this *funcAction) OnTick(tick *Tick) b3.Status {
+	return this.fn(tick, this.GetConfig())
+}
+
+type funcCondition struct {
+	Condition
+	fn ConditionFunc
+}
+
+func (this *funcCondition) OnTick(tick *Tick) b3.Status {
+	if this.fn(tick, this.GetConfig()) {
+		return b3.SUCCESS
+	}
+	return b3.FAILURE
+}
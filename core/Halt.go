@@ -0,0 +1,73 @@
+package core
+
+import (
+	b3 "github.com/youngtrips/behavior3go"
+)
+
+func isOpen(tick *Tick, node IBaseNode) bool {
+	return tick.Blackboard.GetBool("isOpen", tick.tree.id, node.GetID())
+}
+
+/**
+ * haltNode closes node if it is open, closing its open descendants first
+ * (deepest first, like the end of a tick does). It is used by parents that
+ * stop ticking a RUNNING child before it finished, so the child gets its
+ * OnClose and reopens on its next tick.
+**/
+func haltNode(tick *Tick, node IBaseNode) {
+	if node == nil || !isOpen(tick, node) {
+		return
+	}
+	switch node.GetCategory() {
+	case b3.COMPOSITE:
+		comp := node.(IComposite)
+		for i := comp.GetChildCount() - 1; i >= 0; i-- {
+			haltNode(tick, comp.GetChild(i))
+		}
+	case b3.DECORATOR:
+		haltNode(tick, node.(IDecorator).GetChild())
+	default:
+		for _, tree := range subtreesOf(node) {
+			haltNode(tick, tree.GetRoot())
+		}
+	}
+	if base := toBaseNode(node); base != nil {
+		base._close(tick)
+	}
+}
+
+// halt closes every node the tree left open on blackboard, deepest first,
+// so the next tick starts from the root.
+func (this *BehaviorTree) halt(target interface{}, blackboard *Blackboard) {
+	tick := NewTick()
+	tick.debug = this.debug
+	tick.target = target
+	tick.Blackboard = blackboard
+	tick.tree = this
+
+	data := blackboard._getTreeData(this.id)
+	haltNode(tick, this.root)
+	// nodes the walk cannot reach (a reloaded subtree) are closed directly
+	for i := len(data.OpenNodes) - 1; i >= 0; i-- {
+		if isOpen(tick, data.OpenNodes[i]) {
+			data.OpenNodes[i]._close(tick)
+		}
+	}
+	data.OpenNodes = nil
+	tick.flushDebugDraw()
+}
+
+/**
+ * Abort interrupts the tree for the agent of blackboard: the open nodes
+ * are closed, deepest first, with their OnClose called, then the memory
+ * of the tree and of its nodes is dropped, so the next tick starts from
+ * the root with fresh node memory. The global memory is left untouched.
+ *
+ * @method Abort
+ * @param {Object} target A target object.
+ * @param {Blackboard} blackboard An instance of blackboard object.
+**/
+func (this *BehaviorTree) Abort(target interface{}, blackboard *Blackboard) {
+	this.halt(target, blackboard)
+	blackboard.RemoveTreeScope(this.id)
+}
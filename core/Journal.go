@@ -0,0 +1,128 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+/**
+ * Journal records the changes of a blackboard, with their tick index,
+ * scopes and old and new values, for replays and "why did the AI do that"
+ * debugging. It is a ChangeSink keeping the last changes in a ring buffer
+ * and optionally writing every change as a JSON line:
+ *
+ *     journal := NewJournal(1024)
+ *     journal.SetWriter(file)
+ *     blackboard.SetChangeSink(journal)
+ *     ...
+ *     for _, change := range journal.Since(blackboard.TickCount() - 10) {
+ *         ...
+ *     }
+ *
+ * Use MultiSink to journal a blackboard already having a sink. Like the
+ * blackboard, it is not safe for concurrent use.
+ *
+ * @class Journal
+**/
+type Journal struct {
+	ring  []BlackboardChange
+	start int
+	count int
+
+	writer io.Writer
+	err    error
+}
+
+// NewJournal creates a journal keeping the last capacity changes; with
+// a capacity of 0 it only writes them.
+func NewJournal(capacity int) *Journal {
+	return &Journal{ring: make([]BlackboardChange, capacity)}
+}
+
+// SetWriter makes the journal write every change to w as a JSON line,
+// nil to stop. Values that cannot be marshalled are written formatted
+// with %v.
+func (this *Journal) SetWriter(w io.Writer) {
+	this.writer = w
+}
+
+// Err returns the first error of the writer; the journal stops writing
+// after it.
+func (this *Journal) Err() error {
+	return this.err
+}
+
+type journalLine struct {
+	Tick      uint64      `json:"tick"`
+	Key       string      `json:"key"`
+	TreeScope string      `json:"tree,omitempty"`
+	NodeScope string      `json:"node,omitempty"`
+	Old       interface{} `json:"old"`
+	New       interface{} `json:"new"`
+}
+
+func (this *Journal) Change(change BlackboardChange) {
+	if n := len(this.ring); n > 0 {
+		if this.count < n {
+			this.ring[(this.start+this.count)%n] = change
+			this.count++
+		} else {
+			this.ring[this.start] = change
+			this.start = (this.start + 1) % n
+		}
+	}
+	if this.writer != nil && this.err == nil {
+		this.write(change)
+	}
+}
+
+func (this *Journal) write(change BlackboardChange) {
+	line := journalLine{change.Tick, change.Key, change.TreeScope, change.NodeScope, change.Old, change.New}
+	data, err := json.Marshal(line)
+	if err != nil {
+		line.Old, line.New = fmt.Sprintf("%v", change.Old), fmt.Sprintf("%v", change.New)
+		data, _ = json.Marshal(line)
+	}
+	_, this.err = this.writer.Write(append(data, '\n'))
+}
+
+// Len returns the number of changes kept.
+func (this *Journal) Len() int {
+	return this.count
+}
+
+// Entries returns the changes kept, oldest first.
+func (this *Journal) Entries() []BlackboardChange {
+	entries := make([]BlackboardChange, 0, this.count)
+	for i := 0; i < this.count; i++ {
+		entries = append(entries, this.ring[(this.start+i)%len(this.ring)])
+	}
+	return entries
+}
+
+// Since returns the changes kept made from the tick index tick on.
+func (this *Journal) Since(tick uint64) []BlackboardChange {
+	var entries []BlackboardChange
+	for _, change := range this.Entries() {
+		if change.Tick >= tick {
+			entries = append(entries, change)
+		}
+	}
+	return entries
+}
+
+// Reset forgets the changes kept.
+func (this *Journal) Reset() {
+	this.start, this.count = 0, 0
+}
+
+// MultiSink is a ChangeSink forwarding changes to several sinks, in
+// order.
+type MultiSink []ChangeSink
+
+func (this MultiSink) Change(change BlackboardChange) {
+	for _, sink := range this {
+		sink.Change(change)
+	}
+}
@@ -0,0 +1,147 @@
+package core
+
+/**
+ * LazyStorage is a Storage able to read single entries. A blackboard
+ * created with one does not load everything in Initialize with Foreach:
+ * each key is fetched with Get the first time it is read in a scope, then
+ * cached, which suits large external stores. Writes still go through Set
+ * and Remove, and are not preceded by a fetch: watchers of a key written
+ * before being read see a nil old value.
+**/
+type LazyStorage interface {
+	Storage
+	Get(key string, treeScope string, nodeScope string) (value interface{}, ok bool)
+}
+
+// Flusher is implemented by storages buffering writes, see
+// Blackboard.Flush.
+type Flusher interface {
+	Flush()
+}
+
+// StorageOp is a buffered write of a WriteBehind storage.
+type StorageOp struct {
+	Key       string
+	TreeScope string
+	NodeScope string
+	Value     interface{}
+	Removed   bool
+}
+
+// BatchStorage is implemented by storages applying several writes at
+// once (a pipeline, a transaction...).
+type BatchStorage interface {
+	Apply(ops []StorageOp)
+}
+
+/**
+ * WriteBehind buffers the writes to a LazyStorage until Flush, keeping
+ * only the last write of each key, so a key written on every tick costs
+ * one store write per flush. Reads see the buffered writes. If the
+ * storage implements BatchStorage, a flush is a single Apply.
+ *
+ *     store := NewWriteBehind(remote)
+ *     blackboard := NewBlackboard(store)
+ *     ...
+ *     blackboard.Flush() // e.g. once per second
+ *
+ * Like the blackboard, it is not safe for concurrent use.
+ *
+ * @class WriteBehind
+**/
+type WriteBehind struct {
+	storage LazyStorage
+	pending map[watchKey]int
+	ops     []StorageOp
+}
+
+func NewWriteBehind(storage LazyStorage) *WriteBehind {
+	return &WriteBehind{storage: storage, pending: make(map[watchKey]int)}
+}
+
+func (this *WriteBehind) buffer(op StorageOp) {
+	k := watchKey{op.Key, op.TreeScope, op.NodeScope}
+	if i, ok := this.pending[k]; ok {
+		this.ops[i] = op
+		return
+	}
+	this.pending[k] = len(this.ops)
+	this.ops = append(this.ops, op)
+}
+
+func (this *WriteBehind) Set(key string, value interface{}, treeScope string, nodeScope string) {
+	this.buffer(StorageOp{Key: key, TreeScope: treeScope, NodeScope: nodeScope, Value: value})
+}
+
+func (this *WriteBehind) Remove(key string, treeScope string, nodeScope string) {
+	this.buffer(StorageOp{Key: key, TreeScope: treeScope, NodeScope: nodeScope, Removed: true})
+}
+
+func (this *WriteBehind) Get(key string, treeScope string, nodeScope string) (interface{}, bool) {
+	if i, ok := this.pending[watchKey{key, treeScope, nodeScope}]; ok {
+		op := this.ops[i]
+		return op.Value, !op.Removed
+	}
+	return this.storage.Get(key, treeScope, nodeScope)
+}
+
+// Foreach flushes, then lists the entries of the storage.
+func (this *WriteBehind) Foreach(f func(key string, value interface{}, treeScope string, nodeScope string)) {
+	this.Flush()
+	this.storage.Foreach(f)
+}
+
+// Pending returns the number of buffered writes.
+func (this *WriteBehind) Pending() int {
+	return len(this.ops)
+}
+
+// Flush writes the buffered writes to the storage.
+func (this *WriteBehind) Flush() {
+	if len(this.ops) == 0 {
+		return
+	}
+	ops := this.ops
+	this.ops = nil
+	this.pending = make(map[watchKey]int)
+	if batch, ok := this.storage.(BatchStorage); ok {
+		batch.Apply(ops)
+		return
+	}
+	for _, op := range ops {
+		if op.Removed {
+			this.storage.Remove(op.Key, op.TreeScope, op.NodeScope)
+		} else {
+			this.storage.Set(op.Key, op.Value, op.TreeScope, op.NodeScope)
+		}
+	}
+}
+
+// Flush flushes the writes buffered by the storage, if it buffers any.
+func (this *Blackboard) Flush() {
+	if f, ok := this._storage.(Flusher); ok {
+		f.Flush()
+	}
+}
+
+// fetch loads key from a lazy storage the first time it is read.
+func (this *Blackboard) fetch(memory *Memory, key, treeScope, nodeScope string) {
+	lazy, ok := this._storage.(LazyStorage)
+	if !ok {
+		return
+	}
+	k := watchKey{key, treeScope, nodeScope}
+	if this._fetched[k] {
+		return
+	}
+	if this._fetched == nil {
+		this._fetched = make(map[watchKey]bool)
+	}
+	this._fetched[k] = true
+	if _, ok := memory._memory[key]; ok {
+		return
+	}
+	if value, ok := lazy.Get(key, treeScope, nodeScope); ok {
+		memory.Set(key, value)
+	}
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	b3 "github.com/youngtrips/behavior3go"
+)
+
+/**
+ * Listener receives the events of the ticks of a tree, for tracing,
+ * debugging or analytics without touching the nodes. Register it with
+ * BehaviorTree.AddListener; embed BaseListener to implement only some of
+ * the methods. Node events come from every node executed, subtrees
+ * included, in execution order; as with IDebug, the node passed in is
+ * the BaseNode of the node. Listeners run on the ticking goroutine.
+ *
+ * @class Listener
+**/
+type Listener interface {
+	OnTreeTickStart(tick *Tick)
+	OnNodeEnter(tick *Tick, node IBaseNode)
+	// status returned by the node, before it is closed
+	OnNodeStatus(tick *Tick, node IBaseNode, status b3.Status)
+	OnNodeExit(tick *Tick, node IBaseNode)
+	OnTreeTickEnd(tick *Tick, status b3.Status)
+}
+
+// BaseListener implements Listener with methods doing nothing.
+type BaseListener struct{}
+
+func (BaseListener) OnTreeTickStart(tick *Tick)                                {}
+func (BaseListener) OnNodeEnter(tick *Tick, node IBaseNode)                    {}
+func (BaseListener) OnNodeStatus(tick *Tick, node IBaseNode, status b3.Status) {}
+func (BaseListener) OnNodeExit(tick *Tick, node IBaseNode)                     {}
+func (BaseListener) OnTreeTickEnd(tick *Tick, status b3.Status)                {}
+
+/**
+ * AddListener registers listener on the tree and returns a function
+ * removing it. Register listeners before ticking the tree from several
+ * goroutines: the list is not guarded.
+**/
+func (this *BehaviorTree) AddListener(listener Listener) (remove func()) {
+	this.listeners = append(this.listeners[:len(this.listeners):len(this.listeners)], listener)
+	return func() {
+		for i, l := range this.listeners {
+			if l == listener {
+				list := make([]Listener, 0, len(this.listeners)-1)
+				list = append(list, this.listeners[:i]...)
+				this.listeners = append(list, this.listeners[i+1:]...)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/youngtrips/behavior3go/config"
+)
+
+/**
+ * LoadError is the value BehaviorTree.Load panics with when a node cannot
+ * be built. Path locates the node from the root of the tree, e.g.
+ *
+ *     MainAI > Sequence 'Combat' > child#2 'ShootTarget'
+ *
+ * where child#N is the 1-based position of the node under its parent.
+ *
+ * @class LoadError
+**/
+type LoadError struct {
+	TreeID string
+	NodeID string
+	Path   string
+	Reason string
+}
+
+func (this *LoadError) Error() string {
+	return fmt.Sprintf("load %s: %s", this.Path, this.Reason)
+}
+
+func nodeLabel(spec *config.BTNodeCfg) string {
+	if spec.Title != "" && spec.Title != spec.Name {
+		return spec.Name + " '" + spec.Title + "'"
+	}
+	return spec.Name
+}
+
+/**
+ * NodePaths returns the path of every node of data, in the LoadError
+ * format. Nodes not reachable from the root are marked as detached.
+**/
+func NodePaths(data *config.BTTreeCfg) map[string]string {
+	paths := make(map[string]string, len(data.Nodes))
+	var build func(id string, parent string, step string)
+	build = func(id string, parent string, step string) {
+		spec, ok := data.Nodes[id]
+		if !ok {
+			return
+		}
+		if _, seen := paths[id]; seen {
+			return
+		}
+		label := nodeLabel(&spec)
+		if step != "" {
+			title := spec.Title
+			if title == "" {
+				title = spec.Name
+			}
+			label = step + " '" + title + "'"
+		}
+		path := parent + " > " + label
+		paths[id] = path
+
+		// children show as child#N under the Name 'Title' of their parent
+		parentPath := parent + " > " + nodeLabel(&spec)
+		if spec.Child != "" {
+			build(spec.Child, parentPath, "child#1")
+		}
+		for i, child := range spec.Children {
+			build(child, parentPath, "child#"+strconv.Itoa(i+1))
+		}
+	}
+	build(data.Root, data.Title, "")
+	for id, spec := range data.Nodes {
+		if _, ok := paths[id]; !ok {
+			paths[id] = data.Title + " > (detached) " + nodeLabel(&spec)
+		}
+	}
+	return paths
+}
+
+func (this *BehaviorTree) loadError(paths map[string]string, id string, reason string) *LoadError {
+	path, ok := paths[id]
+	if !ok {
+		path = this.title + " > " + id
+	}
+	return &LoadError{TreeID: this.dumpInfo.ID, NodeID: id, Path: path, Reason: reason}
+}
@@ -0,0 +1,130 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// LogLevel is the severity of a log message.
+type LogLevel int
+
+const (
+	LevelDebug LogLevel = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (this LogLevel) String() string {
+	switch this {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	}
+	return fmt.Sprintf("LogLevel(%d)", int(this))
+}
+
+// ParseLogLevel parses "debug", "info", "warn" or "error", in any case.
+func ParseLogLevel(s string) (LogLevel, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q", s)
+}
+
+/**
+ * Logger receives the messages of a tree: the Log actions and Print. The
+ * arguments are formatted as with fmt.Sprint. Adapters to logrus, zap or
+ * slog are a few lines each.
+ *
+ * @class Logger
+**/
+type Logger interface {
+	Debug(args ...interface{})
+	Info(args ...interface{})
+	Warn(args ...interface{})
+	Error(args ...interface{})
+}
+
+// LogAt sends the message to logger at level.
+func LogAt(logger Logger, level LogLevel, args ...interface{}) {
+	switch level {
+	case LevelDebug:
+		logger.Debug(args...)
+	case LevelInfo:
+		logger.Info(args...)
+	case LevelWarn:
+		logger.Warn(args...)
+	default:
+		logger.Error(args...)
+	}
+}
+
+// StdLogger writes the messages from a minimum level to a writer, one per
+// line prefixed by their level.
+type StdLogger struct {
+	mutex sync.Mutex
+	out   io.Writer
+	level LogLevel
+}
+
+func NewStdLogger(out io.Writer, level LogLevel) *StdLogger {
+	return &StdLogger{out: out, level: level}
+}
+
+func (this *StdLogger) SetLevel(level LogLevel) {
+	this.mutex.Lock()
+	this.level = level
+	this.mutex.Unlock()
+}
+
+func (this *StdLogger) write(level LogLevel, args []interface{}) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if level < this.level {
+		return
+	}
+	fmt.Fprintf(this.out, "[%s] %s\n", strings.ToUpper(level.String()), fmt.Sprint(args...))
+}
+
+func (this *StdLogger) Debug(args ...interface{}) { this.write(LevelDebug, args) }
+func (this *StdLogger) Info(args ...interface{})  { this.write(LevelInfo, args) }
+func (this *StdLogger) Warn(args ...interface{})  { this.write(LevelWarn, args) }
+func (this *StdLogger) Error(args ...interface{}) { this.write(LevelError, args) }
+
+// DefaultLogger is the logger of the trees without one, writing every
+// level to the standard output.
+var DefaultLogger Logger = NewStdLogger(os.Stdout, LevelDebug)
+
+// SetLogger sets the logger of the tree, nil for DefaultLogger.
+func (this *BehaviorTree) SetLogger(logger Logger) {
+	this.logger = logger
+}
+
+// Logger returns the logger of the tree.
+func (this *BehaviorTree) Logger() Logger {
+	if this.logger == nil {
+		return DefaultLogger
+	}
+	return this.logger
+}
+
+// Logger returns the logger of the tree being ticked.
+func (this *Tick) Logger() Logger {
+	return this.tree.Logger()
+}
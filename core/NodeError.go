@@ -0,0 +1,73 @@
+package core
+
+import (
+	"fmt"
+
+	b3 "github.com/youngtrips/behavior3go"
+)
+
+// NodeError is an error raised by a node during a tick, see Tick.SetError.
+type NodeError struct {
+	TreeID string
+	NodeID string
+	Name   string
+	Title  string
+	// subtrees entered to reach the node, see Tick.SubTreePath
+	Path string
+	Err  error
+}
+
+func (this *NodeError) Error() string {
+	return fmt.Sprintf("%s: node %s (%s %q): %v", this.Path, this.NodeID, this.Name, this.Title, this.Err)
+}
+
+func (this *NodeError) Unwrap() error {
+	return this.Err
+}
+
+/**
+ * SetError records err as raised by node, for BehaviorTree.TickErr to
+ * return; nodes returning ERROR call it to say why. Only the first error
+ * of a tick is kept, the root cause being usually the deepest node.
+**/
+func (this *Tick) SetError(node IBaseNode, err error) {
+	if err == nil || this._err != nil {
+		return
+	}
+	this._err = &NodeError{
+		TreeID: this.tree.id,
+		NodeID: node.GetID(),
+		Name:   node.GetName(),
+		Title:  node.GetTitle(),
+		Path:   this.SubTreePath(),
+		Err:    err,
+	}
+}
+
+// Fail records err for node and returns ERROR:
+//
+//	return tick.Fail(this, err)
+func (this *Tick) Fail(node IBaseNode, err error) b3.Status {
+	this.SetError(node, err)
+	return b3.ERROR
+}
+
+// Err returns the error recorded during the tick so far, nil if none.
+func (this *Tick) Err() error {
+	if this._err == nil {
+		return nil
+	}
+	return this._err
+}
+
+/**
+ * TickErr is Tick returning as well the error recorded by a node during
+ * the tick (a *NodeError), nil if none was.
+ *
+ * @method TickErr
+ * @param {Object} target A target object.
+ * @param {Blackboard} blackboard An instance of blackboard object.
+**/
+func (this *BehaviorTree) TickErr(target interface{}, blackboard *Blackboard) (b3.Status, error) {
+	return this.tick(target, blackboard, 0)
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"time"
+
+	b3 "github.com/youngtrips/behavior3go"
+)
+
+// ProfileSample is one tick of a subtree measured by a Profiler decorator.
+type ProfileSample struct {
+	// title of the Profiler node, or its name property
+	Name   string
+	TreeID string
+	NodeID string
+	// wall time of the tick of the subtree
+	Duration time.Duration
+	// nodes entered in the subtree, the Profiler excluded
+	Nodes  int
+	Status b3.Status
+}
+
+/**
+ * ProfileStats accumulates the samples of a subtree for an agent; the
+ * Profiler decorator keeps them on the blackboard.
+**/
+type ProfileStats struct {
+	Ticks int64
+	Nodes int64
+	Total time.Duration
+	Last  time.Duration
+	Max   time.Duration
+}
+
+// Add accounts for sample.
+func (this *ProfileStats) Add(sample ProfileSample) {
+	this.Ticks++
+	this.Nodes += int64(sample.Nodes)
+	this.Total += sample.Duration
+	this.Last = sample.Duration
+	if sample.Duration > this.Max {
+		this.Max = sample.Duration
+	}
+}
+
+// Mean returns the mean duration of a tick of the subtree.
+func (this ProfileStats) Mean() time.Duration {
+	if this.Ticks == 0 {
+		return 0
+	}
+	return this.Total / time.Duration(this.Ticks)
+}
+
+// ProfileSink receives the samples of the Profiler nodes of a tree, e.g.
+// to feed a metrics system, see SetProfileSink.
+type ProfileSink interface {
+	Sample(sample ProfileSample)
+}
+
+// ProfileSinkFunc adapts a function to ProfileSink.
+type ProfileSinkFunc func(sample ProfileSample)
+
+func (f ProfileSinkFunc) Sample(sample ProfileSample) {
+	f(sample)
+}
+
+// SetProfileSink sends the samples of the Profiler nodes of the tree to
+// sink, nil to stop; it is called on the ticking goroutine.
+func (this *BehaviorTree) SetProfileSink(sink ProfileSink) {
+	this.profileSink = sink
+}
+
+func (this *BehaviorTree) ProfileSink() ProfileSink {
+	return this.profileSink
+}
@@ -0,0 +1,35 @@
+package core
+
+import (
+	b3 "github.com/youngtrips/behavior3go"
+)
+
+/**
+ * SetResult attaches a payload to the status node returns in this tick,
+ * for its parent to read with Result right after executing it; e.g. the
+ * Priority composite reports the index of the child that did not fail.
+ * Payloads only live for the tick and are dropped when the node is
+ * entered again, so keep them small and do not rely on them across ticks
+ * (use the blackboard for that).
+**/
+func (this *Tick) SetResult(node IBaseNode, value interface{}) {
+	if this._results == nil {
+		this._results = make(map[string]interface{})
+	}
+	this._results[node.GetID()] = value
+}
+
+// Result returns the payload node attached to its last status in this
+// tick, false if it attached none.
+func (this *Tick) Result(node IBaseNode) (interface{}, bool) {
+	value, ok := this._results[node.GetID()]
+	return value, ok
+}
+
+// ExecuteResult executes node and returns its status with its payload,
+// nil if it attached none.
+func (this *Tick) ExecuteResult(node IBaseNode) (b3.Status, interface{}) {
+	status := node.Execute(this)
+	value, _ := this.Result(node)
+	return status, value
+}
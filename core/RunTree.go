@@ -0,0 +1,89 @@
+package core
+
+import (
+	"fmt"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+)
+
+var runTreeRegistry *TreeRegistry
+
+/**
+ * RunTree runs another loaded tree, looked up by id or title with
+ * TreeRegistry.Find in the registry given to UseForRunTree (or with the
+ * function of SetSubTreeLoadFunc without one), and returns its status:
+ *
+ *     registry.Register("flee", fleeTree)
+ *     registry.UseForRunTree()
+ *     // node {"name": "RunTree", "properties": {"tree": "flee"}}
+ *
+ * Like SubTree, the tree runs within the tick of the calling tree: its
+ * open nodes are tracked by that tick, so they are closed when the branch
+ * stops running and halted with it, and its nodes keep their memory under
+ * the id of the calling tree. The tree is looked up on every tick, so a
+ * reloaded tree is picked up; a tree missing or already running in the
+ * subtree stack (recursion) makes the node return ERROR, reported with
+ * Tick.SetError.
+ *
+ * @module b3
+ * @class RunTree
+ * @extends Action
+**/
+type RunTree struct {
+	Action
+	tree string
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **tree** (*String*) Id or title of the tree to run.
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *RunTree) Initialize(setting *BTNodeCfg) {
+	this.Action.Initialize(setting)
+	this.tree = setting.GetPropertyAsString("tree")
+	if this.tree == "" {
+		panic("tree parameter in RunTree action is an obligatory parameter")
+	}
+}
+
+// Tree returns the tree the node runs, nil if it is not loaded.
+func (this *RunTree) Tree() *BehaviorTree {
+	if runTreeRegistry != nil {
+		return runTreeRegistry.Find(this.tree)
+	}
+	if subTreeLoadFunc != nil {
+		return subTreeLoadFunc(this.tree)
+	}
+	return nil
+}
+
+func (this *RunTree) OnTick(tick *Tick) b3.Status {
+	tree := this.Tree()
+	if tree == nil || tree.GetRoot() == nil {
+		return tick.Fail(this, fmt.Errorf("tree %q not loaded", this.tree))
+	}
+	for _, frame := range tick.SubTreeStack() {
+		if frame.Tree == tree {
+			return tick.Fail(this, fmt.Errorf("tree %q runs itself", this.tree))
+		}
+	}
+	tick.pushSubtreeNode(this, tree)
+	status := tree.GetRoot().Execute(tick)
+	tick.popSubtreeNode()
+	return status
+}
+
+func (this *RunTree) subtrees() []*BehaviorTree {
+	if tree := this.Tree(); tree != nil {
+		return []*BehaviorTree{tree}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SchemaError reports a blackboard value not matching its declared type.
+type SchemaError struct {
+	Key  string
+	Want string
+	Got  string
+}
+
+func (this *SchemaError) Error() string {
+	return fmt.Sprintf("blackboard key %q: declared %s, got %s", this.Key, this.Want, this.Got)
+}
+
+var schemaTypes = map[string]func(v interface{}) bool{
+	"any":     func(v interface{}) bool { return true },
+	"bool":    func(v interface{}) bool { _, ok := v.(bool); return ok },
+	"string":  func(v interface{}) bool { _, ok := v.(string); return ok },
+	"int":     func(v interface{}) bool { _, ok := v.(int); return ok },
+	"int32":   func(v interface{}) bool { _, ok := v.(int32); return ok },
+	"int64":   func(v interface{}) bool { _, ok := v.(int64); return ok },
+	"uint64":  func(v interface{}) bool { _, ok := v.(uint64); return ok },
+	"float":   func(v interface{}) bool { _, ok := v.(float64); return ok },
+	"float64": func(v interface{}) bool { _, ok := v.(float64); return ok },
+	"number":  func(v interface{}) bool { _, ok := CoerceFloat64(v); return ok && !isString(v) },
+	"list":    func(v interface{}) bool { return reflect.TypeOf(v).Kind() == reflect.Slice },
+	"map":     func(v interface{}) bool { return reflect.TypeOf(v).Kind() == reflect.Map },
+}
+
+func isString(v interface{}) bool {
+	_, ok := v.(string)
+	return ok
+}
+
+/**
+ * Schema declares the global blackboard keys a tree expects, with their
+ * types: any, bool, string, int, int32, int64, uint64, float (float64),
+ * number (any numeric type), list or map. It is read from the "schema"
+ * property of a tree, an object mapping keys to types:
+ *
+ *     "properties": {"schema": {"hp": "int", "target": "string"}}
+ *
+ * Load rejects unknown types. Blackboard.AddSchema then checks every
+ * write of a declared global key, and Validate the content of a
+ * blackboard.
+ *
+ * @class Schema
+**/
+type Schema struct {
+	types map[string]string
+}
+
+func NewSchema() *Schema {
+	return &Schema{types: make(map[string]string)}
+}
+
+// ParseSchema builds a schema from the "schema" property of a tree.
+func ParseSchema(property interface{}) (*Schema, error) {
+	decl, ok := property.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("schema must be an object mapping keys to types")
+	}
+	schema := NewSchema()
+	for key, typ := range decl {
+		name, ok := typ.(string)
+		if !ok {
+			return nil, fmt.Errorf("schema key %q: type must be a string", key)
+		}
+		if err := schema.Declare(key, name); err != nil {
+			return nil, err
+		}
+	}
+	return schema, nil
+}
+
+// Declare declares key with the type typ; declaring a key twice with
+// different types fails.
+func (this *Schema) Declare(key, typ string) error {
+	typ = strings.ToLower(strings.TrimSpace(typ))
+	if _, ok := schemaTypes[typ]; !ok {
+		return fmt.Errorf("schema key %q: unknown type %q", key, typ)
+	}
+	if old, ok := this.types[key]; ok && old != typ {
+		return fmt.Errorf("schema key %q: declared %s and %s", key, old, typ)
+	}
+	this.types[key] = typ
+	return nil
+}
+
+// Type returns the declared type of key.
+func (this *Schema) Type(key string) (string, bool) {
+	typ, ok := this.types[key]
+	return typ, ok
+}
+
+// Keys returns the declared keys, sorted.
+func (this *Schema) Keys() []string {
+	keys := make([]string, 0, len(this.types))
+	for key := range this.types {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Merge adds the declarations of other, failing on conflicting ones.
+func (this *Schema) Merge(other *Schema) error {
+	for key, typ := range other.types {
+		if err := this.Declare(key, typ); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check tells whether value may be stored in key; nil (a removal) and
+// undeclared keys always may.
+func (this *Schema) Check(key string, value interface{}) error {
+	typ, ok := this.types[key]
+	if !ok || value == nil || schemaTypes[typ](value) {
+		return nil
+	}
+	return &SchemaError{Key: key, Want: typ, Got: reflect.TypeOf(value).String()}
+}
+
+// Validate checks the declared keys present in the global memory of
+// blackboard; missing keys are not errors.
+func (this *Schema) Validate(blackboard *Blackboard) []error {
+	var errs []error
+	for _, key := range this.Keys() {
+		if err := this.Check(key, blackboard.GetMem(key)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Schema returns the schema declared by the tree, nil if none.
+func (this *BehaviorTree) Schema() *Schema {
+	return this.schema
+}
+
+/**
+ * AddSchema makes the blackboard check the writes of global keys against
+ * schema (merged with the schemas added before, e.g. one per tree the
+ * agent runs). A mismatch is reported to the handler set with
+ * SetSchemaHandler, which panics with the *SchemaError by default; the
+ * value is stored anyway when the handler returns.
+**/
+func (this *Blackboard) AddSchema(schema *Schema) error {
+	if schema == nil {
+		return nil
+	}
+	if this._schema == nil {
+		this._schema = NewSchema()
+	}
+	return this._schema.Merge(schema)
+}
+
+// SetSchemaHandler sets the function called on a schema mismatch, nil to
+// panic.
+func (this *Blackboard) SetSchemaHandler(f func(err *SchemaError)) {
+	this._schemaHandler = f
+}
+
+func (this *Blackboard) checkSchema(key string, value interface{}) {
+	err := this._schema.Check(key, value)
+	if err == nil {
+		return
+	}
+	if this._schemaHandler == nil {
+		panic(err)
+	}
+	this._schemaHandler(err.(*SchemaError))
+}
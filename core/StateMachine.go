@@ -0,0 +1,256 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+)
+
+// StateCondition decides whether a StateMachine transition fires.
+type StateCondition func(tick *Tick) bool
+
+type stateDef struct {
+	name     string
+	treeName string
+	tree     *BehaviorTree
+	final    bool
+}
+
+type stateTransition struct {
+	from string // "*" for any state
+	to   string
+	cond StateCondition
+}
+
+/**
+ * StateMachine hosts a small state machine in a tree: every state runs a
+ * tree, like a SubTree, and transitions move between states when a
+ * condition holds. States may themselves contain state machines, which
+ * makes it hierarchical. It suits locomotion or stance logic that is
+ * awkward to express with composites.
+ *
+ * The machine starts in the initial state when the node opens. On each
+ * tick the transitions of the current state are tried in order and the
+ * first one firing switches state, halting the tree of the state left;
+ * then the tree of the current state is ticked. The node stays RUNNING,
+ * except in a final state where it returns the status of its tree once
+ * that is not RUNNING. An ERROR of a state tree is returned as is.
+ *
+ * The state is kept in the node memory ("state", and "previousState"
+ * after a transition).
+ *
+ * States and transitions come from the properties or are added in Go by a
+ * node embedding StateMachine, from its Initialize:
+ *
+ *     func (this *Stance) Initialize(setting *BTNodeCfg) {
+ *         this.StateMachine.Initialize(setting)
+ *         this.AddState("stand", standTree)
+ *         this.AddState("crouch", crouchTree)
+ *         this.AddTransition("stand", "crouch", func(tick *Tick) bool { ... })
+ *     }
+ *
+ * @module b3
+ * @class StateMachine
+ * @extends Action
+**/
+type StateMachine struct {
+	Action
+	states      []*stateDef
+	transitions []stateTransition
+	initial     string
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **states**      (*String*) Comma separated states, "name" running the
+ *                              tree of that name or "name=tree".
+ * - **initial**     (*String*) Initial state, the first one by default.
+ * - **final**       (*String*) Comma separated final states.
+ * - **transitions** (*String*) Comma separated "from>to:key" transitions,
+ *                              firing while the global key holds true
+ *                              ("!key" while it does not); from "*"
+ *                              matches any state.
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *StateMachine) Initialize(setting *BTNodeCfg) {
+	this.Action.Initialize(setting)
+	for _, s := range splitProperty(setting, "states") {
+		name, tree := s, s
+		if i := strings.Index(s, "="); i >= 0 {
+			name, tree = strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:])
+		}
+		this.states = append(this.states, &stateDef{name: name, treeName: tree})
+	}
+	for _, s := range splitProperty(setting, "final") {
+		if state := this.state(s); state != nil {
+			state.final = true
+		}
+	}
+	for _, s := range splitProperty(setting, "transitions") {
+		i, j := strings.Index(s, ">"), strings.Index(s, ":")
+		if i < 0 || j < i {
+			panic(fmt.Sprintf("StateMachine: invalid transition %q", s))
+		}
+		this.AddTransition(strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:j]), keyCondition(strings.TrimSpace(s[j+1:])))
+	}
+	if _, ok := setting.Properties["initial"]; ok {
+		this.initial = setting.GetPropertyAsString("initial")
+	}
+}
+
+func splitProperty(setting *BTNodeCfg, name string) []string {
+	if _, ok := setting.Properties[name]; !ok {
+		return nil
+	}
+	var values []string
+	for _, v := range strings.Split(setting.GetPropertyAsString(name), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func keyCondition(key string) StateCondition {
+	negate := strings.HasPrefix(key, "!")
+	key = strings.TrimPrefix(key, "!")
+	return func(tick *Tick) bool {
+		v, _ := tick.Blackboard.GetMem(key).(bool)
+		return v != negate
+	}
+}
+
+// AddState adds a state running tree; the first state added is the
+// initial one unless SetInitial says otherwise.
+func (this *StateMachine) AddState(name string, tree *BehaviorTree) {
+	if state := this.state(name); state != nil {
+		state.tree = tree
+		return
+	}
+	this.states = append(this.states, &stateDef{name: name, tree: tree})
+}
+
+// SetFinal marks a state as final.
+func (this *StateMachine) SetFinal(name string) {
+	if state := this.state(name); state != nil {
+		state.final = true
+	}
+}
+
+func (this *StateMachine) SetInitial(name string) {
+	this.initial = name
+}
+
+// AddTransition adds a transition from a state ("*" for any) to another,
+// tried after the ones added before it.
+func (this *StateMachine) AddTransition(from, to string, cond StateCondition) {
+	this.transitions = append(this.transitions, stateTransition{from: from, to: to, cond: cond})
+}
+
+// Validate checks the states and transitions, when the tree is loaded.
+func (this *StateMachine) Validate() error {
+	if len(this.states) == 0 {
+		return fmt.Errorf("state machine without states")
+	}
+	if this.initial != "" && this.state(this.initial) == nil {
+		return fmt.Errorf("unknown initial state %q", this.initial)
+	}
+	for _, t := range this.transitions {
+		if t.from != "*" && this.state(t.from) == nil {
+			return fmt.Errorf("transition from unknown state %q", t.from)
+		}
+		if this.state(t.to) == nil {
+			return fmt.Errorf("transition to unknown state %q", t.to)
+		}
+	}
+	return nil
+}
+
+func (this *StateMachine) state(name string) *stateDef {
+	for _, state := range this.states {
+		if state.name == name {
+			return state
+		}
+	}
+	return nil
+}
+
+func (this *StateMachine) stateTree(state *stateDef) *BehaviorTree {
+	if state.tree != nil {
+		return state.tree
+	}
+	if subTreeLoadFunc != nil {
+		return subTreeLoadFunc(state.treeName)
+	}
+	return nil
+}
+
+func (this *StateMachine) subtrees() []*BehaviorTree {
+	var trees []*BehaviorTree
+	for _, state := range this.states {
+		if tree := this.stateTree(state); tree != nil {
+			trees = append(trees, tree)
+		}
+	}
+	return trees
+}
+
+// CurrentState returns the state the machine is in for tick, "" when the
+// node is not open.
+func (this *StateMachine) CurrentState(tick *Tick) string {
+	name, _ := tick.Blackboard.Get("state", tick.tree.id, this.id).(string)
+	return name
+}
+
+func (this *StateMachine) OnOpen(tick *Tick) {
+	initial := this.initial
+	if initial == "" && len(this.states) > 0 {
+		initial = this.states[0].name
+	}
+	tick.Blackboard.Set("state", initial, tick.tree.id, this.id)
+	tick.Blackboard.Set("previousState", nil, tick.tree.id, this.id)
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (this *StateMachine) OnTick(tick *Tick) b3.Status {
+	current := this.state(this.CurrentState(tick))
+	if current == nil {
+		return b3.ERROR
+	}
+	for _, t := range this.transitions {
+		if (t.from == current.name || t.from == "*") && t.to != current.name && t.cond(tick) {
+			if tree := this.stateTree(current); tree != nil {
+				haltNode(tick, tree.GetRoot())
+			}
+			tick.Blackboard.Set("previousState", current.name, tick.tree.id, this.id)
+			tick.Blackboard.Set("state", t.to, tick.tree.id, this.id)
+			current = this.state(t.to)
+			break
+		}
+	}
+
+	tree := this.stateTree(current)
+	if tree == nil {
+		return b3.ERROR
+	}
+	tick.pushSubtreeNode(this, tree)
+	status := tree.GetRoot().Execute(tick)
+	tick.popSubtreeNode()
+	if status == b3.ERROR || (current.final && status != b3.RUNNING) {
+		return status
+	}
+	return b3.RUNNING
+}
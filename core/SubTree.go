@@ -8,7 +8,8 @@ import (
 //子树，通过Name关联树ID查找
 type SubTree struct {
 	Action
-	//tree *BehaviorTree
+	// bound by SetTree, else looked up by name
+	tree *BehaviorTree
 }
 
 func (this *SubTree) Initialize(setting *BTNodeCfg) {
@@ -22,9 +23,9 @@ func (this *SubTree) Initialize(setting *BTNodeCfg) {
 **/
 func (this *SubTree) OnTick(tick *Tick) b3.Status {
 
-	//使用子树，必须先SetSubTreeLoadFunc
+	//使用子树，必须先SetSubTreeLoadFunc或SetTree
 	//子树可能没有加载上来，所以要延迟加载执行
-	sTree := subTreeLoadFunc(this.GetName())
+	sTree := this.Tree()
 	if nil == sTree {
 		return b3.ERROR
 	}
@@ -36,7 +37,7 @@ func (this *SubTree) OnTick(tick *Tick) b3.Status {
 	//tar := tick.GetTarget()
 	//return sTree.Tick(tar, tick.Blackboard)
 
-	tick.pushSubtreeNode(this)
+	tick.pushSubtreeNode(this, sTree)
 	ret := sTree.GetRoot().Execute(tick)
 	tick.popSubtreeNode()
 	return ret
@@ -46,10 +47,70 @@ func (this *SubTree) String() string  {
 	return "SBT_"+this.GetTitle()
 }
 
+// SetTree binds the node to tree, which it then runs instead of looking
+// its name up with the function set by SetSubTreeLoadFunc.
+func (this *SubTree) SetTree(tree *BehaviorTree) {
+	this.tree = tree
+}
+
+// Tree returns the tree the node runs, nil if it is not loaded.
+func (this *SubTree) Tree() *BehaviorTree {
+	if this.tree != nil {
+		return this.tree
+	}
+	if subTreeLoadFunc == nil {
+		return nil
+	}
+	return subTreeLoadFunc(this.GetName())
+}
+
+func (this *SubTree) subtrees() []*BehaviorTree {
+	if tree := this.Tree(); tree != nil {
+		return []*BehaviorTree{tree}
+	}
+	return nil
+}
+
+/**
+ * BindSubTrees binds the SubTree nodes of the tree, not of its subtrees,
+ * to the trees find returns for their names (see SubTree.SetTree), and
+ * returns how many it bound. Nodes find returns nil for are left as is.
+**/
+func (this *BehaviorTree) BindSubTrees(find func(name string) *BehaviorTree) int {
+	bound := 0
+	eachSubTree(this.root, func(sub *SubTree) {
+		if tree := find(sub.GetName()); tree != nil {
+			sub.SetTree(tree)
+			bound++
+		}
+	})
+	return bound
+}
+
+// eachSubTree calls f with the SubTree nodes under node, without entering
+// their trees.
+func eachSubTree(node IBaseNode, f func(sub *SubTree)) {
+	if node == nil {
+		return
+	}
+	switch node.GetCategory() {
+	case b3.COMPOSITE:
+		comp := node.(IComposite)
+		for i := 0; i < comp.GetChildCount(); i++ {
+			eachSubTree(comp.GetChild(i), f)
+		}
+	case b3.DECORATOR:
+		eachSubTree(node.(IDecorator).GetChild(), f)
+	default:
+		if sub, ok := node.(*SubTree); ok {
+			f(sub)
+		}
+	}
+}
 
 var subTreeLoadFunc func(string) *BehaviorTree
 
 //获取子树的方法
 func SetSubTreeLoadFunc(f func(string) *BehaviorTree) {
 	subTreeLoadFunc = f
-}
\ No newline at end of file
+}
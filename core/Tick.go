@@ -2,6 +2,10 @@ package core
 
 import (
 	_ "fmt"
+	"strings"
+	"time"
+
+	b3 "github.com/youngtrips/behavior3go"
 )
 
 /**
@@ -59,7 +63,29 @@ type Tick struct {
 	 * push subtree node before execute subtree.
 	 * pop subtree node after execute subtree.
 	**/
-	_openSubtreeNodes []*SubTree
+	_openSubtreeNodes []IBaseNode
+	_openSubtrees     []*BehaviorTree
+
+	// time elapsed since the previous tick and simulation time, see
+	// BehaviorTree.TickDelta; both 0 with Tick
+	DeltaTime time.Duration
+	SimTime   time.Duration
+
+	// first error of the tick, see SetError
+	_err *NodeError
+
+	// outcome of IConcurrentWork, see WorkResult
+	_work map[string]workResult
+
+	// statuses of the abort guards evaluated during the tick, see
+	// GuardPasses
+	_guards map[string]b3.Status
+
+	// see BehaviorTree.AddListener
+	listeners []Listener
+
+	// payloads of the statuses returned during the tick, see SetResult
+	_results map[string]interface{}
 
 	/**
 	 * The number of nodes entered during the tick. Update during the tree
@@ -70,6 +96,10 @@ type Tick struct {
 	 * @readOnly
 	**/
 	_nodeCount int
+
+	// debug draw bookkeeping, see DebugDraw
+	_drawn       map[string]bool
+	_closedDraws []DrawPrimitive
 }
 
 func NewTick() *Tick {
@@ -93,9 +123,20 @@ func (this *Tick) Initialize() {
 	// updated during the tick signal
 	this._openNodes = nil
 	this._openSubtreeNodes = nil
+	this._openSubtrees = nil
+	this._results = nil
+	this.listeners = nil
+	this._err = nil
+	this._work = nil
+	this._guards = nil
 	this._nodeCount = 0
 }
 
+// NodeCount returns the number of nodes entered so far during the tick.
+func (this *Tick) NodeCount() int {
+	return this._nodeCount
+}
+
 func (this *Tick) GetTree() *BehaviorTree {
 	return this.tree
 }
@@ -109,8 +150,14 @@ func (this *Tick) GetTree() *BehaviorTree {
 func (this *Tick) _enterNode(node IBaseNode) {
 	this._nodeCount++
 	this._openNodes = append(this._openNodes, node)
+	delete(this._results, node.GetID())
 
-	// TODO: call debug here
+	if debug, ok := this.debug.(IDebug); ok {
+		debug.EnterNode(this, node)
+	}
+	for _, l := range this.listeners {
+		l.OnNodeEnter(this, node)
+	}
 }
 
 /**
@@ -120,18 +167,26 @@ func (this *Tick) _enterNode(node IBaseNode) {
  * @protected
 **/
 func (this *Tick) _openNode(node *BaseNode) {
-	// TODO: call debug here
+	if debug, ok := this.debug.(IDebug); ok {
+		debug.OpenNode(this, node)
+	}
 }
 
 /**
  * Callback when ticking a node (called by BaseNode).
  * @method _tickNode
  * @param {Object} node The node that called this method.
+ * @param {Constant} status The status returned by the node.
  * @protected
 **/
-func (this *Tick) _tickNode(node *BaseNode) {
-	// TODO: call debug here
+func (this *Tick) _tickNode(node *BaseNode, status b3.Status) {
 	//fmt.Println("Tick _tickNode :", this.debug, " id:", node.GetID(), node.GetTitle())
+	if debug, ok := this.debug.(IDebug); ok {
+		debug.TickNode(this, node, status)
+	}
+	for _, l := range this.listeners {
+		l.OnNodeStatus(this, node, status)
+	}
 }
 
 /**
@@ -141,38 +196,81 @@ func (this *Tick) _tickNode(node *BaseNode) {
  * @protected
 **/
 func (this *Tick) _closeNode(node *BaseNode) {
-	// TODO: call debug here
-
-	ulen := len(this._openNodes)
-	if ulen > 0 {
-		this._openNodes = this._openNodes[:ulen-1]
+	if debug, ok := this.debug.(IDebug); ok {
+		debug.CloseNode(this, node)
 	}
+	this.clearDebugDraw(node)
 
+	// a node halted by its parent (see haltNode) may not be the last one,
+	// or not be on the list at all if it was not entered during this tick
+	for i := len(this._openNodes) - 1; i >= 0; i-- {
+		if this._openNodes[i] == node {
+			this._openNodes = append(this._openNodes[:i], this._openNodes[i+1:]...)
+			break
+		}
+	}
 }
 
-func (this *Tick) pushSubtreeNode(node *SubTree) {
+func (this *Tick) pushSubtreeNode(node IBaseNode, tree *BehaviorTree) {
 	this._openSubtreeNodes = append(this._openSubtreeNodes, node)
+	this._openSubtrees = append(this._openSubtrees, tree)
 }
 func (this *Tick) popSubtreeNode() {
 	ulen := len(this._openSubtreeNodes)
 	if ulen > 0 {
 		this._openSubtreeNodes = this._openSubtreeNodes[:ulen-1]
+		this._openSubtrees = this._openSubtrees[:ulen-1]
 	}
 }
 
 /**
  * return top subtree node.
- * return nil when it is runing at major tree
+ * return nil when it is runing at major tree (or in a StateMachine state)
  *
 **/
 func (this *Tick) GetLastSubTree() *SubTree {
 	ulen := len(this._openSubtreeNodes)
 	if ulen > 0 {
-		return this._openSubtreeNodes[ulen-1]
+		sub, _ := this._openSubtreeNodes[ulen-1].(*SubTree)
+		return sub
 	}
 	return nil
 }
 
+// SubTreeFrame is one level of the subtree stack of a tick.
+type SubTreeFrame struct {
+	// the node that entered the tree (SubTree, StateMachine), nil for the
+	// ticked tree
+	Node  IBaseNode
+	Tree  *BehaviorTree
+	Title string
+}
+
+/**
+ * SubTreeStack returns the trees being executed, outermost first: the
+ * ticked tree followed by every subtree entered to reach the current node.
+**/
+func (this *Tick) SubTreeStack() []SubTreeFrame {
+	stack := make([]SubTreeFrame, 0, len(this._openSubtrees)+1)
+	if this.tree != nil {
+		stack = append(stack, SubTreeFrame{Tree: this.tree, Title: this.tree.GetTitile()})
+	}
+	for i, tree := range this._openSubtrees {
+		stack = append(stack, SubTreeFrame{Node: this._openSubtreeNodes[i], Tree: tree, Title: tree.GetTitile()})
+	}
+	return stack
+}
+
+// SubTreePath returns the titles of SubTreeStack joined by " > ", e.g.
+// "MainAI > Combat > Flee".
+func (this *Tick) SubTreePath() string {
+	var path []string
+	for _, frame := range this.SubTreeStack() {
+		path = append(path, frame.Title)
+	}
+	return strings.Join(path, " > ")
+}
+
 /**
  * Callback when exiting a node (called by BaseNode).
  * @method _exitNode
@@ -180,7 +278,12 @@ func (this *Tick) GetLastSubTree() *SubTree {
  * @protected
 **/
 func (this *Tick) _exitNode(node *BaseNode) {
-	// TODO: call debug here
+	if debug, ok := this.debug.(IDebug); ok {
+		debug.ExitNode(this, node)
+	}
+	for _, l := range this.listeners {
+		l.OnNodeExit(this, node)
+	}
 }
 
 func (this *Tick) GetTarget() interface{} {
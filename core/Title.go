@@ -0,0 +1,81 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+/**
+ * RenderTitle resolves the placeholders of a node title from blackboard,
+ * so "Attack {targetName}" can read "Attack Goblin" in live views. A
+ * placeholder is looked up in the node memory, then the tree memory, then
+ * the global memory; unknown keys are left as is. "{{" and "}}" stand for
+ * literal braces. The blackboard is only read.
+ *
+ * @method RenderTitle
+ * @param {String} title The node title.
+ * @param {b3.Blackboard} blackboard The blackboard to read.
+ * @param {String} treeScope The tree id.
+ * @param {String} nodeScope The node id.
+**/
+func RenderTitle(title string, blackboard *Blackboard, treeScope, nodeScope string) string {
+	return RenderTemplate(title, blackboard, treeScope, nodeScope, nil)
+}
+
+/**
+ * RenderTemplate resolves the placeholders of template like RenderTitle,
+ * passing the printed values through escape when not nil, e.g.
+ * url.PathEscape for the URL of a request.
+**/
+func RenderTemplate(template string, blackboard *Blackboard, treeScope, nodeScope string, escape func(string) string) string {
+	if blackboard == nil || !strings.Contains(template, "{") {
+		return template
+	}
+	var out strings.Builder
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		if (c == '{' || c == '}') && i+1 < len(template) && template[i+1] == c {
+			out.WriteByte(c)
+			i++
+			continue
+		}
+		if c == '{' {
+			if end := strings.IndexByte(template[i+1:], '}'); end >= 0 {
+				key := template[i+1 : i+1+end]
+				if value, ok := blackboard.lookup(key, treeScope, nodeScope); ok {
+					if escape != nil {
+						out.WriteString(escape(fmt.Sprint(value)))
+					} else {
+						fmt.Fprint(&out, value)
+					}
+					i += end + 1
+					continue
+				}
+			}
+		}
+		out.WriteByte(c)
+	}
+	return out.String()
+}
+
+// RenderTitle resolves the title of node with the blackboard of the tick.
+func (this *Tick) RenderTitle(node IBaseNode) string {
+	return RenderTitle(node.GetTitle(), this.Blackboard, this.tree.GetID(), node.GetID())
+}
+
+// lookup reads key from the innermost scope holding it, without creating
+// memories.
+func (this *Blackboard) lookup(key, treeScope, nodeScope string) (interface{}, bool) {
+	if treeMem, ok := this._treeMemory[treeScope]; ok {
+		if nodeMem, ok := treeMem._nodeMemory[nodeScope]; ok {
+			if value, ok := nodeMem._memory[key]; ok {
+				return value, true
+			}
+		}
+		if value, ok := treeMem._memory[key]; ok {
+			return value, true
+		}
+	}
+	value, ok := this._baseMemory._memory[key]
+	return value, ok
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"sync"
+)
+
+/**
+ * TreePool hands out (tree, blackboard) pairs to spawned agents and takes
+ * them back on despawn, so waves of short lived NPCs reuse blackboards
+ * instead of allocating them:
+ *
+ *     pool := NewTreePool(tree, nil, 256)
+ *     tree, blackboard := pool.Acquire()
+ *     ...
+ *     pool.Release(npc, blackboard)
+ *
+ * Trees being stateless, every pair shares the tree of the pool. Release
+ * halts the nodes left open (their OnClose runs) and wipes the blackboard:
+ * global, tree and node memory, watchers and cooldowns, so nothing leaks
+ * to the next agent. Clock, seed, change sink and deep copy settings are
+ * kept. Blackboards backed by a Storage belong to one agent and should
+ * not be pooled.
+ *
+ * TreePool is safe for concurrent use.
+ *
+ * @class TreePool
+**/
+type TreePool struct {
+	tree          *BehaviorTree
+	newBlackboard func() *Blackboard
+	max           int
+
+	mutex sync.Mutex
+	free  []*Blackboard
+}
+
+// NewTreePool creates a pool for tree. newBlackboard creates the
+// blackboards (nil for NewBlackboard(nil)); at most max released
+// blackboards are kept, 0 for no limit.
+func NewTreePool(tree *BehaviorTree, newBlackboard func() *Blackboard, max int) *TreePool {
+	if newBlackboard == nil {
+		newBlackboard = func() *Blackboard {
+			return NewBlackboard(nil)
+		}
+	}
+	return &TreePool{tree: tree, newBlackboard: newBlackboard, max: max}
+}
+
+// Prewarm creates blackboards until n are available.
+func (this *TreePool) Prewarm(n int) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	for len(this.free) < n {
+		this.free = append(this.free, this.newBlackboard())
+	}
+}
+
+// Acquire returns the tree and a clean blackboard for a new agent.
+func (this *TreePool) Acquire() (*BehaviorTree, *Blackboard) {
+	this.mutex.Lock()
+	if n := len(this.free); n > 0 {
+		blackboard := this.free[n-1]
+		this.free[n-1] = nil
+		this.free = this.free[:n-1]
+		this.mutex.Unlock()
+		return this.tree, blackboard
+	}
+	this.mutex.Unlock()
+	return this.tree, this.newBlackboard()
+}
+
+// Release halts the tree of the despawned agent target, wipes blackboard
+// and returns it to the pool. blackboard must not be used afterwards.
+func (this *TreePool) Release(target interface{}, blackboard *Blackboard) {
+	this.tree.halt(target, blackboard)
+	blackboard.reset()
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if this.max <= 0 || len(this.free) < this.max {
+		this.free = append(this.free, blackboard)
+	}
+}
+
+// Available returns the number of blackboards waiting in the pool.
+func (this *TreePool) Available() int {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return len(this.free)
+}
+
+// reset forgets the data of the agent, keeping the settings.
+func (this *Blackboard) reset() {
+	this._baseMemory = NewMemory()
+	this._treeMemory = make(map[string]*TreeMemory)
+	this._watchers = nil
+	this._nodeWatches = nil
+	this._cooldowns = nil
+	this._tickCount = 0
+	this._simTime = 0
+	this._fetched = nil
+	this._events = nil
+}
@@ -0,0 +1,158 @@
+package core
+
+import (
+	"sort"
+	"sync"
+)
+
+/**
+ * MigrateFunc is called by TreeRegistry.Reload before a tree is replaced,
+ * so running state can be carried over or cleaned up.
+**/
+type MigrateFunc func(old *BehaviorTree, new *BehaviorTree)
+
+/**
+ * KeepTreeID is a MigrateFunc giving the new tree the id of the old one.
+ * Tree and node memories stored on blackboards stay attached to the tree,
+ * so agents keep their state across a reload as long as the node ids of
+ * the config did not change. Nodes left open by the old tree are closed on
+ * the first tick of the new one, like any node no longer running.
+**/
+func KeepTreeID(old *BehaviorTree, new *BehaviorTree) {
+	new.id = old.id
+}
+
+/**
+ * TreeRegistry holds the loaded trees by config id and lets them be
+ * swapped at runtime. It is safe for concurrent use: a tree obtained with
+ * Get stays valid (and usable) after being replaced.
+ *
+ * @class TreeRegistry
+**/
+type TreeRegistry struct {
+	mutex    sync.RWMutex
+	trees    map[string]*BehaviorTree
+	onReload []func(id string, old *BehaviorTree, new *BehaviorTree)
+}
+
+func NewTreeRegistry() *TreeRegistry {
+	return &TreeRegistry{trees: make(map[string]*BehaviorTree)}
+}
+
+// Register adds tree under id, replacing any tree with the same id without
+// migration.
+func (this *TreeRegistry) Register(id string, tree *BehaviorTree) {
+	this.mutex.Lock()
+	this.trees[id] = tree
+	this.mutex.Unlock()
+}
+
+func (this *TreeRegistry) Get(id string) *BehaviorTree {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+	return this.trees[id]
+}
+
+func (this *TreeRegistry) Remove(id string) {
+	this.mutex.Lock()
+	delete(this.trees, id)
+	this.mutex.Unlock()
+}
+
+// IDs returns the registered ids, sorted.
+func (this *TreeRegistry) IDs() []string {
+	this.mutex.RLock()
+	ids := make([]string, 0, len(this.trees))
+	for id := range this.trees {
+		ids = append(ids, id)
+	}
+	this.mutex.RUnlock()
+	sort.Strings(ids)
+	return ids
+}
+
+/**
+ * Reload replaces the tree registered under id and returns the previous
+ * one (nil if there was none). migrate, if not nil, is called with the old
+ * and new trees before the swap; it is skipped for new ids.
+**/
+func (this *TreeRegistry) Reload(id string, tree *BehaviorTree, migrate MigrateFunc) *BehaviorTree {
+	this.mutex.Lock()
+	old := this.trees[id]
+	if old != nil && migrate != nil {
+		migrate(old, tree)
+	}
+	this.trees[id] = tree
+	listeners := this.onReload
+	this.mutex.Unlock()
+
+	for _, f := range listeners {
+		f(id, old, tree)
+	}
+	return old
+}
+
+/**
+ * ReloadAll replaces the trees by id in one step, so a lookup never sees
+ * some trees of a project reloaded and others not, e.g. a tree and the
+ * subtrees it runs. migrate is applied as with Reload.
+**/
+func (this *TreeRegistry) ReloadAll(trees map[string]*BehaviorTree, migrate MigrateFunc) {
+	ids := make([]string, 0, len(trees))
+	for id := range trees {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	olds := make([]*BehaviorTree, len(ids))
+
+	this.mutex.Lock()
+	for i, id := range ids {
+		olds[i] = this.trees[id]
+		if olds[i] != nil && migrate != nil {
+			migrate(olds[i], trees[id])
+		}
+	}
+	for _, id := range ids {
+		this.trees[id] = trees[id]
+	}
+	listeners := this.onReload
+	this.mutex.Unlock()
+
+	for i, id := range ids {
+		for _, f := range listeners {
+			f(id, olds[i], trees[id])
+		}
+	}
+}
+
+// OnReload registers a callback invoked for every tree replaced by Reload
+// or ReloadAll.
+func (this *TreeRegistry) OnReload(f func(id string, old *BehaviorTree, new *BehaviorTree)) {
+	this.mutex.Lock()
+	this.onReload = append(this.onReload, f)
+	this.mutex.Unlock()
+}
+
+// Find returns the tree registered under name, or else the first tree, by
+// id, titled name.
+func (this *TreeRegistry) Find(name string) *BehaviorTree {
+	if tree := this.Get(name); tree != nil {
+		return tree
+	}
+	for _, id := range this.IDs() {
+		if tree := this.Get(id); tree != nil && tree.GetTitile() == name {
+			return tree
+		}
+	}
+	return nil
+}
+
+// UseForSubTrees makes SubTree nodes resolve their trees in this registry.
+func (this *TreeRegistry) UseForSubTrees() {
+	SetSubTreeLoadFunc(this.Get)
+}
+
+// UseForRunTree makes RunTree nodes resolve their trees in this registry.
+func (this *TreeRegistry) UseForRunTree() {
+	runTreeRegistry = this
+}
@@ -0,0 +1,38 @@
+package core
+
+import (
+	b3 "github.com/youngtrips/behavior3go"
+)
+
+/**
+ * TreeRunner ticks several trees per agent, in order, on the same
+ * blackboard: typically a sensing tree filling the blackboard, the
+ * behavior tree and a cleanup tree. Build it from a project with
+ * loader.CreateRunnerFromProject or from loaded trees:
+ *
+ *     runner := NewTreeRunner(sensing, behavior, cleanup)
+ *     statuses := runner.Tick(npc, blackboard)
+ *
+ * @class TreeRunner
+**/
+type TreeRunner struct {
+	trees []*BehaviorTree
+}
+
+func NewTreeRunner(trees ...*BehaviorTree) *TreeRunner {
+	return &TreeRunner{trees: trees}
+}
+
+// Trees returns the trees in tick order.
+func (this *TreeRunner) Trees() []*BehaviorTree {
+	return this.trees
+}
+
+// Tick ticks every tree in order and returns their statuses.
+func (this *TreeRunner) Tick(target interface{}, blackboard *Blackboard) []b3.Status {
+	statuses := make([]b3.Status, len(this.trees))
+	for i, tree := range this.trees {
+		statuses[i] = tree.Tick(target, blackboard)
+	}
+	return statuses
+}
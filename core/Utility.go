@@ -0,0 +1,26 @@
+package core
+
+/**
+ * IScorer is implemented by nodes reporting how desirable they are, for
+ * composites choosing a child by utility (see composites.UtilitySelector).
+ * A score of 0 or less means the node does not want to run. Score must not
+ * have side effects: it is called for every child on every tick.
+**/
+type IScorer interface {
+	Score(tick *Tick) float64
+}
+
+/**
+ * SmoothScore blends a new score into the previous one with momentum in
+ * [0, 1]: 0 keeps the raw score, values close to 1 make the score react
+ * slowly, smoothing out short spikes.
+**/
+func SmoothScore(previous, raw, momentum float64) float64 {
+	if momentum <= 0 {
+		return raw
+	}
+	if momentum > 1 {
+		momentum = 1
+	}
+	return momentum*previous + (1-momentum)*raw
+}
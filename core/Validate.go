@@ -0,0 +1,167 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	b3 "github.com/youngtrips/behavior3go"
+	"github.com/youngtrips/behavior3go/config"
+)
+
+// LoadErrors is the list of the problems found by ValidateConfig.
+type LoadErrors []*LoadError
+
+func (this LoadErrors) Error() string {
+	if len(this) == 1 {
+		return this[0].Error()
+	}
+	lines := make([]string, len(this))
+	for i, err := range this {
+		lines[i] = "\t" + err.Error()
+	}
+	return fmt.Sprintf("%d load errors:\n%s", len(this), strings.Join(lines, "\n"))
+}
+
+/**
+ * ValidateConfig checks data against the registered nodes without building
+ * the tree, and reports every problem rather than the first one: a
+ * missing root, unregistered node names, missing children, nodes not
+ * reachable from the root or with several parents, and categories not
+ * matching the registered nodes (children given to an action, a decorator
+ * without child...). It returns nil or LoadErrors, sorted by path.
+ *
+ * Load panics on some of these only, and silently accepts the others.
+**/
+func ValidateConfig(data *config.BTTreeCfg, maps *b3.RegisterStructMaps, extMaps *b3.RegisterStructMaps) error {
+	treeError := func(reason string) error {
+		return LoadErrors{{TreeID: data.ID, Path: data.Title, Reason: reason}}
+	}
+	expanded, err := config.ExpandDecorators(data)
+	if err != nil {
+		return treeError(err.Error())
+	}
+	data = expanded
+	if _, ok := data.Nodes[data.Root]; !ok {
+		return treeError(fmt.Sprintf("missing root node %q", data.Root))
+	}
+
+	var errs LoadErrors
+	paths := NodePaths(data)
+	report := func(id string, format string, args ...interface{}) {
+		errs = append(errs, &LoadError{TreeID: data.ID, NodeID: id, Path: paths[id], Reason: fmt.Sprintf(format, args...)})
+	}
+	parents := make(map[string][]string)
+	for id, spec := range data.Nodes {
+		if spec.Id != id {
+			report(id, "node listed as %s has id %s", id, spec.Id)
+		}
+		children := childIDs(&spec)
+		for _, child := range children {
+			if _, ok := data.Nodes[child]; !ok {
+				report(id, "missing child %s", child)
+				continue
+			}
+			parents[child] = append(parents[child], id)
+		}
+		validateCategory(&spec, maps, extMaps, func(format string, args ...interface{}) {
+			report(id, format, args...)
+		})
+	}
+
+	for id, ps := range parents {
+		if len(ps) > 1 {
+			sort.Strings(ps)
+			report(id, "node has %d parents: %s", len(ps), strings.Join(ps, ", "))
+		}
+	}
+	reachable := make(map[string]bool)
+	var visit func(id string)
+	visit = func(id string) {
+		spec, ok := data.Nodes[id]
+		if !ok || reachable[id] {
+			return
+		}
+		reachable[id] = true
+		if spec.Child != "" {
+			visit(spec.Child)
+		}
+		for _, child := range spec.Children {
+			visit(child)
+		}
+	}
+	visit(data.Root)
+	for id := range data.Nodes {
+		if !reachable[id] {
+			report(id, "orphan node, not reachable from the root")
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].Path != errs[j].Path {
+			return errs[i].Path < errs[j].Path
+		}
+		return errs[i].Reason < errs[j].Reason
+	})
+	return errs
+}
+
+// validateCategory checks the node of spec is registered and its
+// category matches spec.
+func validateCategory(spec *config.BTNodeCfg, maps *b3.RegisterStructMaps, extMaps *b3.RegisterStructMaps, report func(format string, args ...interface{})) {
+	category := spec.Category
+	if category != "tree" {
+		var created interface{}
+		if extMaps != nil && extMaps.CheckElem(spec.Name) {
+			created, _ = extMaps.New(spec.Name)
+		} else if maps != nil && maps.CheckElem(spec.Name) {
+			created, _ = maps.New(spec.Name)
+		}
+		node, ok := created.(IBaseNode)
+		if !ok {
+			report("unregistered node name %s", spec.Name)
+			return
+		}
+		node.Ctor()
+		category = node.GetCategory()
+		// actions and conditions only differ in their role, see Condition
+		isLeaf := func(c string) bool { return c == b3.ACTION || c == b3.CONDITION }
+		if spec.Category != "" && spec.Category != category && !(isLeaf(spec.Category) && isLeaf(category)) {
+			report("declared as %s, but %s is registered as %s", spec.Category, spec.Name, category)
+		}
+	}
+
+	switch category {
+	case b3.COMPOSITE:
+		if spec.Child != "" && len(spec.Children) == 0 {
+			report("composite %s with a child instead of children", spec.Name)
+		}
+	case b3.DECORATOR:
+		if spec.Child == "" {
+			report("decorator %s without child", spec.Name)
+		} else if len(childIDs(spec)) > 1 {
+			report("decorator %s with children, it takes one child", spec.Name)
+		}
+	default:
+		if spec.Child != "" || len(spec.Children) > 0 {
+			report("%s %s cannot have children", category, spec.Name)
+		}
+	}
+}
+
+// childIDs returns the ids of the children of spec, once each: the
+// importers may list a single child both as child and children.
+func childIDs(spec *config.BTNodeCfg) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, id := range append([]string{spec.Child}, spec.Children...) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
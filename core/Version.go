@@ -0,0 +1,69 @@
+package core
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+
+	b3 "github.com/youngtrips/behavior3go"
+)
+
+/**
+ * Version returns a checksum of the structure of the tree: the id, name,
+ * category and properties of every node, in tree order. Two trees loaded
+ * from equal configs have the same version, so it tells whether running
+ * state saved from one tree (open nodes, node memories) fits another.
+ * Subtrees are not included, they have their own version.
+ *
+ * @method Version
+ * @return {String} 16 hex digits.
+**/
+func (this *BehaviorTree) Version() string {
+	if this.version == "" {
+		this.version = this.checksum()
+	}
+	return this.version
+}
+
+func (this *BehaviorTree) checksum() string {
+	h := sha1.New()
+	var props map[string]map[string]interface{}
+	if this.dumpInfo != nil {
+		props = make(map[string]map[string]interface{}, len(this.dumpInfo.Nodes))
+		for id, spec := range this.dumpInfo.Nodes {
+			props[id] = spec.Properties
+		}
+	}
+	var visit func(node IBaseNode)
+	visit = func(node IBaseNode) {
+		if node == nil {
+			h.Write([]byte("nil\n"))
+			return
+		}
+		h.Write([]byte(node.GetID()))
+		h.Write([]byte{0})
+		h.Write([]byte(node.GetName()))
+		h.Write([]byte{0})
+		h.Write([]byte(node.GetCategory()))
+		h.Write([]byte{0})
+		// json sorts map keys
+		data, _ := json.Marshal(props[node.GetID()])
+		h.Write(data)
+		switch node.GetCategory() {
+		case b3.COMPOSITE:
+			comp := node.(IComposite)
+			h.Write([]byte("\x00" + strconv.Itoa(comp.GetChildCount()) + "\n"))
+			for i := 0; i < comp.GetChildCount(); i++ {
+				visit(comp.GetChild(i))
+			}
+		case b3.DECORATOR:
+			h.Write([]byte("\x001\n"))
+			visit(node.(IDecorator).GetChild())
+		default:
+			h.Write([]byte("\x000\n"))
+		}
+	}
+	visit(this.root)
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	b3 "github.com/youngtrips/behavior3go"
+)
+
+type iBaseNodeHolder interface {
+	baseNode() *BaseNode
+}
+
+// toBaseNode returns the BaseNode embedded in node, nil if it has none.
+func toBaseNode(node IBaseNode) *BaseNode {
+	if holder, ok := node.(iBaseNodeHolder); ok {
+		return holder.baseNode()
+	}
+	return nil
+}
+
+// iSubtreeHost is implemented by the nodes running other trees in the
+// tick of their own tree (SubTree, StateMachine).
+type iSubtreeHost interface {
+	subtrees() []*BehaviorTree
+}
+
+// subtreesOf returns the trees node runs, the loaded ones only.
+func subtreesOf(node IBaseNode) []*BehaviorTree {
+	if host, ok := node.(iSubtreeHost); ok {
+		return host.subtrees()
+	}
+	return nil
+}
+
+/**
+ * walkNode visits node and its descendants depth first, in child order.
+ * SubTree and StateMachine nodes are followed into their trees once per
+ * walk. visit returns false to stop the walk.
+**/
+func walkNode(node IBaseNode, visit func(node IBaseNode) bool) bool {
+	return walkNodeTrees(node, visit, make(map[*BehaviorTree]bool))
+}
+
+func walkNodeTrees(node IBaseNode, visit func(node IBaseNode) bool, seen map[*BehaviorTree]bool) bool {
+	if node == nil {
+		return true
+	}
+	if !visit(node) {
+		return false
+	}
+	switch node.GetCategory() {
+	case b3.COMPOSITE:
+		comp := node.(IComposite)
+		for i := 0; i < comp.GetChildCount(); i++ {
+			if !walkNodeTrees(comp.GetChild(i), visit, seen) {
+				return false
+			}
+		}
+	case b3.DECORATOR:
+		return walkNodeTrees(node.(IDecorator).GetChild(), visit, seen)
+	default:
+		for _, tree := range subtreesOf(node) {
+			if !seen[tree] {
+				seen[tree] = true
+				if !walkNodeTrees(tree.GetRoot(), visit, seen) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+/**
+ * FindNode returns the node with the given id, looking into subtrees as
+ * well, or nil if the tree has no such node.
+**/
+func (this *BehaviorTree) FindNode(id string) IBaseNode {
+	var found IBaseNode
+	walkNode(this.root, func(node IBaseNode) bool {
+		if node.GetID() == id {
+			found = node
+			return false
+		}
+		return true
+	})
+	return found
+}
@@ -0,0 +1,240 @@
+/**
+ * Package builder constructs behavior trees in code, without JSON file:
+ *
+ *     tree, err := builder.NewTree("patrol").
+ *         Priority().
+ *             Sequence().
+ *                 Condition("NumericRange", builder.Props{"key": "hp", "min": 50}).
+ *                 Action("Log", builder.Props{"info": "patrolling"}).
+ *                 Action("Wait", builder.Props{"milliseconds": 500}).
+ *             End().
+ *             Action("Log", builder.Props{"info": "fleeing"}).
+ *         End().
+ *         Build(nil)
+ *
+ * Composites are opened by their method or Composite and closed by End;
+ * decorators take the next node as child and close with it. The tree is
+ * built by the loader from the config the builder writes (see Config), so
+ * the nodes are the registered ones and behave as loaded from a file.
+**/
+package builder
+
+import (
+	"fmt"
+	"strconv"
+
+	b3 "github.com/youngtrips/behavior3go"
+	"github.com/youngtrips/behavior3go/config"
+	"github.com/youngtrips/behavior3go/core"
+	"github.com/youngtrips/behavior3go/loader"
+)
+
+// Props are the properties of a node. Go numbers are stored as decoded
+// from a file, see config.SetUseNumber.
+type Props map[string]interface{}
+
+// TreeBuilder writes a tree config node by node, see NewTree.
+type TreeBuilder struct {
+	cfg *config.BTTreeCfg
+	// the ids of the opened composites and decorators, innermost last
+	open []string
+	// id of the last node added, see Title
+	last  string
+	count int
+	err   error
+}
+
+// NewTree starts a tree whose id and title are id.
+func NewTree(id string) *TreeBuilder {
+	return &TreeBuilder{cfg: &config.BTTreeCfg{
+		ID:         id,
+		Title:      id,
+		Properties: make(map[string]interface{}),
+		Nodes:      make(map[string]config.BTNodeCfg),
+	}}
+}
+
+// Properties sets properties of the tree, e.g. its "schema".
+func (this *TreeBuilder) Properties(props Props) *TreeBuilder {
+	for k, v := range props {
+		this.cfg.Properties[k] = propValue(v)
+	}
+	return this
+}
+
+func (this *TreeBuilder) Sequence() *TreeBuilder    { return this.Composite("Sequence", nil) }
+func (this *TreeBuilder) MemSequence() *TreeBuilder { return this.Composite("MemSequence", nil) }
+func (this *TreeBuilder) Priority() *TreeBuilder    { return this.Composite("Priority", nil) }
+func (this *TreeBuilder) MemPriority() *TreeBuilder { return this.Composite("MemPriority", nil) }
+
+// Selector is Priority, the usual name of the fallback composite.
+func (this *TreeBuilder) Selector() *TreeBuilder { return this.Priority() }
+
+func (this *TreeBuilder) Parallel(props Props) *TreeBuilder {
+	return this.Composite("Parallel", props)
+}
+
+func (this *TreeBuilder) Inverter() *TreeBuilder { return this.Decorator("Inverter", nil) }
+
+// Composite opens a composite node, closed by End.
+func (this *TreeBuilder) Composite(name string, props Props) *TreeBuilder {
+	if id := this.add(name, b3.COMPOSITE, props); id != "" {
+		this.open = append(this.open, id)
+	}
+	return this
+}
+
+// Decorator opens a decorator node, closed by its child.
+func (this *TreeBuilder) Decorator(name string, props Props) *TreeBuilder {
+	if id := this.add(name, b3.DECORATOR, props); id != "" {
+		this.open = append(this.open, id)
+	}
+	return this
+}
+
+func (this *TreeBuilder) Action(name string, props Props) *TreeBuilder {
+	this.add(name, b3.ACTION, props)
+	return this
+}
+
+func (this *TreeBuilder) Condition(name string, props Props) *TreeBuilder {
+	this.add(name, b3.CONDITION, props)
+	return this
+}
+
+// SubTree adds a node running the tree named name, see core.SubTree.
+func (this *TreeBuilder) SubTree(name string) *TreeBuilder {
+	this.add(name, "tree", nil)
+	return this
+}
+
+// End closes the innermost composite.
+func (this *TreeBuilder) End() *TreeBuilder {
+	if this.err != nil {
+		return this
+	}
+	if len(this.open) == 0 {
+		this.err = fmt.Errorf("builder: tree %s: End without open composite", this.cfg.ID)
+		return this
+	}
+	id := this.open[len(this.open)-1]
+	node := this.cfg.Nodes[id]
+	if node.Category != b3.COMPOSITE {
+		this.err = fmt.Errorf("builder: tree %s: End of decorator %s, which has no child", this.cfg.ID, node.Name)
+		return this
+	}
+	this.open = this.open[:len(this.open)-1]
+	return this
+}
+
+// Title sets the title of the last node added.
+func (this *TreeBuilder) Title(title string) *TreeBuilder {
+	if node, ok := this.cfg.Nodes[this.last]; ok {
+		node.Title = title
+		this.cfg.Nodes[this.last] = node
+	}
+	return this
+}
+
+// Config returns the tree config written, with an error if the tree is
+// incomplete or was misbuilt.
+func (this *TreeBuilder) Config() (*config.BTTreeCfg, error) {
+	if this.err != nil {
+		return nil, this.err
+	}
+	if this.cfg.Root == "" {
+		return nil, fmt.Errorf("builder: tree %s is empty", this.cfg.ID)
+	}
+	for _, id := range this.open {
+		if node := this.cfg.Nodes[id]; node.Category == b3.DECORATOR {
+			return nil, fmt.Errorf("builder: tree %s: decorator %s has no child", this.cfg.ID, node.Name)
+		}
+	}
+	return this.cfg, nil
+}
+
+// Build builds the tree with the registered nodes and extMap, see
+// loader.TryCreateBevTreeFromConfig. Composites left open are closed.
+func (this *TreeBuilder) Build(extMap *b3.RegisterStructMaps) (*core.BehaviorTree, error) {
+	cfg, err := this.Config()
+	if err != nil {
+		return nil, err
+	}
+	return loader.TryCreateBevTreeFromConfig(cfg, extMap)
+}
+
+// add adds a node under the innermost opened node and returns its id, ""
+// on error.
+func (this *TreeBuilder) add(name string, category string, props Props) string {
+	if this.err != nil {
+		return ""
+	}
+	this.count++
+	node := config.BTNodeCfg{
+		Id:         this.cfg.ID + "/" + strconv.Itoa(this.count),
+		Name:       name,
+		Category:   category,
+		Title:      name,
+		Properties: make(map[string]interface{}, len(props)),
+	}
+	for k, v := range props {
+		node.Properties[k] = propValue(v)
+	}
+
+	if len(this.open) == 0 {
+		if this.cfg.Root != "" {
+			this.err = fmt.Errorf("builder: tree %s: %s added beside the root", this.cfg.ID, name)
+			return ""
+		}
+		this.cfg.Root = node.Id
+	} else {
+		parentID := this.open[len(this.open)-1]
+		parent := this.cfg.Nodes[parentID]
+		if parent.Category == b3.DECORATOR {
+			parent.Child = node.Id
+			this.open = this.open[:len(this.open)-1]
+		} else {
+			parent.Children = append(parent.Children, node.Id)
+		}
+		this.cfg.Nodes[parentID] = parent
+	}
+	this.cfg.Nodes[node.Id] = node
+	this.last = node.Id
+	return node.Id
+}
+
+// propValue converts the Go numbers of v as config.Unmarshal decodes
+// them, recursively.
+func propValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case int:
+		return config.NumberValue(strconv.FormatInt(int64(v), 10))
+	case int32:
+		return config.NumberValue(strconv.FormatInt(int64(v), 10))
+	case int64:
+		return config.NumberValue(strconv.FormatInt(v, 10))
+	case uint32:
+		return config.NumberValue(strconv.FormatUint(uint64(v), 10))
+	case uint64:
+		return config.NumberValue(strconv.FormatUint(v, 10))
+	case float32:
+		return config.NumberValue(strconv.FormatFloat(float64(v), 'f', -1, 32))
+	case float64:
+		return config.NumberValue(strconv.FormatFloat(v, 'f', -1, 64))
+	case Props:
+		return propValue(map[string]interface{}(v))
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = propValue(e)
+		}
+		return m
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, e := range v {
+			list[i] = propValue(e)
+		}
+		return list
+	}
+	return v
+}
@@ -0,0 +1,82 @@
+package core_test
+
+import (
+	"testing"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+	"github.com/youngtrips/behavior3go/loader"
+)
+
+// closeRecorder runs until closed, appending its id to closed on OnClose.
+type closeRecorder struct {
+	Action
+	closed *[]string
+}
+
+func (this *closeRecorder) OnTick(tick *Tick) b3.Status {
+	tick.Blackboard.Set("ticks", tick.Blackboard.GetInt("ticks", tick.GetTree().GetID(), this.GetID())+1,
+		tick.GetTree().GetID(), this.GetID())
+	return b3.RUNNING
+}
+
+func (this *closeRecorder) OnClose(tick *Tick) {
+	*this.closed = append(*this.closed, this.GetID())
+}
+
+func TestAbortClosesAndForgets(t *testing.T) {
+	var closed []string
+	maps := b3.NewRegisterStructMaps()
+	RegisterNodeFactory(maps, "Recorder", func() IBaseNode {
+		return &closeRecorder{closed: &closed}
+	})
+	treeConfig, err := LoadTreeCfgFromBytes([]byte(`{
+		"id": "t", "title": "abort", "root": "s",
+		"nodes": {
+			"s": {"id": "s", "name": "MemSequence", "category": "composite", "children": ["i", "r"]},
+			"i": {"id": "i", "name": "Inverter", "category": "decorator", "child": "f"},
+			"f": {"id": "f", "name": "Failer", "category": "action"},
+			"r": {"id": "r", "name": "Recorder", "category": "action"}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := loader.TryCreateBevTreeFromConfig(treeConfig, maps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	board := NewBlackboard(nil)
+	board.SetMem("kept", true)
+	tree.Tick(0, board)
+	tree.Tick(0, board)
+	if ticks := board.GetInt("ticks", tree.GetID(), "r"); ticks != 2 {
+		t.Fatal("ticks before abort:", ticks)
+	}
+
+	tree.Abort(0, board)
+	if len(closed) != 1 || closed[0] != "r" {
+		t.Error("closed on abort:", closed)
+	}
+	if open := tree.OpenNodes(board); len(open) != 0 {
+		t.Error("open after abort:", open)
+	}
+	if board.Get("ticks", tree.GetID(), "r") != nil {
+		t.Error("node memory kept")
+	}
+	if !board.GetBool("kept", "", "") {
+		t.Error("global memory dropped")
+	}
+
+	// the next tick starts from the root, with fresh memory
+	tree.Tick(0, board)
+	if ticks := board.GetInt("ticks", tree.GetID(), "r"); ticks != 1 {
+		t.Error("ticks after abort:", ticks)
+	}
+	tree.Abort(0, board)
+	tree.Abort(0, board)
+	if len(closed) != 2 {
+		t.Error("closed twice by a second abort:", closed)
+	}
+}
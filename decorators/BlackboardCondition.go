@@ -0,0 +1,135 @@
+package decorators
+
+import (
+	"strings"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * The BlackboardCondition decorator only ticks its child while a key of
+ * the global memory of the blackboard passes a check, returning `FAILURE`
+ * otherwise; it replaces the trivial condition nodes written to guard a
+ * branch:
+ *
+ *     key: "hp", operator: "<", value: 30
+ *
+ * The operators are those of Compare, plus "isSet" and "isNotSet" which
+ * ignore the value. The check is made when the node opens; with reactive
+ * set it is repeated on every tick and a RUNNING child is halted as soon
+ * as it fails.
+ *
+ * @module b3
+ * @class BlackboardCondition
+ * @extends DecoratorHelper
+**/
+type BlackboardCondition struct {
+	DecoratorHelper
+	check    keyCheck
+	reactive bool
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **key**      (*String*)  Key of the global memory to check.
+ * - **operator** (*String*)  Comparison operator, "==" by default.
+ * - **value**    (*Object*)  Value compared to the key.
+ * - **reactive** (*Boolean*) Check on every tick, halting the running
+ *                            child when the check fails.
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *BlackboardCondition) Initialize(setting *BTNodeCfg) {
+	this.DecoratorHelper.Initialize(setting)
+	this.check.init(setting, "BlackboardCondition", nil)
+	this.reactive = setting.GetPropertyAsBool("reactive")
+}
+
+// Check tells whether the key passes the check.
+func (this *BlackboardCondition) Check(tick *Tick) bool {
+	return this.check.holds(tick)
+}
+
+// keyCheck is the key/operator/value check of a global memory key shared
+// by the decorators gated by the blackboard.
+type keyCheck struct {
+	key      string
+	operator string
+	value    interface{}
+}
+
+// init reads the properties of the check; value defaults to def.
+func (this *keyCheck) init(setting *BTNodeCfg, node string, def interface{}) {
+	this.key = setting.GetPropertyAsString("key")
+	if this.key == "" {
+		panic("key parameter in " + node + " decorator is an obligatory parameter")
+	}
+	this.operator = "=="
+	if _, ok := setting.Properties["operator"]; ok {
+		this.operator = setting.GetPropertyAsString("operator")
+	}
+	if !this.unary() && !ValidCompareOp(this.operator) {
+		panic(node + " decorator: unknown operator " + this.operator)
+	}
+	this.value = def
+	if v, ok := setting.Properties["value"]; ok {
+		this.value = v
+	}
+}
+
+func (this *keyCheck) unary() bool {
+	switch strings.ToLower(this.operator) {
+	case "isset", "isnotset":
+		return true
+	}
+	return false
+}
+
+func (this *keyCheck) holds(tick *Tick) bool {
+	v := tick.Blackboard.GetMem(this.key)
+	switch strings.ToLower(this.operator) {
+	case "isset":
+		return v != nil
+	case "isnotset":
+		return v == nil
+	}
+	ok, err := Compare(v, this.operator, this.value)
+	return err == nil && ok
+}
+
+/**
+ * Open method.
+ * @method open
+ * @param {Tick} tick A tick instance.
+**/
+func (this *BlackboardCondition) OnOpen(tick *Tick) {
+	tick.Blackboard.Set("checked", false, tick.GetTree().GetID(), this.GetID())
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (this *BlackboardCondition) OnTick(tick *Tick) b3.Status {
+	if !this.HasChild() {
+		return b3.ERROR
+	}
+	treeID := tick.GetTree().GetID()
+	if this.reactive || !tick.Blackboard.GetBool("checked", treeID, this.GetID()) {
+		if !this.Check(tick) {
+			this.HaltChild(tick)
+			return b3.FAILURE
+		}
+		tick.Blackboard.Set("checked", true, treeID, this.GetID())
+	}
+	return this.TickChild(tick)
+}
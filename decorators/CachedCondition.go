@@ -0,0 +1,103 @@
+package decorators
+
+import (
+	"strings"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * CachedCondition caches the result of its child, a condition (or any
+ * node without side effects), and only ticks it again after one of the
+ * blackboard keys it depends on was written. Large guard-heavy trees then
+ * cost one lookup per guard and tick instead of a full evaluation.
+ *
+ * Each agent gets its own cache: the decorator watches the keys (see
+ * Blackboard.Watch) on the blackboard of the agent the first time it is
+ * ticked with it, and again once the memory of the node was removed
+ * (Abort, RemoveTreeScope), which cancels the watches. RUNNING and ERROR
+ * results are not cached.
+ *
+ * @module b3
+ * @class CachedCondition
+ * @extends Decorator
+**/
+type CachedCondition struct {
+	Decorator
+	keys []string
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **keys** (*String*) Comma separated keys the child depends on, global
+ *                       keys by default or "tree:key" for the memory of
+ *                       the tree.
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *CachedCondition) Initialize(setting *BTNodeCfg) {
+	this.Decorator.Initialize(setting)
+	for _, key := range strings.Split(setting.GetPropertyAsString("keys"), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			this.keys = append(this.keys, key)
+		}
+	}
+}
+
+func (this *CachedCondition) watch(tick *Tick) {
+	treeID, nodeID := tick.GetTree().GetID(), this.GetID()
+	bb := tick.Blackboard
+	invalidate := func(key string, old, new interface{}) {
+		bb.Set("dirty", true, treeID, nodeID)
+	}
+	for _, key := range this.keys {
+		var cancel func()
+		if strings.HasPrefix(key, "tree:") {
+			cancel = bb.Watch(strings.TrimPrefix(key, "tree:"), treeID, "", invalidate)
+		} else {
+			cancel = bb.Watch(key, "", "", invalidate)
+		}
+		bb.AddNodeWatch(treeID, nodeID, cancel)
+	}
+	if len(this.keys) == 0 {
+		// nothing invalidates the cache, but mark it watched
+		bb.AddNodeWatch(treeID, nodeID, func() {})
+	}
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (this *CachedCondition) OnTick(tick *Tick) b3.Status {
+	if this.GetChild() == nil {
+		return b3.ERROR
+	}
+	treeID, nodeID := tick.GetTree().GetID(), this.GetID()
+	// a restored or copied cache is not watched: evaluate again
+	if !tick.Blackboard.HasNodeWatches(treeID, nodeID) {
+		this.watch(tick)
+	} else if !tick.Blackboard.GetBool("dirty", treeID, nodeID) {
+		if cached, ok := tick.Blackboard.Get("cached", treeID, nodeID).(b3.Status); ok {
+			return cached
+		}
+	}
+
+	tick.Blackboard.Set("dirty", false, treeID, nodeID)
+	status := this.GetChild().Execute(tick)
+	if status == b3.SUCCESS || status == b3.FAILURE {
+		tick.Blackboard.Set("cached", status, treeID, nodeID)
+	} else {
+		tick.Blackboard.Set("cached", nil, treeID, nodeID)
+	}
+	return status
+}
@@ -0,0 +1,90 @@
+package decorators
+
+import (
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * The CircuitBreaker decorator stops calling a child that keeps failing,
+ * typically an action hitting an external service. It counts the
+ * consecutive failures (ERROR included) of the child; after maxFailures
+ * of them the breaker opens and returns `FAILURE` without executing the
+ * child for the next milliseconds. Then it is half-open: the child is
+ * tried again, a success closing the breaker and a failure opening it for
+ * another period.
+ *
+ * The state is kept in the node memory, so it is per agent and per tree;
+ * the time is read from the clock of the blackboard (see SetClock).
+ *
+ * @module b3
+ * @class CircuitBreaker
+ * @extends DecoratorHelper
+**/
+type CircuitBreaker struct {
+	DecoratorHelper
+	maxFailures  int
+	milliseconds int64
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **maxFailures**  (*Integer*) Consecutive failures opening the breaker.
+ * - **milliseconds** (*Integer*) Time the breaker stays open.
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *CircuitBreaker) Initialize(setting *BTNodeCfg) {
+	this.DecoratorHelper.Initialize(setting)
+	this.maxFailures = setting.GetPropertyAsInt("maxFailures")
+	if this.maxFailures < 1 {
+		panic("maxFailures parameter in CircuitBreaker decorator is an obligatory parameter")
+	}
+	this.milliseconds = setting.GetPropertyAsInt64("milliseconds")
+	if this.milliseconds < 1 {
+		panic("milliseconds parameter in CircuitBreaker decorator is an obligatory parameter")
+	}
+}
+
+// IsOpen tells whether the breaker currently rejects the calls.
+func (this *CircuitBreaker) IsOpen(tick *Tick) bool {
+	until, ok := tick.Blackboard.Get("openUntil", tick.GetTree().GetID(), this.GetID()).(int64)
+	return ok && tick.Now().UnixNano()/1000000 < until
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (this *CircuitBreaker) OnTick(tick *Tick) b3.Status {
+	if !this.HasChild() {
+		return b3.ERROR
+	}
+	if this.IsOpen(tick) {
+		return b3.FAILURE
+	}
+	treeID, nodeID := tick.GetTree().GetID(), this.GetID()
+	var status = this.TickChild(tick)
+	switch status {
+	case b3.RUNNING:
+	case b3.SUCCESS:
+		tick.Blackboard.Set("failures", 0, treeID, nodeID)
+		tick.Blackboard.Set("openUntil", nil, treeID, nodeID)
+	default:
+		// a failure while half-open opens the breaker again at once
+		failures := tick.Blackboard.GetInt("failures", treeID, nodeID) + 1
+		if failures >= this.maxFailures {
+			tick.Blackboard.Set("openUntil", tick.Now().UnixNano()/1000000+this.milliseconds, treeID, nodeID)
+		}
+		tick.Blackboard.Set("failures", failures, treeID, nodeID)
+	}
+	return status
+}
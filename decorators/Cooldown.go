@@ -0,0 +1,107 @@
+package decorators
+
+import (
+	"strings"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * The Cooldown decorator blocks its child for a while after it finished:
+ * once the child returned one of the given statuses (SUCCESS by default),
+ * the decorator returns `FAILURE` without executing it for the next
+ * milliseconds, read from the clock of the blackboard (see SetClock), or
+ * for the next ticks of the blackboard.
+ *
+ * The end of the cooldown is kept in the node memory, so it is per agent
+ * and per tree; see Tick.Cooldowns for cooldowns shared across trees.
+ *
+ * @module b3
+ * @class Cooldown
+ * @extends Decorator
+**/
+type Cooldown struct {
+	Decorator
+	milliseconds int64
+	ticks        int64
+	statuses     []b3.Status
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **milliseconds** (*Integer*) Cooldown duration, in milliseconds.
+ * - **ticks**        (*Integer*) Cooldown duration, in ticks, instead of
+ *                                milliseconds.
+ * - **statuses**     (*String*)  Comma separated statuses of the child
+ *                                starting the cooldown, "success" by
+ *                                default.
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *Cooldown) Initialize(setting *BTNodeCfg) {
+	this.Decorator.Initialize(setting)
+	if _, ok := setting.Properties["milliseconds"]; ok {
+		this.milliseconds = setting.GetPropertyAsInt64("milliseconds")
+	}
+	if _, ok := setting.Properties["ticks"]; ok {
+		this.ticks = setting.GetPropertyAsInt64("ticks")
+	}
+	if this.milliseconds < 1 && this.ticks < 1 {
+		panic("milliseconds or ticks parameter in Cooldown decorator is an obligatory parameter")
+	}
+	this.statuses = []b3.Status{b3.SUCCESS}
+	if _, ok := setting.Properties["statuses"]; ok {
+		this.statuses = nil
+		for _, name := range strings.Split(setting.GetPropertyAsString("statuses"), ",") {
+			status, err := b3.ParseStatus(name)
+			if err != nil {
+				panic("Cooldown decorator: " + err.Error())
+			}
+			this.statuses = append(this.statuses, status)
+		}
+	}
+}
+
+// now returns the current time in the unit of the cooldown.
+func (this *Cooldown) now(tick *Tick) int64 {
+	if this.ticks > 0 {
+		return int64(tick.Blackboard.TickCount())
+	}
+	return tick.Now().UnixNano() / 1000000
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (this *Cooldown) OnTick(tick *Tick) b3.Status {
+	if this.GetChild() == nil {
+		return b3.ERROR
+	}
+	treeID := tick.GetTree().GetID()
+	if until, ok := tick.Blackboard.Get("until", treeID, this.GetID()).(int64); ok && this.now(tick) < until {
+		return b3.FAILURE
+	}
+	var status = this.GetChild().Execute(tick)
+	for _, s := range this.statuses {
+		if s == status {
+			duration := this.milliseconds
+			if this.ticks > 0 {
+				// the current tick does not count
+				duration = this.ticks + 1
+			}
+			tick.Blackboard.Set("until", this.now(tick)+duration, treeID, this.GetID())
+			break
+		}
+	}
+	return status
+}
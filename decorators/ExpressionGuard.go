@@ -0,0 +1,67 @@
+package decorators
+
+import (
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+	"github.com/youngtrips/behavior3go/expr"
+)
+
+/**
+ * The ExpressionGuard decorator only ticks its child while a boolean
+ * expression over the global memory of the blackboard holds, e.g.
+ * "hp < 30 && ammo > 0" (see package expr for the syntax). The expression
+ * is evaluated on every tick: once it does not hold, a RUNNING child is
+ * halted and the decorator returns `FAILURE`. An evaluation error (e.g.
+ * arithmetic on a missing key) counts as false and is reported with
+ * Tick.SetError.
+ *
+ * @module b3
+ * @class ExpressionGuard
+ * @extends DecoratorHelper
+**/
+type ExpressionGuard struct {
+	DecoratorHelper
+	expr *expr.Expr
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **expr** (*String*) The boolean expression.
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *ExpressionGuard) Initialize(setting *BTNodeCfg) {
+	this.DecoratorHelper.Initialize(setting)
+	e, err := expr.Parse(setting.GetPropertyAsString("expr"))
+	if err != nil {
+		panic("ExpressionGuard decorator: " + err.Error())
+	}
+	this.expr = e
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (this *ExpressionGuard) OnTick(tick *Tick) b3.Status {
+	if !this.HasChild() {
+		return b3.ERROR
+	}
+	ok, err := this.expr.EvalBool(tick.Blackboard.GetMem)
+	if err != nil {
+		tick.SetError(this, err)
+	}
+	if !ok {
+		this.HaltChild(tick)
+		return b3.FAILURE
+	}
+	return this.TickChild(tick)
+}
@@ -1,8 +1,6 @@
 package decorators
 
 import (
-	"time"
-
 	b3 "github.com/youngtrips/behavior3go"
 	. "github.com/youngtrips/behavior3go/config"
 	. "github.com/youngtrips/behavior3go/core"
@@ -49,7 +47,7 @@ func (this *MaxTime) Initialize(setting *BTNodeCfg) {
  * @param {Tick} tick A tick instance.
 **/
 func (this *MaxTime) OnOpen(tick *Tick) {
-	var startTime int64 = time.Now().UnixNano() / 1000000
+	var startTime int64 = tick.Now().UnixNano() / 1000000
 	tick.Blackboard.Set("startTime", startTime, tick.GetTree().GetID(), this.GetID())
 }
 
@@ -63,7 +61,7 @@ func (this *MaxTime) OnTick(tick *Tick) b3.Status {
 	if this.GetChild() == nil {
 		return b3.ERROR
 	}
-	var currTime int64 = time.Now().UnixNano() / 1000000
+	var currTime int64 = tick.Now().UnixNano() / 1000000
 	var startTime int64 = tick.Blackboard.GetInt64("startTime", tick.GetTree().GetID(), this.GetID())
 	var status = this.GetChild().Execute(tick)
 	if currTime-startTime > this.maxTime {
@@ -0,0 +1,73 @@
+package decorators
+
+import (
+	"time"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * MaxTimeDelta limits the time its child runs like MaxTime, counting the
+ * delta time of the ticks (see BehaviorTree.TickDelta) instead of reading
+ * the clock.
+ *
+ * @module b3
+ * @class MaxTimeDelta
+ * @extends Decorator
+**/
+type MaxTimeDelta struct {
+	Decorator
+	maxTime time.Duration
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **maxTime** (*Integer*) Maximum simulation time, in milliseconds, the
+ *                           child can run.
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *MaxTimeDelta) Initialize(setting *BTNodeCfg) {
+	this.Decorator.Initialize(setting)
+	this.maxTime = time.Duration(setting.GetPropertyAsInt64("maxTime")) * time.Millisecond
+	if this.maxTime <= 0 {
+		panic("maxTime parameter in MaxTimeDelta decorator is an obligatory parameter")
+	}
+}
+
+/**
+ * Open method.
+ * @method open
+ * @param {Tick} tick A tick instance.
+**/
+func (this *MaxTimeDelta) OnOpen(tick *Tick) {
+	tick.Blackboard.Set("elapsed", time.Duration(0), tick.GetTree().GetID(), this.GetID())
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (this *MaxTimeDelta) OnTick(tick *Tick) b3.Status {
+	if this.GetChild() == nil {
+		return b3.ERROR
+	}
+	elapsed, _ := tick.Blackboard.Get("elapsed", tick.GetTree().GetID(), this.GetID()).(time.Duration)
+	elapsed += tick.DeltaTime
+	tick.Blackboard.Set("elapsed", elapsed, tick.GetTree().GetID(), this.GetID())
+	var status = this.GetChild().Execute(tick)
+	if elapsed > this.maxTime {
+		return b3.FAILURE
+	}
+
+	return status
+}
@@ -0,0 +1,135 @@
+package decorators
+
+import (
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * The ObserveKey decorator interrupts its child when a key of the global
+ * memory of the blackboard changes, without polling a condition. While
+ * the node is open it watches the key (see Blackboard.WatchChanges); once
+ * the key changed, or changed to a value passing operator/value when they
+ * are set, the RUNNING child is halted and the decorator returns the
+ * status property (`FAILURE` by default).
+ *
+ * Writes made by the game between ticks interrupt the child on the next
+ * tick; writes made by the child itself interrupt it as soon as its tick
+ * returns.
+ *
+ * @module b3
+ * @class ObserveKey
+ * @extends DecoratorHelper
+**/
+type ObserveKey struct {
+	DecoratorHelper
+	key      string
+	operator string
+	value    interface{}
+	status   b3.Status
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **key**      (*String*) Key of the global memory to observe.
+ * - **operator** (*String*) Optional comparison, see Compare; the child
+ *                           is interrupted only when the new value passes.
+ * - **value**    (*Object*) Value compared to the new value.
+ * - **status**   (*String*) Status returned when interrupted, "failure"
+ *                           by default.
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *ObserveKey) Initialize(setting *BTNodeCfg) {
+	this.DecoratorHelper.Initialize(setting)
+	this.key = setting.GetPropertyAsString("key")
+	if this.key == "" {
+		panic("key parameter in ObserveKey decorator is an obligatory parameter")
+	}
+	this.operator = ""
+	if _, ok := setting.Properties["operator"]; ok {
+		this.operator = setting.GetPropertyAsString("operator")
+		if !ValidCompareOp(this.operator) {
+			panic("ObserveKey decorator: unknown operator " + this.operator)
+		}
+	}
+	this.value = setting.Properties["value"]
+	this.status = b3.FAILURE
+	if _, ok := setting.Properties["status"]; ok {
+		status, err := b3.ParseStatus(setting.GetPropertyAsString("status"))
+		if err != nil {
+			panic("ObserveKey decorator: " + err.Error())
+		}
+		this.status = status
+	}
+}
+
+/**
+ * Open method.
+ * @method open
+ * @param {Tick} tick A tick instance.
+**/
+func (this *ObserveKey) OnOpen(tick *Tick) {
+	tick.Blackboard.Set("triggered", false, tick.GetTree().GetID(), this.GetID())
+	this.watch(tick)
+}
+
+// watch watches the key for the node, the cancel func being kept off the
+// node memory (see AddNodeWatch).
+func (this *ObserveKey) watch(tick *Tick) {
+	blackboard, treeID, nodeID := tick.Blackboard, tick.GetTree().GetID(), this.GetID()
+	cancel := blackboard.WatchChanges(this.key, "", "", func(key string, old, new interface{}) {
+		if this.operator != "" {
+			if ok, err := Compare(new, this.operator, this.value); err != nil || !ok {
+				return
+			}
+		}
+		blackboard.Set("triggered", true, treeID, nodeID)
+	})
+	blackboard.AddNodeWatch(treeID, nodeID, cancel)
+}
+
+/**
+ * Close method.
+ * @method close
+ * @param {Tick} tick A tick instance.
+**/
+func (this *ObserveKey) OnClose(tick *Tick) {
+	tick.Blackboard.UnwatchNode(tick.GetTree().GetID(), this.GetID())
+}
+
+func (this *ObserveKey) triggered(tick *Tick) bool {
+	return tick.Blackboard.GetBool("triggered", tick.GetTree().GetID(), this.GetID())
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (this *ObserveKey) OnTick(tick *Tick) b3.Status {
+	if !this.HasChild() {
+		return b3.ERROR
+	}
+	// open in a restored memory, without its watch
+	if !tick.Blackboard.HasNodeWatches(tick.GetTree().GetID(), this.GetID()) {
+		this.watch(tick)
+	}
+	if this.triggered(tick) {
+		this.HaltChild(tick)
+		return this.status
+	}
+	var status = this.TickChild(tick)
+	if status == b3.RUNNING && this.triggered(tick) {
+		this.HaltChild(tick)
+		return this.status
+	}
+	return status
+}
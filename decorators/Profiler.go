@@ -0,0 +1,103 @@
+package decorators
+
+import (
+	"time"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * The Profiler decorator measures its child subtree: the wall time of each
+ * of its ticks and the number of nodes it entered. Every sample is sent
+ * to the profile sink of the tree (see BehaviorTree.SetProfileSink) and
+ * accumulated in a ProfileStats, stored in the global memory of the
+ * blackboard under the key property, or in the node memory ("stats")
+ * without one. The child status is returned unchanged.
+ *
+ * Wall time is measured with the real clock, whatever the clock of the
+ * blackboard, so it must only feed statistics, not the behavior.
+ *
+ * @module b3
+ * @class Profiler
+ * @extends DecoratorHelper
+**/
+type Profiler struct {
+	DecoratorHelper
+	key  string
+	name string
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **key**  (*String*) Global memory key of the statistics.
+ * - **name** (*String*) Name of the samples, the title by default.
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *Profiler) Initialize(setting *BTNodeCfg) {
+	this.DecoratorHelper.Initialize(setting)
+	this.key = ""
+	if _, ok := setting.Properties["key"]; ok {
+		this.key = setting.GetPropertyAsString("key")
+	}
+	this.name = setting.Title
+	if _, ok := setting.Properties["name"]; ok {
+		this.name = setting.GetPropertyAsString("name")
+	}
+}
+
+// Stats returns the statistics of the subtree for the blackboard of tick.
+func (this *Profiler) Stats(tick *Tick) ProfileStats {
+	var stats *ProfileStats
+	if this.key != "" {
+		stats, _ = tick.Blackboard.GetMem(this.key).(*ProfileStats)
+	} else {
+		stats, _ = tick.Blackboard.Get("stats", tick.GetTree().GetID(), this.GetID()).(*ProfileStats)
+	}
+	if stats == nil {
+		return ProfileStats{}
+	}
+	return *stats
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (this *Profiler) OnTick(tick *Tick) b3.Status {
+	if !this.HasChild() {
+		return b3.ERROR
+	}
+	nodes := tick.NodeCount()
+	start := time.Now()
+	var status = this.TickChild(tick)
+	sample := ProfileSample{
+		Name:     this.name,
+		TreeID:   tick.GetTree().GetID(),
+		NodeID:   this.GetID(),
+		Duration: time.Since(start),
+		Nodes:    tick.NodeCount() - nodes,
+		Status:   status,
+	}
+
+	stats := this.Stats(tick)
+	stats.Add(sample)
+	if this.key != "" {
+		tick.Blackboard.SetMem(this.key, &stats)
+	} else {
+		tick.Blackboard.Set("stats", &stats, sample.TreeID, sample.NodeID)
+	}
+	if sink := tick.GetTree().ProfileSink(); sink != nil {
+		sink.Sample(sample)
+	}
+	return status
+}
@@ -0,0 +1,91 @@
+package decorators
+
+import (
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * RepeatUntilBlackboardFlag is a decorator that repeats its child until a
+ * key of the global memory of the blackboard passes a check, given by the
+ * key, operator and value properties as for BlackboardCondition (by
+ * default, until the key is true). The check is made before each
+ * iteration: once it passes the decorator returns `SUCCESS`.
+ *
+ * Unlike Repeater, one iteration at most runs per tick and the decorator
+ * returns `RUNNING` in between, so the flag may be set by the game between
+ * ticks. The child returning `ERROR` stops the loop; an optional maxLoop
+ * bounds the iterations, the last child status being returned when it is
+ * reached.
+ *
+ * @module b3
+ * @class RepeatUntilBlackboardFlag
+ * @extends DecoratorHelper
+**/
+type RepeatUntilBlackboardFlag struct {
+	DecoratorHelper
+	check   keyCheck
+	maxLoop int
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **key**      (*String*)  Key of the global memory to check.
+ * - **operator** (*String*)  Comparison operator, "==" by default.
+ * - **value**    (*Object*)  Value compared to the key, true by default.
+ * - **maxLoop**  (*Integer*) Maximum number of iterations, unbounded by
+ *                            default.
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *RepeatUntilBlackboardFlag) Initialize(setting *BTNodeCfg) {
+	this.DecoratorHelper.Initialize(setting)
+	this.check.init(setting, "RepeatUntilBlackboardFlag", true)
+	this.maxLoop = -1
+	if _, ok := setting.Properties["maxLoop"]; ok {
+		this.maxLoop = setting.GetPropertyAsInt("maxLoop")
+	}
+}
+
+/**
+ * Open method.
+ * @method open
+ * @param {Tick} tick A tick instance.
+**/
+func (this *RepeatUntilBlackboardFlag) OnOpen(tick *Tick) {
+	tick.Blackboard.Set("i", 0, tick.GetTree().GetID(), this.GetID())
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (this *RepeatUntilBlackboardFlag) OnTick(tick *Tick) b3.Status {
+	if !this.HasChild() {
+		return b3.ERROR
+	}
+	if this.check.holds(tick) {
+		return b3.SUCCESS
+	}
+	var status = this.TickChild(tick)
+	if status == b3.RUNNING || status == b3.ERROR {
+		return status
+	}
+	var i = tick.Blackboard.GetInt("i", tick.GetTree().GetID(), this.GetID()) + 1
+	tick.Blackboard.Set("i", i, tick.GetTree().GetID(), this.GetID())
+	if this.check.holds(tick) {
+		return b3.SUCCESS
+	}
+	if this.maxLoop >= 0 && i >= this.maxLoop {
+		return status
+	}
+	return b3.RUNNING
+}
@@ -0,0 +1,72 @@
+package decorators
+
+import (
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * The RunOnce decorator executes its child until it finishes once (returns
+ * anything but `RUNNING`), then never again for the lifetime of the
+ * blackboard: it returns the status property, or the status the child
+ * finished with when it is not set. Useful for one-shot setup branches.
+ *
+ * The flag is kept in the node memory, so it is per agent and per tree.
+ *
+ * @module b3
+ * @class RunOnce
+ * @extends DecoratorHelper
+**/
+type RunOnce struct {
+	DecoratorHelper
+	status b3.Status
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **status** (*String*) Status returned once the child ran: "success",
+ *                         "failure", "running" or "error".
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *RunOnce) Initialize(setting *BTNodeCfg) {
+	this.DecoratorHelper.Initialize(setting)
+	this.status = 0
+	if _, ok := setting.Properties["status"]; ok {
+		status, err := b3.ParseStatus(setting.GetPropertyAsString("status"))
+		if err != nil {
+			panic("RunOnce decorator: " + err.Error())
+		}
+		this.status = status
+	}
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (this *RunOnce) OnTick(tick *Tick) b3.Status {
+	if !this.HasChild() {
+		return b3.ERROR
+	}
+	treeID := tick.GetTree().GetID()
+	if done, ok := tick.Blackboard.Get("done", treeID, this.GetID()).(b3.Status); ok {
+		if this.status != 0 {
+			return this.status
+		}
+		return done
+	}
+	var status = this.TickChild(tick)
+	if status != b3.RUNNING {
+		tick.Blackboard.Set("done", status, treeID, this.GetID())
+	}
+	return status
+}
@@ -0,0 +1,83 @@
+package decorators
+
+import (
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * The Timeout decorator fails when its child runs for too long. Unlike
+ * MaxTime, which only reports the failure and leaves the child open, it
+ * halts the child once the timeout expired: the running subtree is
+ * closed, deepest node first, so its OnClose hooks run and its node
+ * memory does not linger. The child is not ticked after the timeout.
+ *
+ * The time is read from the clock of the blackboard (see SetClock).
+ *
+ * @module b3
+ * @class Timeout
+ * @extends DecoratorHelper
+**/
+type Timeout struct {
+	DecoratorHelper
+	milliseconds int64
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **milliseconds** (*Integer*) Maximum time, in milliseconds, the child
+ *                                can run.
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *Timeout) Initialize(setting *BTNodeCfg) {
+	this.DecoratorHelper.Initialize(setting)
+	this.milliseconds = setting.GetPropertyAsInt64("milliseconds")
+	if this.milliseconds < 1 {
+		panic("milliseconds parameter in Timeout decorator is an obligatory parameter")
+	}
+}
+
+/**
+ * Open method.
+ * @method open
+ * @param {Tick} tick A tick instance.
+**/
+func (this *Timeout) OnOpen(tick *Tick) {
+	var startTime int64 = tick.Now().UnixNano() / 1000000
+	tick.Blackboard.Set("startTime", startTime, tick.GetTree().GetID(), this.GetID())
+}
+
+func (this *Timeout) expired(tick *Tick) bool {
+	var currTime int64 = tick.Now().UnixNano() / 1000000
+	var startTime int64 = tick.Blackboard.GetInt64("startTime", tick.GetTree().GetID(), this.GetID())
+	return currTime-startTime >= this.milliseconds
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (this *Timeout) OnTick(tick *Tick) b3.Status {
+	if !this.HasChild() {
+		return b3.ERROR
+	}
+	if this.expired(tick) {
+		this.HaltChild(tick)
+		return b3.FAILURE
+	}
+	var status = this.TickChild(tick)
+	if status == b3.RUNNING && this.expired(tick) {
+		this.HaltChild(tick)
+		return b3.FAILURE
+	}
+	return status
+}
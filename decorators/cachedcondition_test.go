@@ -0,0 +1,65 @@
+package decorators_test
+
+import (
+	"testing"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+	"github.com/youngtrips/behavior3go/loader"
+)
+
+func TestCachedConditionInvalidation(t *testing.T) {
+	evaluations := 0
+	maps := b3.NewRegisterStructMaps()
+	RegisterCondition(maps, "HasTarget", func(tick *Tick, cfg *BTNodeCfg) bool {
+		evaluations++
+		return tick.Blackboard.GetMem("target") != nil
+	})
+	treeConfig, err := LoadTreeCfgFromBytes([]byte(`{
+		"id": "t", "title": "cached", "root": "c",
+		"nodes": {
+			"c": {"id": "c", "name": "CachedCondition", "category": "decorator", "child": "h",
+				"properties": {"keys": "target"}},
+			"h": {"id": "h", "name": "HasTarget", "category": "condition"}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := loader.TryCreateBevTreeFromConfig(treeConfig, maps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	board := NewBlackboard(nil)
+
+	steps := []struct {
+		name        string
+		before      func()
+		want        b3.Status
+		evaluations int
+	}{
+		{"first tick evaluates", func() {}, b3.FAILURE, 1},
+		{"cached", func() {}, b3.FAILURE, 1},
+		{"unrelated key", func() { board.SetMem("other", 1) }, b3.FAILURE, 1},
+		{"watched key", func() { board.SetMem("target", "orc") }, b3.SUCCESS, 2},
+		{"cached again", func() {}, b3.SUCCESS, 2},
+		{"abort drops the cache", func() { tree.Abort(0, board) }, b3.SUCCESS, 3},
+		{"watched after abort", func() { board.SetMem("target", nil) }, b3.FAILURE, 4},
+		{"cached after abort", func() {}, b3.FAILURE, 4},
+	}
+	for _, step := range steps {
+		step.before()
+		if status := tree.Tick(0, board); status != step.want {
+			t.Errorf("%s: status %v, want %v", step.name, status, step.want)
+		}
+		if evaluations != step.evaluations {
+			t.Errorf("%s: %d evaluations, want %d", step.name, evaluations, step.evaluations)
+		}
+	}
+
+	tree.Abort(0, board)
+	if board.HasNodeWatches(tree.GetID(), "c") {
+		t.Error("watches left after abort")
+	}
+}
@@ -0,0 +1,68 @@
+package decorators_test
+
+import (
+	"testing"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+	"github.com/youngtrips/behavior3go/loader"
+	"github.com/youngtrips/behavior3go/snapshot"
+)
+
+func loadTree(t *testing.T, data string) *BehaviorTree {
+	t.Helper()
+	treeConfig, err := LoadTreeCfgFromBytes([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := loader.TryCreateBevTreeFromConfig(treeConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+const observeTree = `{
+	"id": "t", "title": "observe", "root": "o",
+	"nodes": {
+		"o": {"id": "o", "name": "ObserveKey", "category": "decorator", "child": "r",
+			"properties": {"key": "alarm"}},
+		"r": {"id": "r", "name": "Runner", "category": "action"}
+	}
+}`
+
+func TestObserveKeyInterrupts(t *testing.T) {
+	tree := loadTree(t, observeTree)
+	board := NewBlackboard(nil)
+	if status := tree.Tick(0, board); status != b3.RUNNING {
+		t.Fatal("tick 1:", status)
+	}
+	// the watch must not reach the memory, which snapshots encode
+	if _, err := snapshot.SaveAgent("a", board, tree); err != nil {
+		t.Fatal("save while observing:", err)
+	}
+	if status := tree.Tick(0, board); status != b3.RUNNING {
+		t.Fatal("tick 2:", status)
+	}
+	board.SetMem("alarm", true)
+	if status := tree.Tick(0, board); status != b3.FAILURE {
+		t.Fatal("tick after change:", status)
+	}
+	if board.HasNodeWatches(tree.GetID(), "o") {
+		t.Error("watch left after close")
+	}
+}
+
+func TestObserveKeyAbortUnwatches(t *testing.T) {
+	tree := loadTree(t, observeTree)
+	board := NewBlackboard(nil)
+	tree.Tick(0, board)
+	tree.Abort(0, board)
+	if board.HasNodeWatches(tree.GetID(), "o") {
+		t.Error("watch left after abort")
+	}
+	if status := tree.Tick(0, board); status != b3.RUNNING {
+		t.Fatal("tick after abort:", status)
+	}
+}
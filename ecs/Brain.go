@@ -0,0 +1,41 @@
+package ecs
+
+import (
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * Brain is the AI component attached to an entity. It pairs the (shared,
+ * stateless) behavior tree with the entity's own blackboard, which holds
+ * all of the execution state.
+ *
+ * Most ECS libraries store components by value or by pointer; store the
+ * *Brain so the System can update `Status` in place.
+ *
+ * @class Brain
+**/
+type Brain struct {
+	Tree       *BehaviorTree
+	Blackboard *Blackboard
+
+	//上一次Tick的返回值
+	Status b3.Status
+
+	//为true时System跳过该实体
+	Disabled bool
+}
+
+// NewBrain creates a brain running tree with a fresh blackboard.
+func NewBrain(tree *BehaviorTree) *Brain {
+	return &Brain{
+		Tree:       tree,
+		Blackboard: NewBlackboard(nil),
+	}
+}
+
+// Tick runs one tick of the tree with entity as the tick target.
+func (this *Brain) Tick(entity interface{}) b3.Status {
+	this.Status = this.Tree.Tick(entity, this.Blackboard)
+	return this.Status
+}
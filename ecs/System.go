@@ -0,0 +1,117 @@
+package ecs
+
+/**
+ * Query enumerates every entity that carries a Brain. It is the only glue
+ * needed between this package and an ECS library: implement it with the
+ * library's own query/view/filter and pass it to NewSystem. For example,
+ * with a query over (Entity, *Brain):
+ *
+ *     query := func(each func(entity interface{}, brain *ecs.Brain)) {
+ *         q := world.Query(brainFilter)
+ *         for q.Next() {
+ *             each(q.Entity(), q.Get())
+ *         }
+ *     }
+ *
+ * The entity handle passed to `each` becomes Tick.Target, so custom nodes
+ * can resolve the entity's other components with tick.GetTarget().
+**/
+type Query func(each func(entity interface{}, brain *Brain))
+
+/**
+ * System ticks every AI entity once per Update. Register it with the ECS
+ * scheduler (or call Update from the game loop) once per frame.
+ *
+ * @class System
+**/
+type System struct {
+	query Query
+
+	//可选，把实体句柄转换成Tick的target
+	targetFunc func(entity interface{}) interface{}
+}
+
+func NewSystem(query Query) *System {
+	return &System{query: query}
+}
+
+/**
+ * SetTargetFunc overrides what is passed as Tick.Target. By default the
+ * entity handle itself is used; set this when nodes expect a richer object
+ * (e.g. a wrapper holding the world and the entity).
+**/
+func (this *System) SetTargetFunc(f func(entity interface{}) interface{}) {
+	this.targetFunc = f
+}
+
+// Update ticks all enabled brains returned by the query.
+func (this *System) Update() {
+	this.query(func(entity interface{}, brain *Brain) {
+		if brain == nil || brain.Disabled || brain.Tree == nil {
+			return
+		}
+		target := entity
+		if this.targetFunc != nil {
+			target = this.targetFunc(entity)
+		}
+		brain.Tick(target)
+	})
+}
+
+/**
+ * Store is a minimal entity -> Brain map implementing Query, for games that
+ * do not use an ECS library or for tests. Entities are ticked in insertion
+ * order. It is not safe for concurrent use.
+ *
+ * @class Store
+**/
+type Store struct {
+	brains   map[interface{}]*Brain
+	entities []interface{}
+}
+
+func NewStore() *Store {
+	return &Store{brains: make(map[interface{}]*Brain)}
+}
+
+// Add attaches brain to entity, replacing any existing one.
+func (this *Store) Add(entity interface{}, brain *Brain) {
+	if _, ok := this.brains[entity]; !ok {
+		this.entities = append(this.entities, entity)
+	}
+	this.brains[entity] = brain
+}
+
+// Remove detaches the brain from entity.
+func (this *Store) Remove(entity interface{}) {
+	if _, ok := this.brains[entity]; !ok {
+		return
+	}
+	delete(this.brains, entity)
+	for i, e := range this.entities {
+		if e == entity {
+			this.entities = append(this.entities[:i], this.entities[i+1:]...)
+			break
+		}
+	}
+}
+
+func (this *Store) Get(entity interface{}) *Brain {
+	return this.brains[entity]
+}
+
+func (this *Store) Len() int {
+	return len(this.entities)
+}
+
+// Each implements Query.
+func (this *Store) Each(each func(entity interface{}, brain *Brain)) {
+	for _, e := range this.entities {
+		each(e, this.brains[e])
+	}
+}
+
+// NewStoreSystem is a shortcut for NewSystem(store.Each).
+func NewStoreSystem(store *Store) *System {
+	return NewSystem(store.Each)
+}
@@ -0,0 +1,452 @@
+/*
+Package expr is a small expression engine for the nodes configured with an
+expression over blackboard keys, e.g. the ExpressionGuard decorator:
+
+	hp < 30 && ammo > 0
+	name == "boss" || (level + 2) * 10 >= threshold
+
+or the Expression action, which can also assign the result to a key (see
+ParseAssignment):
+
+	damage = atk * 2 - def
+
+Operators, by increasing precedence: ||, &&, comparisons (== != < <= > >=,
+see core.Compare), + and -, * / and %, unary - and !. Operands are numbers,
+strings in single or double quotes, true, false, nil, parentheses and
+identifiers, possibly dotted ("enemy.hp"), looked up when evaluating. All
+the numbers are float64; + also concatenates strings; && and || short
+circuit and take the truth of their operands (false, nil, 0 and "" are
+false).
+*/
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/youngtrips/behavior3go/core"
+)
+
+// Lookup returns the value of an identifier, nil if it is not set.
+type Lookup func(name string) interface{}
+
+// Expr is a parsed expression, safe for concurrent use.
+type Expr struct {
+	src  string
+	root node
+}
+
+// Parse parses src.
+func Parse(src string) (*Expr, error) {
+	p := &parser{src: src}
+	p.next()
+	root, err := p.parseOr()
+	if err == nil && p.tok.kind != tokEOF {
+		err = p.errorf("unexpected %s", p.tok)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+/**
+ * ParseAssignment parses "target = expression", e.g.
+ * "damage = atk * 2 - def", returning the target identifier and the
+ * expression. The target is empty for a source without assignment.
+**/
+func ParseAssignment(src string) (target string, e *Expr, err error) {
+	p := &parser{src: src}
+	p.next()
+	if p.tok.kind == tokIdent {
+		name := p.tok.text
+		p.next()
+		if p.isOp("=") {
+			e, err := Parse(src[p.pos:])
+			if err != nil {
+				return "", nil, fmt.Errorf("expr %q: %v", src, err)
+			}
+			return name, e, nil
+		}
+	}
+	e, err = Parse(src)
+	return "", e, err
+}
+
+// MustParse is Parse panicking on error, for expressions of the code.
+func MustParse(src string) *Expr {
+	e, err := Parse(src)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+func (this *Expr) String() string {
+	return this.src
+}
+
+// Eval evaluates the expression, reading the identifiers with lookup.
+func (this *Expr) Eval(lookup Lookup) (interface{}, error) {
+	return this.root.eval(lookup)
+}
+
+// EvalBool evaluates the expression and returns its truth.
+func (this *Expr) EvalBool(lookup Lookup) (bool, error) {
+	v, err := this.Eval(lookup)
+	if err != nil {
+		return false, err
+	}
+	return Truth(v), nil
+}
+
+// Identifiers returns the identifiers read by the expression, in order of
+// appearance.
+func (this *Expr) Identifiers() []string {
+	var names []string
+	seen := make(map[string]bool)
+	var walk func(n node)
+	walk = func(n node) {
+		switch n := n.(type) {
+		case ident:
+			if !seen[string(n)] {
+				seen[string(n)] = true
+				names = append(names, string(n))
+			}
+		case unary:
+			walk(n.x)
+		case binary:
+			walk(n.x)
+			walk(n.y)
+		}
+	}
+	walk(this.root)
+	return names
+}
+
+// Truth tells whether v holds: false, nil, 0 and "" are false.
+func Truth(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	}
+	if f, ok := core.CoerceFloat64(v); ok {
+		return f != 0
+	}
+	return true
+}
+
+// ----------------------------------------------------------------------------
+// evaluation
+
+type node interface {
+	eval(lookup Lookup) (interface{}, error)
+}
+
+type literal struct{ v interface{} }
+
+func (this literal) eval(lookup Lookup) (interface{}, error) {
+	return this.v, nil
+}
+
+type ident string
+
+func (this ident) eval(lookup Lookup) (interface{}, error) {
+	if lookup == nil {
+		return nil, nil
+	}
+	return lookup(string(this)), nil
+}
+
+type unary struct {
+	op string
+	x  node
+}
+
+func (this unary) eval(lookup Lookup) (interface{}, error) {
+	v, err := this.x.eval(lookup)
+	if err != nil {
+		return nil, err
+	}
+	if this.op == "!" {
+		return !Truth(v), nil
+	}
+	f, ok := core.CoerceFloat64(v)
+	if !ok {
+		return nil, fmt.Errorf("-%v: not a number", v)
+	}
+	return -f, nil
+}
+
+type binary struct {
+	op   string
+	x, y node
+}
+
+func (this binary) eval(lookup Lookup) (interface{}, error) {
+	x, err := this.x.eval(lookup)
+	if err != nil {
+		return nil, err
+	}
+	switch this.op {
+	case "&&":
+		if !Truth(x) {
+			return false, nil
+		}
+		y, err := this.y.eval(lookup)
+		return Truth(y), err
+	case "||":
+		if Truth(x) {
+			return true, nil
+		}
+		y, err := this.y.eval(lookup)
+		return Truth(y), err
+	}
+	y, err := this.y.eval(lookup)
+	if err != nil {
+		return nil, err
+	}
+	switch this.op {
+	case "==", "!=", "<", "<=", ">", ">=":
+		return core.Compare(x, this.op, y)
+	case "+":
+		if sx, ok := x.(string); ok {
+			if sy, ok := y.(string); ok {
+				return sx + sy, nil
+			}
+		}
+	}
+	fx, xok := core.CoerceFloat64(x)
+	fy, yok := core.CoerceFloat64(y)
+	if !xok || !yok {
+		return nil, fmt.Errorf("%v %s %v: not numbers", x, this.op, y)
+	}
+	switch this.op {
+	case "+":
+		return fx + fy, nil
+	case "-":
+		return fx - fy, nil
+	case "*":
+		return fx * fy, nil
+	case "/":
+		if fy == 0 {
+			return nil, fmt.Errorf("%v / %v: division by zero", x, y)
+		}
+		return fx / fy, nil
+	case "%":
+		if fy == 0 {
+			return nil, fmt.Errorf("%v %% %v: division by zero", x, y)
+		}
+		return math.Mod(fx, fy), nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", this.op)
+}
+
+// ----------------------------------------------------------------------------
+// parsing
+
+const (
+	tokEOF = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind int
+	text string
+	pos  int
+}
+
+func (this token) String() string {
+	if this.kind == tokEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(this.text)
+}
+
+type parser struct {
+	src string
+	pos int
+	tok token
+	err error
+}
+
+func (this *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("expr %q: at %d: %s", this.src, this.tok.pos, fmt.Sprintf(format, args...))
+}
+
+// next reads the next token into tok.
+func (this *parser) next() {
+	src := this.src
+	for this.pos < len(src) && unicode.IsSpace(rune(src[this.pos])) {
+		this.pos++
+	}
+	start := this.pos
+	if start >= len(src) {
+		this.tok = token{tokEOF, "", start}
+		return
+	}
+	c := src[start]
+	switch {
+	case c >= '0' && c <= '9' || c == '.' && start+1 < len(src) && src[start+1] >= '0' && src[start+1] <= '9':
+		for this.pos < len(src) && (src[this.pos] >= '0' && src[this.pos] <= '9' || src[this.pos] == '.' ||
+			src[this.pos] == 'e' || src[this.pos] == 'E' ||
+			(src[this.pos] == '-' || src[this.pos] == '+') && (src[this.pos-1] == 'e' || src[this.pos-1] == 'E')) {
+			this.pos++
+		}
+		this.tok = token{tokNumber, src[start:this.pos], start}
+	case c == '_' || unicode.IsLetter(rune(c)):
+		for this.pos < len(src) && (src[this.pos] == '_' || src[this.pos] == '.' ||
+			unicode.IsLetter(rune(src[this.pos])) || unicode.IsDigit(rune(src[this.pos]))) {
+			this.pos++
+		}
+		this.tok = token{tokIdent, src[start:this.pos], start}
+	case c == '"' || c == '\'':
+		var sb strings.Builder
+		this.pos++
+		for {
+			if this.pos >= len(src) {
+				this.tok = token{tokString, "", start}
+				this.err = fmt.Errorf("expr %q: at %d: unterminated string", src, start)
+				return
+			}
+			ch := src[this.pos]
+			this.pos++
+			if ch == c {
+				break
+			}
+			if ch == '\\' && this.pos < len(src) {
+				ch = src[this.pos]
+				this.pos++
+			}
+			sb.WriteByte(ch)
+		}
+		this.tok = token{tokString, sb.String(), start}
+	default:
+		for _, op := range []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "+", "-", "*", "/", "%", "!", "(", ")", "="} {
+			if strings.HasPrefix(src[start:], op) {
+				this.pos += len(op)
+				this.tok = token{tokOp, op, start}
+				return
+			}
+		}
+		this.tok = token{tokOp, string(c), start}
+		this.err = fmt.Errorf("expr %q: at %d: unexpected %q", src, start, string(c))
+		this.pos++
+	}
+}
+
+func (this *parser) isOp(ops ...string) bool {
+	if this.tok.kind != tokOp {
+		return false
+	}
+	for _, op := range ops {
+		if this.tok.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+// binaryLevel parses operands with sub separated by ops, left associative.
+func (this *parser) binaryLevel(sub func() (node, error), ops ...string) (node, error) {
+	x, err := sub()
+	if err != nil {
+		return nil, err
+	}
+	for this.isOp(ops...) {
+		op := this.tok.text
+		this.next()
+		y, err := sub()
+		if err != nil {
+			return nil, err
+		}
+		x = binary{op, x, y}
+	}
+	return x, nil
+}
+
+func (this *parser) parseOr() (node, error) {
+	return this.binaryLevel(this.parseAnd, "||")
+}
+
+func (this *parser) parseAnd() (node, error) {
+	return this.binaryLevel(this.parseComparison, "&&")
+}
+
+func (this *parser) parseComparison() (node, error) {
+	return this.binaryLevel(this.parseAdditive, "==", "!=", "<", "<=", ">", ">=")
+}
+
+func (this *parser) parseAdditive() (node, error) {
+	return this.binaryLevel(this.parseMultiplicative, "+", "-")
+}
+
+func (this *parser) parseMultiplicative() (node, error) {
+	return this.binaryLevel(this.parseUnary, "*", "/", "%")
+}
+
+func (this *parser) parseUnary() (node, error) {
+	if this.isOp("-", "!") {
+		op := this.tok.text
+		this.next()
+		x, err := this.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unary{op, x}, nil
+	}
+	return this.parsePrimary()
+}
+
+func (this *parser) parsePrimary() (node, error) {
+	if this.err != nil {
+		return nil, this.err
+	}
+	tok := this.tok
+	switch tok.kind {
+	case tokNumber:
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, this.errorf("bad number %s", tok)
+		}
+		this.next()
+		return literal{f}, nil
+	case tokString:
+		this.next()
+		return literal{tok.text}, nil
+	case tokIdent:
+		this.next()
+		switch tok.text {
+		case "true":
+			return literal{true}, nil
+		case "false":
+			return literal{false}, nil
+		case "nil", "null":
+			return literal{nil}, nil
+		}
+		return ident(tok.text), nil
+	}
+	if this.isOp("(") {
+		this.next()
+		x, err := this.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !this.isOp(")") {
+			return nil, this.errorf("expected \")\", got %s", this.tok)
+		}
+		this.next()
+		return x, nil
+	}
+	return nil, this.errorf("unexpected %s", tok)
+}
@@ -0,0 +1,249 @@
+package hotreload
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	b3 "github.com/youngtrips/behavior3go"
+	"github.com/youngtrips/behavior3go/config"
+	"github.com/youngtrips/behavior3go/core"
+	"github.com/youngtrips/behavior3go/loader"
+)
+
+// 编辑器保存时会连续产生多个事件，合并后再重新加载
+const DefaultDebounce = 200 * time.Millisecond
+
+/**
+ * Watcher reloads .b3 project files (behavior3editor raw projects) when
+ * they change on disk and swaps the rebuilt trees into a TreeRegistry, so
+ * AI can be iterated on without restarting the server:
+ *
+ *     registry := core.NewTreeRegistry()
+ *     registry.UseForSubTrees()
+ *     w, _ := hotreload.NewWatcher(registry, customNodes)
+ *     w.Watch("ai/monster.b3")
+ *     go w.Run()
+ *
+ * WatchDir watches every .b3 file of a directory instead, including the
+ * ones created later.
+ *
+ * A changed project is validated by building all of its trees first; if
+ * any tree fails to build nothing is swapped and the error is reported, so
+ * a half saved file never reaches the game. The trees of a project are
+ * swapped together (see TreeRegistry.ReloadAll) with the migration set by
+ * SetMigrate, core.KeepTreeID by default, so agents keep their blackboard
+ * memory.
+ *
+ * @class Watcher
+**/
+type Watcher struct {
+	registry *core.TreeRegistry
+	maps     *b3.RegisterStructMaps
+	load     loader.Loader
+	migrate  core.MigrateFunc
+	debounce time.Duration
+
+	onReload func(path string, ids []string)
+	onError  func(path string, err error)
+
+	watcher *fsnotify.Watcher
+	mutex   sync.Mutex
+	files   map[string]bool
+	dirs    map[string]bool
+	// directories whose every .b3 file is watched
+	allDirs map[string]bool
+	timers  map[string]*time.Timer
+}
+
+// NewWatcher creates a watcher building trees with the custom nodes maps.
+func NewWatcher(registry *core.TreeRegistry, maps *b3.RegisterStructMaps) (*Watcher, error) {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return &Watcher{
+		registry: registry,
+		maps:     maps,
+		migrate:  core.KeepTreeID,
+		debounce: DefaultDebounce,
+		onError: func(path string, err error) {
+			if path == "" {
+				core.DefaultLogger.Error("hotreload: ", err)
+				return
+			}
+			core.DefaultLogger.Error("hotreload: ", path, ": ", err)
+		},
+		watcher: fw,
+		files:   make(map[string]bool),
+		dirs:    make(map[string]bool),
+		allDirs: make(map[string]bool),
+		timers:  make(map[string]*time.Timer),
+	}, nil
+}
+
+func (this *Watcher) SetMigrate(migrate core.MigrateFunc) {
+	this.migrate = migrate
+}
+
+func (this *Watcher) SetDebounce(d time.Duration) {
+	this.debounce = d
+}
+
+// SetLoader sets the options the trees are built with, e.g.
+// loader.Loader{PreserveIDs: true} to keep their config ids.
+func (this *Watcher) SetLoader(load loader.Loader) {
+	this.load = load
+}
+
+// OnReload sets a callback receiving the tree ids swapped in from path.
+func (this *Watcher) OnReload(f func(path string, ids []string)) {
+	this.onReload = f
+}
+
+// OnError replaces the default error handler, which logs the error to
+// core.DefaultLogger.
+func (this *Watcher) OnError(f func(path string, err error)) {
+	this.onError = f
+}
+
+/**
+ * Watch loads the project at path into the registry and reloads it on
+ * every change. The directory is watched rather than the file, because
+ * most editors save by writing a new file and renaming it.
+**/
+func (this *Watcher) Watch(path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if err := this.Reload(path); err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.files[path] = true
+	return this.addDir(dir)
+}
+
+// addDir watches dir; mutex must be held.
+func (this *Watcher) addDir(dir string) error {
+	if !this.dirs[dir] {
+		if err := this.watcher.Add(dir); err != nil {
+			return err
+		}
+		this.dirs[dir] = true
+	}
+	return nil
+}
+
+/**
+ * WatchDir loads every .b3 project of dir (not of its subdirectories) and
+ * reloads them on every change, loading the ones created later as well.
+ * It stops at the first project that fails to load.
+**/
+func (this *Watcher) WatchDir(dir string) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	this.mutex.Lock()
+	err = this.addDir(dir)
+	this.allDirs[dir] = true
+	this.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.b3"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err := this.Reload(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/**
+ * Reload builds every tree of the project at path with
+ * Loader.CreateProjectTrees of the loader set by SetLoader, the subtree
+ * references bound within the project, and, if all of them build, swaps
+ * them into the registry under their config ids as one set.
+**/
+func (this *Watcher) Reload(path string) error {
+	project, err := config.ReadRawProjectCfg(path)
+	if err != nil {
+		return fmt.Errorf("hotreload: %v", err)
+	}
+	trees, err := this.load.CreateProjectTrees(&project.Data, this.maps)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, len(project.Data.Trees))
+	for i := range project.Data.Trees {
+		ids[i] = project.Data.Trees[i].ID
+	}
+	this.registry.ReloadAll(trees, this.migrate)
+	if this.onReload != nil {
+		this.onReload(path, ids)
+	}
+	return nil
+}
+
+// Run processes file events until Close is called.
+func (this *Watcher) Run() {
+	for {
+		select {
+		case event, ok := <-this.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			this.schedule(filepath.Clean(event.Name))
+		case err, ok := <-this.watcher.Errors:
+			if !ok {
+				return
+			}
+			this.onError("", err)
+		}
+	}
+}
+
+func (this *Watcher) schedule(path string) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if !this.files[path] && !(this.allDirs[filepath.Dir(path)] && filepath.Ext(path) == ".b3") {
+		return
+	}
+	if t, ok := this.timers[path]; ok {
+		t.Reset(this.debounce)
+		return
+	}
+	this.timers[path] = time.AfterFunc(this.debounce, func() {
+		this.mutex.Lock()
+		delete(this.timers, path)
+		this.mutex.Unlock()
+		if err := this.Reload(path); err != nil {
+			this.onError(path, err)
+		}
+	})
+}
+
+// Close stops watching; Run returns.
+func (this *Watcher) Close() error {
+	this.mutex.Lock()
+	for _, t := range this.timers {
+		t.Stop()
+	}
+	this.timers = make(map[string]*time.Timer)
+	this.mutex.Unlock()
+	return this.watcher.Close()
+}
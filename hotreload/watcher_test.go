@@ -0,0 +1,116 @@
+package hotreload_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/core"
+	"github.com/youngtrips/behavior3go/hotreload"
+)
+
+// project is a .b3 project whose tree "main" runs the tree "sub", a leaf
+// named leaf.
+func project(leaf string) string {
+	return `{
+		"name": "ai",
+		"data": {
+			"id": "p", "selectedTree": "main",
+			"trees": [
+				{"id": "main", "title": "main", "root": "m",
+					"nodes": {"m": {"id": "m", "name": "sub", "category": "tree"}}},
+				{"id": "sub", "title": "sub", "root": "l",
+					"nodes": {"l": {"id": "l", "name": "` + leaf + `", "category": "action"}}}
+			]
+		}
+	}`
+}
+
+func writeProject(t *testing.T, path, data string) {
+	t.Helper()
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloadSwapsTheProject(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ai.b3")
+	writeProject(t, path, project("Succeeder"))
+	registry := NewTreeRegistry()
+	w, err := hotreload.NewWatcher(registry, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	var reloaded []string
+	w.OnReload(func(path string, ids []string) {
+		reloaded = ids
+	})
+	if err := w.Reload(path); err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded) != 2 || reloaded[0] != "main" || reloaded[1] != "sub" {
+		t.Fatal("reloaded ids:", reloaded)
+	}
+	board := NewBlackboard(nil)
+	first := registry.Get("main")
+	if status := first.Tick(0, board); status != b3.SUCCESS {
+		t.Fatal("tick before reload:", status)
+	}
+
+	// main runs the reloaded sub, not the one it was built with
+	writeProject(t, path, project("Failer"))
+	if err := w.Reload(path); err != nil {
+		t.Fatal(err)
+	}
+	main := registry.Get("main")
+	if main == first {
+		t.Fatal("main not swapped")
+	}
+	if main.GetID() != first.GetID() {
+		t.Error("tree id not kept by the default migration")
+	}
+	if status := main.Tick(0, board); status != b3.FAILURE {
+		t.Fatal("tick after reload:", status)
+	}
+
+	// a broken project leaves the registry as is
+	writeProject(t, path, project("NoSuchNode"))
+	if err := w.Reload(path); err == nil {
+		t.Fatal("reloaded a broken project")
+	}
+	if registry.Get("main") != main {
+		t.Error("broken project swapped in")
+	}
+}
+
+func TestWatchDirLoadsEveryProject(t *testing.T) {
+	dir := t.TempDir()
+	writeProject(t, filepath.Join(dir, "a.b3"), project("Succeeder"))
+	writeProject(t, filepath.Join(dir, "notes.txt"), "{")
+	registry := NewTreeRegistry()
+	w, err := hotreload.NewWatcher(registry, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	var paths []string
+	w.OnReload(func(path string, ids []string) {
+		paths = append(paths, filepath.Base(path))
+	})
+	if err := w.WatchDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 1 || paths[0] != "a.b3" {
+		t.Error("loaded:", paths)
+	}
+	if registry.Get("main") == nil || registry.Get("sub") == nil {
+		t.Error("trees not registered:", registry.IDs())
+	}
+
+	writeProject(t, filepath.Join(dir, "b.b3"), "{")
+	if err := w.WatchDir(dir); err == nil {
+		t.Error("broken project loaded")
+	}
+}
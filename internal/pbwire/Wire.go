@@ -0,0 +1,203 @@
+/*
+Package pbwire is a minimal protocol buffers wire format encoder/decoder,
+enough for the hand written messages of this module (see the .proto files
+next to their Go mirrors). It keeps the module free of generated code and
+third party dependencies while staying byte compatible with protoc output
+for the same schema.
+*/
+package pbwire
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Wire types.
+const (
+	TypeVarint  = 0
+	TypeFixed64 = 1
+	TypeBytes   = 2
+	TypeFixed32 = 5
+)
+
+var ErrTruncated = errors.New("pbwire: truncated message")
+
+// ------------------------Encoder-------------------------
+type Encoder struct {
+	buf []byte
+}
+
+func NewEncoder() *Encoder {
+	return &Encoder{}
+}
+
+func (this *Encoder) Bytes() []byte {
+	return this.buf
+}
+
+func (this *Encoder) tag(num int, typ int) {
+	this.buf = binary.AppendUvarint(this.buf, uint64(num)<<3|uint64(typ))
+}
+
+// Uint64 writes a varint field (uint32/uint64/int32/int64/enum).
+func (this *Encoder) Uint64(num int, v uint64) {
+	this.tag(num, TypeVarint)
+	this.buf = binary.AppendUvarint(this.buf, v)
+}
+
+// Int64 writes an int64 field (two's complement varint).
+func (this *Encoder) Int64(num int, v int64) {
+	this.Uint64(num, uint64(v))
+}
+
+// Sint64 writes a sint32/sint64 field (zigzag varint).
+func (this *Encoder) Sint64(num int, v int64) {
+	this.Uint64(num, uint64(v<<1)^uint64(v>>63))
+}
+
+func (this *Encoder) Bool(num int, v bool) {
+	if v {
+		this.Uint64(num, 1)
+	} else {
+		this.Uint64(num, 0)
+	}
+}
+
+func (this *Encoder) Double(num int, v float64) {
+	this.tag(num, TypeFixed64)
+	this.buf = binary.LittleEndian.AppendUint64(this.buf, math.Float64bits(v))
+}
+
+func (this *Encoder) Float(num int, v float32) {
+	this.tag(num, TypeFixed32)
+	this.buf = binary.LittleEndian.AppendUint32(this.buf, math.Float32bits(v))
+}
+
+func (this *Encoder) Data(num int, v []byte) {
+	this.tag(num, TypeBytes)
+	this.buf = binary.AppendUvarint(this.buf, uint64(len(v)))
+	this.buf = append(this.buf, v...)
+}
+
+func (this *Encoder) String(num int, v string) {
+	this.tag(num, TypeBytes)
+	this.buf = binary.AppendUvarint(this.buf, uint64(len(v)))
+	this.buf = append(this.buf, v...)
+}
+
+// Message writes an embedded message filled by f.
+func (this *Encoder) Message(num int, f func(e *Encoder)) {
+	sub := &Encoder{}
+	f(sub)
+	this.Data(num, sub.buf)
+}
+
+// Proto3 omits scalar fields holding their zero value; these helpers do
+// the same outside of oneofs.
+func (this *Encoder) OptString(num int, v string) {
+	if v != "" {
+		this.String(num, v)
+	}
+}
+
+func (this *Encoder) OptInt64(num int, v int64) {
+	if v != 0 {
+		this.Int64(num, v)
+	}
+}
+
+func (this *Encoder) OptUint64(num int, v uint64) {
+	if v != 0 {
+		this.Uint64(num, v)
+	}
+}
+
+func (this *Encoder) OptBool(num int, v bool) {
+	if v {
+		this.Bool(num, v)
+	}
+}
+
+// ------------------------Decoder-------------------------
+type Field struct {
+	Num  int
+	Type int
+	// varint, fixed64 and fixed32 values
+	Value uint64
+	// length delimited payload
+	Data []byte
+}
+
+func (this Field) Int64() int64 {
+	return int64(this.Value)
+}
+
+func (this Field) Sint64() int64 {
+	return int64(this.Value>>1) ^ -int64(this.Value&1)
+}
+
+func (this Field) Bool() bool {
+	return this.Value != 0
+}
+
+func (this Field) Double() float64 {
+	return math.Float64frombits(this.Value)
+}
+
+func (this Field) Float() float32 {
+	return math.Float32frombits(uint32(this.Value))
+}
+
+func (this Field) String() string {
+	return string(this.Data)
+}
+
+/**
+ * Parse calls f for every field of the message in data, in wire order.
+ * Unknown fields are passed to f as well and can simply be ignored.
+**/
+func Parse(data []byte, f func(field Field) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return ErrTruncated
+		}
+		data = data[n:]
+		field := Field{Num: int(key >> 3), Type: int(key & 7)}
+		switch field.Type {
+		case TypeVarint:
+			field.Value, n = binary.Uvarint(data)
+			if n <= 0 {
+				return ErrTruncated
+			}
+			data = data[n:]
+		case TypeFixed64:
+			if len(data) < 8 {
+				return ErrTruncated
+			}
+			field.Value = binary.LittleEndian.Uint64(data)
+			data = data[8:]
+		case TypeFixed32:
+			if len(data) < 4 {
+				return ErrTruncated
+			}
+			field.Value = uint64(binary.LittleEndian.Uint32(data))
+			data = data[4:]
+		case TypeBytes:
+			size, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < size {
+				return ErrTruncated
+			}
+			field.Data = data[n : n+int(size)]
+			data = data[n+int(size):]
+		default:
+			return fmt.Errorf("pbwire: unsupported wire type %d for field %d", field.Type, field.Num)
+		}
+		if err := f(field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+/*
+Package lint checks behavior tree configs against project conventions
+(maximum depth, children per composite, forbidden nodes, naming of the
+blackboard keys found in properties) and reports machine-readable findings.
+
+	cfg, _ := lint.LoadConfig("lint.json")
+	linter, _ := lint.New(cfg)
+	findings := linter.LintProject(project)
+
+The same checks run from the command line with cmd/b3lint.
+*/
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/youngtrips/behavior3go/config"
+	"github.com/youngtrips/behavior3go/core"
+)
+
+// Severities of a finding.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+	// disables a rule in Config.Severity
+	SeverityOff = "off"
+)
+
+// Finding is one problem found in a tree.
+type Finding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Tree     string `json:"tree"`
+	Node     string `json:"node,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (this Finding) String() string {
+	where := this.Path
+	if where == "" {
+		where = this.Tree
+	}
+	return fmt.Sprintf("%s: %s [%s] %s", this.Severity, where, this.Rule, this.Message)
+}
+
+/**
+ * Rule checks a tree and reports its findings. Rules built from a Config
+ * are added by New; custom rules can be added with Linter.Add.
+**/
+type Rule interface {
+	Name() string
+	Check(tree *config.BTTreeCfg, report *Reporter)
+}
+
+// Reporter collects the findings of one rule on one tree.
+type Reporter struct {
+	rule     string
+	severity string
+	tree     *config.BTTreeCfg
+	paths    map[string]string
+	findings []Finding
+}
+
+// Tree reports a finding about the whole tree.
+func (this *Reporter) Tree(format string, args ...interface{}) {
+	this.findings = append(this.findings, Finding{
+		Rule:     this.rule,
+		Severity: this.severity,
+		Tree:     this.tree.ID,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// Node reports a finding about the node id of the tree.
+func (this *Reporter) Node(id string, format string, args ...interface{}) {
+	this.findings = append(this.findings, Finding{
+		Rule:     this.rule,
+		Severity: this.severity,
+		Tree:     this.tree.ID,
+		Node:     id,
+		Path:     this.paths[id],
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+/**
+ * Config selects and tunes the rules of a project; it is usually loaded
+ * from a JSON file next to the project. Zero values disable the
+ * corresponding rule.
+**/
+type Config struct {
+	MaxDepth       int      `json:"maxDepth"`
+	MaxChildren    int      `json:"maxChildren"`
+	ForbiddenNodes []string `json:"forbiddenNodes"`
+	// regexp the blackboard keys found in properties must match
+	KeyPattern string `json:"keyPattern"`
+	// properties holding blackboard keys; by default any property whose
+	// name ends with "key" (case insensitive)
+	KeyProperties []string `json:"keyProperties"`
+	// severity by rule name, SeverityWarning by default
+	Severity map[string]string `json:"severity"`
+}
+
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("lint: %s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+/**
+ * Linter runs a set of rules over trees.
+ *
+ * @class Linter
+**/
+type Linter struct {
+	rules    []Rule
+	severity map[string]string
+}
+
+// New creates a linter with the rules enabled by cfg (which may be nil).
+func New(cfg *Config) (*Linter, error) {
+	this := &Linter{severity: make(map[string]string)}
+	if cfg == nil {
+		return this, nil
+	}
+	for rule, severity := range cfg.Severity {
+		this.severity[rule] = severity
+	}
+	if cfg.MaxDepth > 0 {
+		this.Add(&MaxDepth{Max: cfg.MaxDepth})
+	}
+	if cfg.MaxChildren > 0 {
+		this.Add(&MaxChildren{Max: cfg.MaxChildren})
+	}
+	if len(cfg.ForbiddenNodes) > 0 {
+		this.Add(&ForbiddenNodes{Names: cfg.ForbiddenNodes})
+	}
+	if cfg.KeyPattern != "" {
+		rule, err := NewKeyNaming(cfg.KeyPattern, cfg.KeyProperties)
+		if err != nil {
+			return nil, err
+		}
+		this.Add(rule)
+	}
+	return this, nil
+}
+
+func (this *Linter) Add(rule Rule) {
+	this.rules = append(this.rules, rule)
+}
+
+// SetSeverity overrides the severity of the findings of a rule.
+func (this *Linter) SetSeverity(rule string, severity string) {
+	this.severity[rule] = severity
+}
+
+// LintTree runs every rule over tree; findings are sorted by path.
+func (this *Linter) LintTree(tree *config.BTTreeCfg) []Finding {
+	paths := core.NodePaths(tree)
+	var findings []Finding
+	for _, rule := range this.rules {
+		severity := this.severity[rule.Name()]
+		if severity == SeverityOff {
+			continue
+		}
+		if severity == "" {
+			severity = SeverityWarning
+		}
+		r := &Reporter{rule: rule.Name(), severity: severity, tree: tree, paths: paths}
+		rule.Check(tree, r)
+		findings = append(findings, r.findings...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Path < findings[j].Path
+	})
+	return findings
+}
+
+func (this *Linter) LintProject(project *config.BTProjectCfg) []Finding {
+	var findings []Finding
+	for i := range project.Trees {
+		findings = append(findings, this.LintTree(&project.Trees[i])...)
+	}
+	return findings
+}
+
+// HasErrors tells whether findings contain an error.
+func HasErrors(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package lint
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/youngtrips/behavior3go/config"
+)
+
+// walk visits the nodes reachable from the root with their depth (1 for
+// the root), in child order.
+func walk(tree *config.BTTreeCfg, visit func(id string, node *config.BTNodeCfg, depth int)) {
+	seen := make(map[string]bool, len(tree.Nodes))
+	var rec func(id string, depth int)
+	rec = func(id string, depth int) {
+		node, ok := tree.Nodes[id]
+		if !ok || seen[id] {
+			return
+		}
+		seen[id] = true
+		visit(id, &node, depth)
+		if node.Child != "" {
+			rec(node.Child, depth+1)
+		}
+		for _, child := range node.Children {
+			rec(child, depth+1)
+		}
+	}
+	rec(tree.Root, 1)
+}
+
+// MaxDepth reports nodes nested deeper than Max (the root has depth 1).
+type MaxDepth struct {
+	Max int
+}
+
+func (this *MaxDepth) Name() string {
+	return "max-depth"
+}
+
+func (this *MaxDepth) Check(tree *config.BTTreeCfg, report *Reporter) {
+	walk(tree, func(id string, node *config.BTNodeCfg, depth int) {
+		// only report the first node past the limit on each branch
+		if depth == this.Max+1 {
+			report.Node(id, "node at depth %d, maximum is %d", depth, this.Max)
+		}
+	})
+}
+
+// MaxChildren reports composites with more than Max children.
+type MaxChildren struct {
+	Max int
+}
+
+func (this *MaxChildren) Name() string {
+	return "max-children"
+}
+
+func (this *MaxChildren) Check(tree *config.BTTreeCfg, report *Reporter) {
+	walk(tree, func(id string, node *config.BTNodeCfg, depth int) {
+		if len(node.Children) > this.Max {
+			report.Node(id, "%d children, maximum is %d", len(node.Children), this.Max)
+		}
+	})
+}
+
+// ForbiddenNodes reports nodes whose name is in Names.
+type ForbiddenNodes struct {
+	Names []string
+}
+
+func (this *ForbiddenNodes) Name() string {
+	return "forbidden-node"
+}
+
+func (this *ForbiddenNodes) Check(tree *config.BTTreeCfg, report *Reporter) {
+	walk(tree, func(id string, node *config.BTNodeCfg, depth int) {
+		for _, name := range this.Names {
+			if node.Name == name {
+				report.Node(id, "node %s is not allowed", name)
+			}
+		}
+	})
+}
+
+/**
+ * KeyNaming reports blackboard keys, found in the string values of key
+ * properties, not matching Pattern.
+**/
+type KeyNaming struct {
+	Pattern *regexp.Regexp
+	// properties holding keys, any property named "*key" if empty
+	Properties []string
+}
+
+func NewKeyNaming(pattern string, properties []string) (*KeyNaming, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyNaming{Pattern: re, Properties: properties}, nil
+}
+
+func (this *KeyNaming) Name() string {
+	return "key-naming"
+}
+
+func (this *KeyNaming) isKey(property string) bool {
+	if len(this.Properties) == 0 {
+		return strings.HasSuffix(strings.ToLower(property), "key")
+	}
+	for _, p := range this.Properties {
+		if p == property {
+			return true
+		}
+	}
+	return false
+}
+
+func (this *KeyNaming) Check(tree *config.BTTreeCfg, report *Reporter) {
+	walk(tree, func(id string, node *config.BTNodeCfg, depth int) {
+		names := make([]string, 0, len(node.Properties))
+		for name := range node.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			key, ok := node.Properties[name].(string)
+			if !ok || !this.isKey(name) || this.Pattern.MatchString(key) {
+				continue
+			}
+			report.Node(id, "property %s: key %q does not match %s", name, key, this.Pattern)
+		}
+	})
+}
@@ -0,0 +1,70 @@
+/*
+Package live streams the execution of behavior trees to a viewer (a
+behavior3editor fork or a companion tool) over WebSocket, so designers can
+watch their trees run against a live game.
+
+Every message is a JSON object with a "type" field.
+
+Server to viewer:
+
+	{"type":"tree","trees":[{"id":"<tree id>","title":"...","version":"<checksum>","config":{...}}]}
+	    sent on connect and whenever a tree is attached; config is the
+	    editor format (BTTreeCfg) the tree was loaded from and version
+	    changes whenever the structure of the tree does.
+	{"type":"status","tree":"<tree id>","agent":"npc-1","tick":42,
+	 "nodes":[{"id":"<node id>","status":"running"}, ...]}
+	    sent after every tick, listing the ticked nodes in the order they
+	    returned (children before their parent). Nodes whose title has
+	    placeholders ("Attack {target}") also carry the rendered "title".
+	{"type":"blackboard","tree":"<tree id>","agent":"npc-1","tick":42,
+	 "values":{"hp":30}}
+	    sent after every tick with the watched global memory keys.
+
+Viewer to server:
+
+	{"type":"watch","keys":["hp","target"]}
+	{"type":"unwatch","keys":["target"]}
+	{"type":"select","agent":"npc-1"}    // "" follows every agent
+*/
+package live
+
+import (
+	"encoding/json"
+
+	"github.com/youngtrips/behavior3go/config"
+)
+
+const (
+	MsgTree       = "tree"
+	MsgStatus     = "status"
+	MsgBlackboard = "blackboard"
+	MsgWatch      = "watch"
+	MsgUnwatch    = "unwatch"
+	MsgSelect     = "select"
+)
+
+type TreeInfo struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	// checksum of the tree structure, see BehaviorTree.Version
+	Version string            `json:"version"`
+	Config  *config.BTTreeCfg `json:"config,omitempty"`
+}
+
+type NodeStatus struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	// rendered title, only set for titles with placeholders
+	Title string `json:"title,omitempty"`
+}
+
+type Message struct {
+	Type   string                     `json:"type"`
+	Tree   string                     `json:"tree,omitempty"`
+	Agent  string                     `json:"agent,omitempty"`
+	Tick   int64                      `json:"tick,omitempty"`
+	Trees  []TreeInfo                 `json:"trees,omitempty"`
+	Nodes  []NodeStatus               `json:"nodes,omitempty"`
+	Keys   []string                   `json:"keys,omitempty"`
+	Values map[string]json.RawMessage `json:"values,omitempty"`
+}
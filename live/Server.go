@@ -0,0 +1,299 @@
+package live
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	b3 "github.com/youngtrips/behavior3go"
+	"github.com/youngtrips/behavior3go/core"
+)
+
+// 每个连接的发送队列长度，满了直接丢弃消息，不阻塞tick
+const sendQueueSize = 256
+
+const writeTimeout = 5 * time.Second
+
+/**
+ * Server is an http.Handler accepting viewer connections. Trees are made
+ * visible with Attach, which installs the server as the tree's debug
+ * object:
+ *
+ *     server := live.NewServer()
+ *     server.Attach(tree)
+ *     http.Handle("/b3/live", server)
+ *
+ * Ticks are never blocked by slow viewers: messages that do not fit in a
+ * viewer's queue are dropped.
+ *
+ * @class Server
+**/
+type Server struct {
+	upgrader  websocket.Upgrader
+	agentName func(tick *core.Tick) string
+
+	mutex   sync.Mutex
+	trees   []*core.BehaviorTree
+	clients map[*client]bool
+	nclient int32
+}
+
+func NewServer() *Server {
+	return &Server{
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+		agentName: func(tick *core.Tick) string {
+			return fmt.Sprint(tick.GetTarget())
+		},
+		clients: make(map[*client]bool),
+	}
+}
+
+// SetAgentName sets how an agent is named from its tick, the target by default.
+func (this *Server) SetAgentName(f func(tick *core.Tick) string) {
+	this.agentName = f
+}
+
+// SetCheckOrigin restricts which origins may connect; all by default.
+func (this *Server) SetCheckOrigin(f func(r *http.Request) bool) {
+	this.upgrader.CheckOrigin = f
+}
+
+/**
+ * Attach streams the execution of tree to the viewers. It replaces any
+ * debug object previously set on the tree.
+**/
+func (this *Server) Attach(tree *core.BehaviorTree) {
+	tree.SetDebug(&treeDebug{server: this, tree: tree, frames: make(map[*core.Tick]*frame)})
+
+	this.mutex.Lock()
+	this.trees = append(this.trees, tree)
+	this.mutex.Unlock()
+
+	this.broadcast(&Message{Type: MsgTree, Trees: []TreeInfo{treeInfo(tree)}})
+}
+
+func treeInfo(tree *core.BehaviorTree) TreeInfo {
+	return TreeInfo{ID: tree.GetID(), Title: tree.GetTitile(), Version: tree.Version(), Config: tree.Dump()}
+}
+
+func (this *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := this.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	c := &client{
+		conn:  conn,
+		send:  make(chan *Message, sendQueueSize),
+		watch: make(map[string]bool),
+	}
+
+	this.mutex.Lock()
+	infos := make([]TreeInfo, 0, len(this.trees))
+	for _, tree := range this.trees {
+		infos = append(infos, treeInfo(tree))
+	}
+	this.clients[c] = true
+	atomic.AddInt32(&this.nclient, 1)
+	this.mutex.Unlock()
+
+	c.push(&Message{Type: MsgTree, Trees: infos})
+	go c.writeLoop()
+	c.readLoop()
+
+	this.mutex.Lock()
+	delete(this.clients, c)
+	atomic.AddInt32(&this.nclient, -1)
+	this.mutex.Unlock()
+	close(c.send)
+}
+
+func (this *Server) broadcast(msg *Message) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	for c := range this.clients {
+		c.push(msg)
+	}
+}
+
+func (this *Server) hasClients() bool {
+	return atomic.LoadInt32(&this.nclient) > 0
+}
+
+// tick结束，发送节点状态和关注的黑板值
+func (this *Server) flush(tick *core.Tick, f *frame) {
+	agent := this.agentName(tick)
+	treeID := tick.GetTree().GetID()
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	for c := range this.clients {
+		keys := c.follows(agent)
+		if keys == nil {
+			continue
+		}
+		c.push(&Message{Type: MsgStatus, Tree: treeID, Agent: agent, Tick: f.seq, Nodes: f.nodes})
+		if len(keys) == 0 {
+			continue
+		}
+		values := make(map[string]json.RawMessage, len(keys))
+		for _, key := range keys {
+			values[key] = marshalValue(tick.Blackboard.GetMem(key))
+		}
+		c.push(&Message{Type: MsgBlackboard, Tree: treeID, Agent: agent, Tick: f.seq, Values: values})
+	}
+}
+
+// 在tick协程里序列化，避免和节点同时读写
+func marshalValue(v interface{}) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(v))
+	}
+	return data
+}
+
+// ------------------------client-------------------------
+type client struct {
+	conn *websocket.Conn
+	send chan *Message
+
+	mutex sync.Mutex
+	agent string
+	watch map[string]bool
+}
+
+func (this *client) push(msg *Message) {
+	select {
+	case this.send <- msg:
+	default:
+	}
+}
+
+/**
+ * follows returns nil if the client does not follow agent, otherwise the
+ * (possibly empty) list of watched keys.
+**/
+func (this *client) follows(agent string) []string {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if this.agent != "" && this.agent != agent {
+		return nil
+	}
+	keys := make([]string, 0, len(this.watch))
+	for key := range this.watch {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (this *client) readLoop() {
+	for {
+		var msg Message
+		if err := this.conn.ReadJSON(&msg); err != nil {
+			this.conn.Close()
+			return
+		}
+		this.mutex.Lock()
+		switch msg.Type {
+		case MsgWatch:
+			for _, key := range msg.Keys {
+				this.watch[key] = true
+			}
+		case MsgUnwatch:
+			for _, key := range msg.Keys {
+				delete(this.watch, key)
+			}
+		case MsgSelect:
+			this.agent = msg.Agent
+		}
+		this.mutex.Unlock()
+	}
+}
+
+func (this *client) writeLoop() {
+	for msg := range this.send {
+		this.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if err := this.conn.WriteJSON(msg); err != nil {
+			this.conn.Close()
+			return
+		}
+	}
+}
+
+// ------------------------treeDebug-------------------------
+type frame struct {
+	seq   int64
+	nodes []NodeStatus
+}
+
+/**
+ * treeDebug is the core.IDebug installed on attached trees. A tree may be
+ * ticked for several agents from several goroutines, so the statuses are
+ * collected per tick.
+**/
+type treeDebug struct {
+	server *Server
+	tree   *core.BehaviorTree
+	seq    int64
+
+	mutex  sync.Mutex
+	frames map[*core.Tick]*frame
+}
+
+func (this *treeDebug) frame(tick *core.Tick, create bool) *frame {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	f, ok := this.frames[tick]
+	if !ok && create {
+		f = &frame{seq: atomic.AddInt64(&this.seq, 1)}
+		this.frames[tick] = f
+	}
+	return f
+}
+
+func (this *treeDebug) isRoot(tick *core.Tick, node core.IBaseNode) bool {
+	return len(tick.SubTreeStack()) == 1 && node.GetID() == this.tree.GetRoot().GetID()
+}
+
+func (this *treeDebug) EnterNode(tick *core.Tick, node core.IBaseNode) {
+	if this.isRoot(tick, node) && this.server.hasClients() {
+		this.frame(tick, true)
+	}
+}
+
+func (this *treeDebug) OpenNode(tick *core.Tick, node core.IBaseNode) {
+}
+
+func (this *treeDebug) TickNode(tick *core.Tick, node core.IBaseNode, status b3.Status) {
+	if f := this.frame(tick, false); f != nil {
+		ns := NodeStatus{ID: node.GetID(), Status: status.String()}
+		if strings.Contains(node.GetTitle(), "{") {
+			ns.Title = tick.RenderTitle(node)
+		}
+		f.nodes = append(f.nodes, ns)
+	}
+}
+
+func (this *treeDebug) CloseNode(tick *core.Tick, node core.IBaseNode) {
+}
+
+func (this *treeDebug) ExitNode(tick *core.Tick, node core.IBaseNode) {
+	if !this.isRoot(tick, node) {
+		return
+	}
+	f := this.frame(tick, false)
+	if f == nil {
+		return
+	}
+	this.mutex.Lock()
+	delete(this.frames, tick)
+	this.mutex.Unlock()
+	this.server.flush(tick, f)
+}
@@ -0,0 +1,201 @@
+package live_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+	"github.com/youngtrips/behavior3go/live"
+	"github.com/youngtrips/behavior3go/loader"
+)
+
+func loadTree(t *testing.T, data string) *BehaviorTree {
+	t.Helper()
+	treeConfig, err := LoadTreeCfgFromBytes([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := loader.TryCreateBevTreeFromConfig(treeConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+const liveTree = `{
+	"id": "t", "title": "live", "root": "s",
+	"nodes": {
+		"s": {"id": "s", "name": "Sequence", "category": "composite", "children": ["a", "b"]},
+		"a": {"id": "a", "name": "Succeeder", "title": "Attack {target}", "category": "action"},
+		"b": {"id": "b", "name": "Succeeder", "category": "action"}
+	}
+}`
+
+// dial connects a viewer to server.
+func dial(t *testing.T, server *live.Server) *websocket.Conn {
+	t.Helper()
+	hs := httptest.NewServer(server)
+	t.Cleanup(hs.Close)
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(hs.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// read reads the next message, checking it is one text frame holding a
+// single JSON object.
+func read(t *testing.T, conn *websocket.Conn) *live.Message {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	kind, r, err := conn.NextReader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kind != websocket.TextMessage {
+		t.Fatal("frame type", kind)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var msg live.Message
+	if err := dec.Decode(&msg); err != nil {
+		t.Fatalf("%s: %v", data, err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		t.Fatalf("more than one object in %s", data)
+	}
+	return &msg
+}
+
+func send(t *testing.T, conn *websocket.Conn, msg *live.Message) {
+	t.Helper()
+	if err := conn.WriteJSON(msg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestViewerReceivesTreesAndStatuses(t *testing.T) {
+	server := live.NewServer()
+	tree := loadTree(t, liveTree)
+	server.Attach(tree)
+	conn := dial(t, server)
+
+	msg := read(t, conn)
+	if msg.Type != live.MsgTree || len(msg.Trees) != 1 {
+		t.Fatalf("first message %+v", msg)
+	}
+	info := msg.Trees[0]
+	if info.ID != tree.GetID() || info.Title != "live" || info.Version != tree.Version() || info.Config == nil || len(info.Config.Nodes) != 3 {
+		t.Errorf("tree info %+v", info)
+	}
+
+	board := NewBlackboard(nil)
+	board.SetMem("target", "orc")
+	if status := tree.Tick("npc-1", board); status != b3.SUCCESS {
+		t.Fatal(status)
+	}
+	msg = read(t, conn)
+	if msg.Type != live.MsgStatus || msg.Tree != tree.GetID() || msg.Agent != "npc-1" || msg.Tick != 1 {
+		t.Fatalf("status message %+v", msg)
+	}
+	want := []live.NodeStatus{
+		{ID: "a", Status: "success", Title: "Attack orc"},
+		{ID: "b", Status: "success"},
+		{ID: "s", Status: "success"},
+	}
+	if len(msg.Nodes) != len(want) {
+		t.Fatalf("nodes %+v", msg.Nodes)
+	}
+	for i := range want {
+		if msg.Nodes[i] != want[i] {
+			t.Errorf("node %d: %+v, want %+v", i, msg.Nodes[i], want[i])
+		}
+	}
+
+	// attaching a tree later is broadcast
+	other := loadTree(t, strings.Replace(liveTree, `"title": "live"`, `"title": "other"`, 1))
+	server.Attach(other)
+	msg = read(t, conn)
+	if msg.Type != live.MsgTree || len(msg.Trees) != 1 || msg.Trees[0].ID != other.GetID() {
+		t.Errorf("attach message %+v", msg)
+	}
+}
+
+func TestViewerWatchesAndSelects(t *testing.T) {
+	server := live.NewServer()
+	tree := loadTree(t, liveTree)
+	server.Attach(tree)
+	conn := dial(t, server)
+	read(t, conn)
+
+	board := NewBlackboard(nil)
+	board.SetMem("hp", 30)
+	board.SetMem("target", "orc")
+	send(t, conn, &live.Message{Type: live.MsgWatch, Keys: []string{"hp", "target"}})
+	// the requests are applied asynchronously: tick until the values follow
+	// the status of a tick
+	tree.Tick("npc-1", board)
+	if msg := read(t, conn); msg.Type != live.MsgStatus {
+		t.Fatalf("message %+v", msg)
+	}
+	var values map[string]json.RawMessage
+	for i := 0; values == nil; i++ {
+		if i == 100 {
+			t.Fatal("watch not applied")
+		}
+		tree.Tick("npc-1", board)
+		if msg := read(t, conn); msg.Type == live.MsgBlackboard {
+			values = msg.Values
+			// the status and values of the last tick
+			read(t, conn)
+			read(t, conn)
+		}
+	}
+	if string(values["hp"]) != "30" || string(values["target"]) != `"orc"` {
+		t.Errorf("values %s", values)
+	}
+
+	// applied in order: once target is unwatched, npc-2 is selected
+	send(t, conn, &live.Message{Type: live.MsgSelect, Agent: "npc-2"})
+	send(t, conn, &live.Message{Type: live.MsgUnwatch, Keys: []string{"target"}})
+	other := NewBlackboard(nil)
+	other.SetMem("hp", 10)
+	for i := 0; ; i++ {
+		if i == 100 {
+			t.Fatal("unwatch not applied")
+		}
+		tree.Tick("npc-2", other)
+		if msg := read(t, conn); msg.Type != live.MsgStatus || msg.Agent != "npc-2" {
+			t.Fatalf("message %+v", msg)
+		}
+		msg := read(t, conn)
+		if msg.Type != live.MsgBlackboard {
+			t.Fatalf("message %+v", msg)
+		}
+		if len(msg.Values) == 1 {
+			if string(msg.Values["hp"]) != "10" {
+				t.Errorf("values %s", msg.Values)
+			}
+			break
+		}
+	}
+
+	// npc-1 is no longer streamed
+	tree.Tick("npc-1", board)
+	tree.Tick("npc-2", other)
+	if msg := read(t, conn); msg.Agent != "npc-2" {
+		t.Errorf("message for %s", msg.Agent)
+	}
+}
@@ -1,12 +1,13 @@
 package loader
 
 import (
-	_ "fmt"
+	"fmt"
 	_ "reflect"
 
 	b3 "github.com/youngtrips/behavior3go"
 	. "github.com/youngtrips/behavior3go/actions"
 	. "github.com/youngtrips/behavior3go/composites"
+	. "github.com/youngtrips/behavior3go/conditions"
 	. "github.com/youngtrips/behavior3go/config"
 	. "github.com/youngtrips/behavior3go/core"
 	. "github.com/youngtrips/behavior3go/decorators"
@@ -15,31 +16,190 @@ import (
 func createBaseStructMaps() *b3.RegisterStructMaps {
 	st := b3.NewRegisterStructMaps()
 	//actions
-	st.Register("Error", &Error{})
-	st.Register("Failer", &Failer{})
-	st.Register("Runner", &Runner{})
-	st.Register("Succeeder", &Succeeder{})
-	st.Register("Wait", &Wait{})
-	st.Register("Log", &Log{})
+	st.RegisterFactory("Error", func() interface{} { return &Error{} })
+	st.RegisterFactory("EmitEvent", func() interface{} { return &EmitEvent{} })
+	st.RegisterFactory("Exec", func() interface{} { return &Exec{} })
+	st.RegisterFactory("Expression", func() interface{} { return &Expression{} })
+	st.RegisterFactory("Failer", func() interface{} { return &Failer{} })
+	st.RegisterFactory("RemoveBlackboardKey", func() interface{} { return &RemoveBlackboardKey{} })
+	st.RegisterFactory("ReturnStatus", func() interface{} { return &ReturnStatus{} })
+	st.RegisterFactory("Runner", func() interface{} { return &Runner{} })
+	st.RegisterFactory("StampTime", func() interface{} { return &StampTime{} })
+	st.RegisterFactory("Succeeder", func() interface{} { return &Succeeder{} })
+	st.RegisterFactory("Wait", func() interface{} { return &Wait{} })
+	st.RegisterFactory("WaitDelta", func() interface{} { return &WaitDelta{} })
+	st.RegisterFactory("WaitForEvent", func() interface{} { return &WaitForEvent{} })
+	st.RegisterFactory("HTTPRequest", func() interface{} { return &HTTPRequest{} })
+	st.RegisterFactory("Log", func() interface{} { return &Log{} })
+	st.RegisterFactory("RunTree", func() interface{} { return &RunTree{} })
+	st.RegisterFactory("StateMachine", func() interface{} { return &StateMachine{} })
 	//composites
-	st.Register("MemPriority", &MemPriority{})
-	st.Register("MemSequence", &MemSequence{})
-	st.Register("Priority", &Priority{})
-	st.Register("Sequence", &Sequence{})
+	st.RegisterFactory("Concurrent", func() interface{} { return &Concurrent{} })
+	st.RegisterFactory("MemPriority", func() interface{} { return &MemPriority{} })
+	st.RegisterFactory("MemSequence", func() interface{} { return &MemSequence{} })
+	st.RegisterFactory("Parallel", func() interface{} { return &Parallel{} })
+	st.RegisterFactory("Priority", func() interface{} { return &Priority{} })
+	st.RegisterFactory("RandomSequence", func() interface{} { return &RandomSequence{} })
+	st.RegisterFactory("ReactiveSelector", func() interface{} { return &ReactiveSelector{} })
+	st.RegisterFactory("Sequence", func() interface{} { return &Sequence{} })
+	st.RegisterFactory("Switch", func() interface{} { return &Switch{} })
+	st.RegisterFactory("UtilitySelector", func() interface{} { return &UtilitySelector{} })
+	st.RegisterFactory("WeightedRandomSelector", func() interface{} { return &WeightedRandomSelector{} })
+
+	//conditions
+	st.RegisterFactory("CooldownElapsed", func() interface{} { return &CooldownElapsed{} })
+	st.RegisterFactory("NumericRange", func() interface{} { return &NumericRange{} })
+	st.RegisterFactory("Schedule", func() interface{} { return &Schedule{} })
 
 	//decorators
-	st.Register("Inverter", &Inverter{})
-	st.Register("Limiter", &Limiter{})
-	st.Register("MaxTime", &MaxTime{})
-	st.Register("Repeater", &Repeater{})
-	st.Register("RepeatUntilFailure", &RepeatUntilFailure{})
-	st.Register("RepeatUntilSuccess", &RepeatUntilSuccess{})
+	st.RegisterFactory("BlackboardCondition", func() interface{} { return &BlackboardCondition{} })
+	st.RegisterFactory("CachedCondition", func() interface{} { return &CachedCondition{} })
+	st.RegisterFactory("CircuitBreaker", func() interface{} { return &CircuitBreaker{} })
+	st.RegisterFactory("Cooldown", func() interface{} { return &Cooldown{} })
+	st.RegisterFactory("ExpressionGuard", func() interface{} { return &ExpressionGuard{} })
+	st.RegisterFactory("Inverter", func() interface{} { return &Inverter{} })
+	st.RegisterFactory("Limiter", func() interface{} { return &Limiter{} })
+	st.RegisterFactory("MaxTime", func() interface{} { return &MaxTime{} })
+	st.RegisterFactory("MaxTimeDelta", func() interface{} { return &MaxTimeDelta{} })
+	st.RegisterFactory("ObserveKey", func() interface{} { return &ObserveKey{} })
+	st.RegisterFactory("Profiler", func() interface{} { return &Profiler{} })
+	st.RegisterFactory("Repeater", func() interface{} { return &Repeater{} })
+	st.RegisterFactory("RepeatUntilBlackboardFlag", func() interface{} { return &RepeatUntilBlackboardFlag{} })
+	st.RegisterFactory("RepeatUntilFailure", func() interface{} { return &RepeatUntilFailure{} })
+	st.RegisterFactory("RepeatUntilSuccess", func() interface{} { return &RepeatUntilSuccess{} })
+	st.RegisterFactory("RunOnce", func() interface{} { return &RunOnce{} })
+	st.RegisterFactory("Timeout", func() interface{} { return &Timeout{} })
 	return st
 }
 
-func CreateBevTreeFromConfig(config *BTTreeCfg, extMap *b3.RegisterStructMaps) *BehaviorTree {
+/**
+ * Loader builds trees from their configs with the options of one load;
+ * the package functions of the same names use the zero Loader. Being a
+ * value, loads with different options can run concurrently:
+ *
+ *     trees, err := loader.Loader{PreserveIDs: true}.CreateProjectTrees(project, maps)
+ *
+ * @class Loader
+**/
+type Loader struct {
+	// StableNodeIDs replaces the node ids of the editor with content
+	// derived ones, see config.StableNodeIDs.
+	StableNodeIDs bool
+	// PreserveIDs keeps the ids assigned by the editor verbatim: the tree
+	// gets the id of its config instead of a random one, and the node ids
+	// are not replaced (StableNodeIDs is ignored). The memory of the
+	// trees and nodes, which the blackboard keys by these ids, then stays
+	// valid across restarts and reloads, e.g. for persisted blackboards.
+	// Trees loaded twice from one config share their memory.
+	PreserveIDs bool
+}
+
+func (this Loader) CreateBevTreeFromConfig(config *BTTreeCfg, extMap *b3.RegisterStructMaps) *BehaviorTree {
+	if this.StableNodeIDs && !this.PreserveIDs {
+		config = StableNodeIDs(config)
+	}
 	baseMaps := createBaseStructMaps()
 	tree := NewBeTree()
+	if this.PreserveIDs && config.ID != "" {
+		tree.SetID(config.ID)
+	}
 	tree.Load(config, baseMaps, extMap)
 	return tree
 }
+
+// TryCreateBevTreeFromConfig is CreateBevTreeFromConfig returning load
+// failures (e.g. unregistered node names) as an error instead of panicking.
+// Node failures are returned as a *LoadError carrying the node path.
+func (this Loader) TryCreateBevTreeFromConfig(config *BTTreeCfg, extMap *b3.RegisterStructMaps) (tree *BehaviorTree, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			tree = nil
+			if e, ok := r.(*LoadError); ok {
+				err = e
+				return
+			}
+			err = fmt.Errorf("tree %s(%s): %v", config.Title, config.ID, r)
+		}
+	}()
+	return this.CreateBevTreeFromConfig(config, extMap), nil
+}
+
+func CreateBevTreeFromConfig(config *BTTreeCfg, extMap *b3.RegisterStructMaps) *BehaviorTree {
+	return Loader{}.CreateBevTreeFromConfig(config, extMap)
+}
+
+// TryCreateBevTreeFromConfig is Loader.TryCreateBevTreeFromConfig with the
+// default options.
+func TryCreateBevTreeFromConfig(config *BTTreeCfg, extMap *b3.RegisterStructMaps) (*BehaviorTree, error) {
+	return Loader{}.TryCreateBevTreeFromConfig(config, extMap)
+}
+
+/**
+ * CreateRunnerFromProject builds the trees of project listed by its run
+ * order (see BTProjectCfg.RunOrder) into a TreeRunner. Trees referenced as
+ * subtrees must still be made available with SetSubTreeLoadFunc.
+**/
+func (this Loader) CreateRunnerFromProject(project *BTProjectCfg, extMap *b3.RegisterStructMaps) (*TreeRunner, error) {
+	var trees []*BehaviorTree
+	for _, id := range project.RunOrder() {
+		var cfg *BTTreeCfg
+		for i := range project.Trees {
+			if project.Trees[i].ID == id {
+				cfg = &project.Trees[i]
+				break
+			}
+		}
+		if cfg == nil {
+			return nil, fmt.Errorf("project %s: no tree %s to run", project.ID, id)
+		}
+		tree, err := this.TryCreateBevTreeFromConfig(cfg, extMap)
+		if err != nil {
+			return nil, err
+		}
+		trees = append(trees, tree)
+	}
+	return NewTreeRunner(trees...), nil
+}
+
+// CreateRunnerFromProject is Loader.CreateRunnerFromProject with the
+// default options.
+func CreateRunnerFromProject(project *BTProjectCfg, extMap *b3.RegisterStructMaps) (*TreeRunner, error) {
+	return Loader{}.CreateRunnerFromProject(project, extMap)
+}
+
+// ValidateTreeCfg checks config against the base and custom nodes, see
+// core.ValidateConfig.
+func ValidateTreeCfg(config *BTTreeCfg, extMap *b3.RegisterStructMaps) error {
+	return ValidateConfig(config, createBaseStructMaps(), extMap)
+}
+
+// ValidateProjectCfg checks every tree of project, returning all their
+// problems as one LoadErrors.
+func ValidateProjectCfg(project *BTProjectCfg, extMap *b3.RegisterStructMaps) error {
+	var errs LoadErrors
+	baseMaps := createBaseStructMaps()
+	for i := range project.Trees {
+		if err := ValidateConfig(&project.Trees[i], baseMaps, extMap); err != nil {
+			errs = append(errs, err.(LoadErrors)...)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// CreateBevTreeStrict is TryCreateBevTreeFromConfig refusing the configs
+// ValidateTreeCfg finds problems in.
+func (this Loader) CreateBevTreeStrict(config *BTTreeCfg, extMap *b3.RegisterStructMaps) (*BehaviorTree, error) {
+	if err := ValidateTreeCfg(config, extMap); err != nil {
+		return nil, err
+	}
+	return this.TryCreateBevTreeFromConfig(config, extMap)
+}
+
+// CreateBevTreeStrict is Loader.CreateBevTreeStrict with the default
+// options.
+func CreateBevTreeStrict(config *BTTreeCfg, extMap *b3.RegisterStructMaps) (*BehaviorTree, error) {
+	return Loader{}.CreateBevTreeStrict(config, extMap)
+}
@@ -0,0 +1,58 @@
+package loader
+
+import (
+	"fmt"
+	"io/fs"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * LoadFS builds the trees of every .b3 project under root in fsys (see
+ * LoadRawProjectsFS) and registers them in registry under their config
+ * ids, which must be unique across the projects. Nothing is registered
+ * unless every tree builds. It returns the registered ids.
+ *
+ *     //go:embed ai
+ *     var aiFiles embed.FS
+ *
+ *     registry := core.NewTreeRegistry()
+ *     registry.UseForSubTrees()
+ *     ids, err := loader.LoadFS(aiFiles, "ai", registry, customNodes)
+**/
+func (this Loader) LoadFS(fsys fs.FS, root string, registry *TreeRegistry, extMap *b3.RegisterStructMaps) ([]string, error) {
+	paths, projects, err := LoadRawProjectsFS(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	var trees []*BehaviorTree
+	from := make(map[string]string)
+	for _, path := range paths {
+		project := projects[path]
+		for i := range project.Data.Trees {
+			cfg := &project.Data.Trees[i]
+			if other, ok := from[cfg.ID]; ok {
+				return nil, fmt.Errorf("%s: tree %s already loaded from %s", path, cfg.ID, other)
+			}
+			tree, err := this.TryCreateBevTreeFromConfig(cfg, extMap)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", path, err)
+			}
+			from[cfg.ID] = path
+			ids = append(ids, cfg.ID)
+			trees = append(trees, tree)
+		}
+	}
+	for i, id := range ids {
+		registry.Register(id, trees[i])
+	}
+	return ids, nil
+}
+
+// LoadFS is Loader.LoadFS with the default options.
+func LoadFS(fsys fs.FS, root string, registry *TreeRegistry, extMap *b3.RegisterStructMaps) ([]string, error) {
+	return Loader{}.LoadFS(fsys, root, registry, extMap)
+}
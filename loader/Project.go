@@ -0,0 +1,148 @@
+package loader
+
+import (
+	"fmt"
+	"strings"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * CreateProjectTrees builds every tree of project and wires the subtree
+ * references between them. behavior3editor lets a node be another tree
+ * of the project, named after its ID: such nodes become SubTree nodes,
+ * whatever their category, unless the name is a registered node, and are
+ * bound to the tree built here (see SubTree.SetTree) rather than looked
+ * up with SetSubTreeLoadFunc, which still resolves the references to
+ * trees outside of the project. Reference cycles are refused, since the
+ * trees would run each other forever.
+ *
+ * It returns the trees by config id.
+**/
+func (this Loader) CreateProjectTrees(project *BTProjectCfg, extMap *b3.RegisterStructMaps) (map[string]*BehaviorTree, error) {
+	baseMaps := createBaseStructMaps()
+	cfgs := make(map[string]*BTTreeCfg, len(project.Trees))
+	for i := range project.Trees {
+		cfgs[project.Trees[i].ID] = &project.Trees[i]
+	}
+	isRegistered := func(name string) bool {
+		return baseMaps.CheckElem(name) || extMap != nil && extMap.CheckElem(name)
+	}
+
+	// the configs with their references marked, without changing project
+	refs := make(map[string][]string)
+	marked := make(map[string]*BTTreeCfg, len(cfgs))
+	for id, cfg := range cfgs {
+		copied := *cfg
+		copied.Nodes = make(map[string]BTNodeCfg, len(cfg.Nodes))
+		for nid, node := range cfg.Nodes {
+			if _, ok := cfgs[node.Name]; ok && (node.Category == "tree" || !isRegistered(node.Name)) {
+				node.Category = "tree"
+				refs[id] = append(refs[id], node.Name)
+			}
+			copied.Nodes[nid] = node
+		}
+		marked[id] = &copied
+	}
+	if cycle := findTreeCycle(project, refs); cycle != nil {
+		titles := make([]string, len(cycle))
+		for i, id := range cycle {
+			titles[i] = cfgs[id].Title
+		}
+		return nil, fmt.Errorf("project %s: subtree cycle %s", project.ID, strings.Join(titles, " > "))
+	}
+
+	trees := make(map[string]*BehaviorTree, len(marked))
+	for id, cfg := range marked {
+		tree, err := this.TryCreateBevTreeFromConfig(cfg, extMap)
+		if err != nil {
+			return nil, err
+		}
+		trees[id] = tree
+	}
+	for _, tree := range trees {
+		tree.BindSubTrees(func(name string) *BehaviorTree {
+			return trees[name]
+		})
+	}
+	return trees, nil
+}
+
+// CreateProjectTrees is Loader.CreateProjectTrees with the default
+// options.
+func CreateProjectTrees(project *BTProjectCfg, extMap *b3.RegisterStructMaps) (map[string]*BehaviorTree, error) {
+	return Loader{}.CreateProjectTrees(project, extMap)
+}
+
+// findTreeCycle returns a cycle of refs, from a tree back to itself, or
+// nil. The trees are visited in project order, for stable errors.
+func findTreeCycle(project *BTProjectCfg, refs map[string][]string) []string {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+	var stack []string
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		switch state[id] {
+		case visiting:
+			for i, s := range stack {
+				if s == id {
+					return append(append([]string(nil), stack[i:]...), id)
+				}
+			}
+		case done:
+			return nil
+		}
+		state[id] = visiting
+		stack = append(stack, id)
+		for _, ref := range refs[id] {
+			if cycle := visit(ref); cycle != nil {
+				return cycle
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[id] = done
+		return nil
+	}
+	for i := range project.Trees {
+		if cycle := visit(project.Trees[i].ID); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+/**
+ * LoadProjects loads the .b3 projects at paths, merges them (see
+ * MergeProjects) and builds their trees with CreateProjectTrees, so a tree
+ * can use a tree of another project as subtree. It returns the merged
+ * project and the trees by config id.
+**/
+func (this Loader) LoadProjects(paths []string, extMap *b3.RegisterStructMaps) (*BTProjectCfg, map[string]*BehaviorTree, error) {
+	projects := make([]*BTProjectCfg, len(paths))
+	for i, path := range paths {
+		raw, err := ReadRawProjectCfg(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		projects[i] = &raw.Data
+	}
+	merged, err := MergeProjects(paths, projects...)
+	if err != nil {
+		return nil, nil, err
+	}
+	trees, err := this.CreateProjectTrees(merged, extMap)
+	if err != nil {
+		return nil, nil, err
+	}
+	return merged, trees, nil
+}
+
+// LoadProjects is Loader.LoadProjects with the default options.
+func LoadProjects(paths []string, extMap *b3.RegisterStructMaps) (*BTProjectCfg, map[string]*BehaviorTree, error) {
+	return Loader{}.LoadProjects(paths, extMap)
+}
@@ -60,7 +60,7 @@ func TestLoadTree(t *testing.T) {
 		tree.Print()
 
 		//输入板
-		board := NewBlackboard()
+		board := NewBlackboard(nil)
 		//循环每一帧
 		for i := 0; i < 5; i++ {
 			tree.Tick(i, board)
@@ -70,3 +70,78 @@ func TestLoadTree(t *testing.T) {
 	}
 
 }
+
+func TestLoadTreeFromBytes(t *testing.T) {
+	treeConfig, err := LoadTreeCfgFromBytes([]byte(`{
+		"id": "t", "title": "bytes", "root": "r",
+		"nodes": {"r": {"id": "r", "name": "Succeeder", "category": "action"}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := TryCreateBevTreeFromConfig(treeConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := tree.Tick(0, NewBlackboard(nil)); status != b3.SUCCESS {
+		t.Error("status:", status)
+	}
+
+	if _, err := LoadTreeCfgFromBytes([]byte("{")); err == nil {
+		t.Error("no error for truncated config")
+	}
+}
+
+func TestLoaderPreserveIDs(t *testing.T) {
+	treeConfig, err := LoadTreeCfgFromBytes([]byte(`{
+		"id": "t", "title": "ids", "root": "r",
+		"nodes": {"r": {"id": "r", "name": "Succeeder", "category": "action"}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	preserved, err := Loader{PreserveIDs: true}.TryCreateBevTreeFromConfig(treeConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preserved.GetID() != "t" || preserved.GetRoot().GetID() != "r" {
+		t.Errorf("ids %s/%s, want t/r", preserved.GetID(), preserved.GetRoot().GetID())
+	}
+	// the option is per load: the default loader still assigns a new id
+	tree, err := TryCreateBevTreeFromConfig(treeConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.GetID() == "t" || tree.GetID() == preserved.GetID() {
+		t.Error("tree id kept:", tree.GetID())
+	}
+}
+
+func TestLoaderStableNodeIDs(t *testing.T) {
+	treeConfig, err := LoadTreeCfgFromBytes([]byte(`{
+		"id": "t", "title": "ids", "root": "r",
+		"nodes": {"r": {"id": "r", "name": "Succeeder", "category": "action"}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := StableNodeIDs(treeConfig).Root
+	tree, err := Loader{StableNodeIDs: true}.TryCreateBevTreeFromConfig(treeConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tree.GetRoot().GetID(); got != want || got == "r" {
+		t.Errorf("root id %s, want %s", got, want)
+	}
+	// PreserveIDs keeps the ids of the editor
+	tree, err = Loader{StableNodeIDs: true, PreserveIDs: true}.TryCreateBevTreeFromConfig(treeConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tree.GetRoot().GetID(); got != "r" {
+		t.Error("preserved root id:", got)
+	}
+	if treeConfig.Root != "r" {
+		t.Error("config changed:", treeConfig.Root)
+	}
+}
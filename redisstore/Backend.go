@@ -0,0 +1,65 @@
+package redisstore
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// backend is what Storage needs from Redis: a hash and a pub/sub channel.
+type backend interface {
+	hset(ctx context.Context, hash, field, data string) error
+	hdel(ctx context.Context, hash, field string) error
+	hgetall(ctx context.Context, hash string) (map[string]string, error)
+	// hget returns ok false for a missing field
+	hget(ctx context.Context, hash, field string) (data string, ok bool, err error)
+	publish(ctx context.Context, channel, msg string) error
+	// subscribe returns the messages of channel once the subscription is
+	// confirmed, and the function ending it
+	subscribe(ctx context.Context, channel string) (<-chan string, func() error, error)
+}
+
+type clientBackend struct {
+	client redis.UniversalClient
+}
+
+func (this clientBackend) hset(ctx context.Context, hash, field, data string) error {
+	return this.client.HSet(ctx, hash, field, data).Err()
+}
+
+func (this clientBackend) hdel(ctx context.Context, hash, field string) error {
+	return this.client.HDel(ctx, hash, field).Err()
+}
+
+func (this clientBackend) hgetall(ctx context.Context, hash string) (map[string]string, error) {
+	return this.client.HGetAll(ctx, hash).Result()
+}
+
+func (this clientBackend) hget(ctx context.Context, hash, field string) (string, bool, error) {
+	data, err := this.client.HGet(ctx, hash, field).Result()
+	if err == redis.Nil {
+		return "", false, nil
+	}
+	return data, err == nil, err
+}
+
+func (this clientBackend) publish(ctx context.Context, channel, msg string) error {
+	return this.client.Publish(ctx, channel, msg).Err()
+}
+
+func (this clientBackend) subscribe(ctx context.Context, channel string) (<-chan string, func() error, error) {
+	pubsub := this.client.Subscribe(ctx, channel)
+	// the first reply confirms the subscription, or reports why it failed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, nil, err
+	}
+	msgs := make(chan string)
+	go func() {
+		defer close(msgs)
+		for msg := range pubsub.Channel() {
+			msgs <- msg.Payload
+		}
+	}()
+	return msgs, pubsub.Close, nil
+}
@@ -0,0 +1,123 @@
+package redisstore
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// 带类型标记的值，保证int等类型读回来不会变成float64
+type typedValue struct {
+	T string          `json:"t"`
+	V json.RawMessage `json:"v"`
+}
+
+/**
+ * encodeValue encodes a blackboard value together with its Go type so that
+ * Blackboard.GetInt & co. keep working after the value went through Redis.
+ * Types other than the basic ones are stored as plain JSON and read back
+ * as whatever encoding/json produces.
+**/
+func encodeValue(value interface{}) (string, error) {
+	var t string
+	switch value.(type) {
+	case nil:
+		t = "nil"
+	case bool:
+		t = "bool"
+	case int:
+		t = "int"
+	case int32:
+		t = "int32"
+	case int64:
+		t = "int64"
+	case uint32:
+		t = "uint32"
+	case uint64:
+		t = "uint64"
+	case float32:
+		t = "float32"
+	case float64:
+		t = "float64"
+	case string:
+		t = "string"
+	default:
+		t = "json"
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(typedValue{T: t, V: raw})
+	return string(data), err
+}
+
+func decodeValue(data string) (interface{}, error) {
+	var tv typedValue
+	if err := json.Unmarshal([]byte(data), &tv); err != nil {
+		return nil, err
+	}
+	var err error
+	switch tv.T {
+	case "nil":
+		return nil, nil
+	case "bool":
+		var v bool
+		err = json.Unmarshal(tv.V, &v)
+		return v, err
+	case "int":
+		var v int
+		err = json.Unmarshal(tv.V, &v)
+		return v, err
+	case "int32":
+		var v int32
+		err = json.Unmarshal(tv.V, &v)
+		return v, err
+	case "int64":
+		var v int64
+		err = json.Unmarshal(tv.V, &v)
+		return v, err
+	case "uint32":
+		var v uint32
+		err = json.Unmarshal(tv.V, &v)
+		return v, err
+	case "uint64":
+		var v uint64
+		err = json.Unmarshal(tv.V, &v)
+		return v, err
+	case "float32":
+		var v float32
+		err = json.Unmarshal(tv.V, &v)
+		return v, err
+	case "float64":
+		var v float64
+		err = json.Unmarshal(tv.V, &v)
+		return v, err
+	case "string":
+		var v string
+		err = json.Unmarshal(tv.V, &v)
+		return v, err
+	case "json":
+		var v interface{}
+		err = json.Unmarshal(tv.V, &v)
+		return v, err
+	}
+	return nil, fmt.Errorf("redisstore: unknown value type %q", tv.T)
+}
+
+// field用json数组编码三元组，避免分隔符冲突
+func encodeField(key, treeScope, nodeScope string) string {
+	data, _ := json.Marshal([]string{key, treeScope, nodeScope})
+	return string(data)
+}
+
+func decodeField(field string) (key, treeScope, nodeScope string, err error) {
+	var parts []string
+	if err = json.Unmarshal([]byte(field), &parts); err != nil {
+		return
+	}
+	if len(parts) != 3 {
+		err = fmt.Errorf("redisstore: bad field %q", field)
+		return
+	}
+	return parts[0], parts[1], parts[2], nil
+}
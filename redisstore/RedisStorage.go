@@ -0,0 +1,255 @@
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+	b3 "github.com/youngtrips/behavior3go"
+	"github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * Storage is a core.Storage backed by a Redis hash, shared by blackboards
+ * living in different processes (e.g. the server shards of one world).
+ *
+ * Every write is stored in the hash `name` and an invalidation is published
+ * on the channel `name:inv`. Other instances listening on the channel fetch
+ * the changed entry and queue it; the queued entries are applied to the
+ * local blackboard by Sync, which must be called from the goroutine that
+ * ticks the tree (blackboards are not safe for concurrent use):
+ *
+ *     store := redisstore.NewStorage(client, "b3:world:1")
+ *     board := core.NewBlackboard(store)
+ *     store.Listen()
+ *     for range ticker.C {
+ *         store.Sync(board)
+ *         tree.Tick(npc, board)
+ *     }
+ *
+ * By default only the global memory is shared. Tree and node memory hold
+ * per agent execution state (open nodes, running children, ...) and stay
+ * local unless ShareAllScopes is called.
+ *
+ * @class Storage
+**/
+type Storage struct {
+	backend  backend
+	ctx      context.Context
+	hash     string
+	channel  string
+	origin   string
+	shareAll bool
+	onError  func(err error)
+
+	mutex   sync.Mutex
+	pending map[string]*pendingEntry
+	// ends the subscription of Listen, nil when not listening
+	unsubscribe func() error
+
+	//Sync期间写回的值不再发往redis
+	applying bool
+}
+
+var _ core.Storage = (*Storage)(nil)
+
+type pendingEntry struct {
+	value   interface{}
+	removed bool
+}
+
+type invalidation struct {
+	Origin string `json:"o"`
+	Field  string `json:"f"`
+}
+
+func NewStorage(client redis.UniversalClient, name string) *Storage {
+	return newStorage(clientBackend{client}, name)
+}
+
+func newStorage(backend backend, name string) *Storage {
+	return &Storage{
+		backend: backend,
+		ctx:     context.Background(),
+		hash:    name,
+		channel: name + ":inv",
+		origin:  b3.CreateUUID(),
+		pending: make(map[string]*pendingEntry),
+		onError: func(err error) {
+			core.DefaultLogger.Error("redisstore: ", err)
+		},
+	}
+}
+
+// SetContext sets the context used for redis commands.
+func (this *Storage) SetContext(ctx context.Context) {
+	this.ctx = ctx
+}
+
+// OnError replaces the default error handler, which logs the error to
+// core.DefaultLogger.
+func (this *Storage) OnError(f func(err error)) {
+	this.onError = f
+}
+
+// ShareAllScopes also stores tree and node memory in redis.
+func (this *Storage) ShareAllScopes() {
+	this.shareAll = true
+}
+
+func (this *Storage) shared(treeScope string) bool {
+	return this.shareAll || treeScope == ""
+}
+
+func (this *Storage) Set(key string, value interface{}, treeScope string, nodeScope string) {
+	if this.applying || !this.shared(treeScope) {
+		return
+	}
+	data, err := encodeValue(value)
+	if err != nil {
+		this.onError(fmt.Errorf("encode %s: %v", key, err))
+		return
+	}
+	field := encodeField(key, treeScope, nodeScope)
+	if err := this.backend.hset(this.ctx, this.hash, field, data); err != nil {
+		this.onError(err)
+		return
+	}
+	this.publish(field)
+}
+
+func (this *Storage) Remove(key string, treeScope string, nodeScope string) {
+	if this.applying || !this.shared(treeScope) {
+		return
+	}
+	field := encodeField(key, treeScope, nodeScope)
+	if err := this.backend.hdel(this.ctx, this.hash, field); err != nil {
+		this.onError(err)
+		return
+	}
+	this.publish(field)
+}
+
+func (this *Storage) Foreach(f func(key string, value interface{}, treeScope string, nodeScope string)) {
+	all, err := this.backend.hgetall(this.ctx, this.hash)
+	if err != nil {
+		this.onError(err)
+		return
+	}
+	for field, data := range all {
+		key, treeScope, nodeScope, err := decodeField(field)
+		if err != nil {
+			this.onError(err)
+			continue
+		}
+		if !this.shared(treeScope) {
+			continue
+		}
+		value, err := decodeValue(data)
+		if err != nil {
+			this.onError(fmt.Errorf("decode %s: %v", key, err))
+			continue
+		}
+		f(key, value, treeScope, nodeScope)
+	}
+}
+
+func (this *Storage) publish(field string) {
+	msg, _ := json.Marshal(invalidation{Origin: this.origin, Field: field})
+	if err := this.backend.publish(this.ctx, this.channel, string(msg)); err != nil {
+		this.onError(err)
+	}
+}
+
+/**
+ * Listen subscribes to the invalidation channel and returns once Redis
+ * confirmed the subscription, or the error it failed with. Changes made by
+ * other instances are then fetched in the background and queued until the
+ * next Sync.
+**/
+func (this *Storage) Listen() error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if this.unsubscribe != nil {
+		return nil
+	}
+	msgs, unsubscribe, err := this.backend.subscribe(this.ctx, this.channel)
+	if err != nil {
+		return err
+	}
+	this.unsubscribe = unsubscribe
+	go this.loop(msgs)
+	return nil
+}
+
+func (this *Storage) loop(msgs <-chan string) {
+	for msg := range msgs {
+		var inv invalidation
+		if err := json.Unmarshal([]byte(msg), &inv); err != nil {
+			this.onError(err)
+			continue
+		}
+		if inv.Origin == this.origin {
+			continue
+		}
+		entry := &pendingEntry{}
+		data, ok, err := this.backend.hget(this.ctx, this.hash, inv.Field)
+		if err != nil {
+			this.onError(err)
+			continue
+		}
+		if !ok {
+			entry.removed = true
+		} else if entry.value, err = decodeValue(data); err != nil {
+			this.onError(err)
+			continue
+		}
+		this.mutex.Lock()
+		this.pending[inv.Field] = entry
+		this.mutex.Unlock()
+	}
+}
+
+// Close stops listening for invalidations.
+func (this *Storage) Close() error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if this.unsubscribe == nil {
+		return nil
+	}
+	err := this.unsubscribe()
+	this.unsubscribe = nil
+	return err
+}
+
+/**
+ * Sync applies the changes received from other instances to blackboard and
+ * returns how many entries were refreshed. Call it before ticking.
+**/
+func (this *Storage) Sync(blackboard *core.Blackboard) int {
+	this.mutex.Lock()
+	pending := this.pending
+	this.pending = make(map[string]*pendingEntry)
+	this.mutex.Unlock()
+
+	this.applying = true
+	defer func() { this.applying = false }()
+
+	n := 0
+	for field, entry := range pending {
+		key, treeScope, nodeScope, err := decodeField(field)
+		if err != nil || !this.shared(treeScope) {
+			continue
+		}
+		switch {
+		case entry.removed:
+			blackboard.RemoveKey(key, treeScope, nodeScope)
+		default:
+			blackboard.Set(key, entry.value, treeScope, nodeScope)
+		}
+		n++
+	}
+	return n
+}
@@ -0,0 +1,228 @@
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/youngtrips/behavior3go/core"
+)
+
+// fakeRedis is an in-memory backend shared by the storages of a test, as
+// one Redis server.
+type fakeRedis struct {
+	mutex     sync.Mutex
+	hashes    map[string]map[string]string
+	subs      map[string][]chan string
+	hsets     int
+	publishes int
+	// error of the next subscribe
+	subscribeErr error
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{hashes: make(map[string]map[string]string), subs: make(map[string][]chan string)}
+}
+
+func (this *fakeRedis) hset(ctx context.Context, hash, field, data string) error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if this.hashes[hash] == nil {
+		this.hashes[hash] = make(map[string]string)
+	}
+	this.hashes[hash][field] = data
+	this.hsets++
+	return nil
+}
+
+func (this *fakeRedis) hdel(ctx context.Context, hash, field string) error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	delete(this.hashes[hash], field)
+	return nil
+}
+
+func (this *fakeRedis) hgetall(ctx context.Context, hash string) (map[string]string, error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	all := make(map[string]string)
+	for field, data := range this.hashes[hash] {
+		all[field] = data
+	}
+	return all, nil
+}
+
+func (this *fakeRedis) hget(ctx context.Context, hash, field string) (string, bool, error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	data, ok := this.hashes[hash][field]
+	return data, ok, nil
+}
+
+func (this *fakeRedis) publish(ctx context.Context, channel, msg string) error {
+	this.mutex.Lock()
+	subs := this.subs[channel]
+	this.publishes++
+	this.mutex.Unlock()
+	for _, sub := range subs {
+		sub <- msg
+	}
+	return nil
+}
+
+func (this *fakeRedis) subscribe(ctx context.Context, channel string) (<-chan string, func() error, error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if err := this.subscribeErr; err != nil {
+		this.subscribeErr = nil
+		return nil, nil, err
+	}
+	sub := make(chan string, 64)
+	this.subs[channel] = append(this.subs[channel], sub)
+	var once sync.Once
+	return sub, func() error {
+		once.Do(func() {
+			this.mutex.Lock()
+			defer this.mutex.Unlock()
+			subs := this.subs[channel]
+			for i, s := range subs {
+				if s == sub {
+					this.subs[channel] = append(subs[:i:i], subs[i+1:]...)
+				}
+			}
+			close(sub)
+		})
+		return nil
+	}, nil
+}
+
+func (this *fakeRedis) counts() (hsets, publishes int) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return this.hsets, this.publishes
+}
+
+func newTestStorage(t *testing.T, redis *fakeRedis) *Storage {
+	t.Helper()
+	s := newStorage(redis, "b3:test")
+	s.OnError(func(err error) {
+		t.Error("storage error:", err)
+	})
+	return s
+}
+
+// syncWait syncs board until an entry arrives, failing after a second.
+func syncWait(t *testing.T, s *Storage, board *core.Blackboard) int {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if n := s.Sync(board); n > 0 {
+			return n
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("no invalidation received")
+	return 0
+}
+
+func TestSetStoresSharedKeys(t *testing.T) {
+	redis := newFakeRedis()
+	board := core.NewBlackboard(newTestStorage(t, redis))
+	board.SetMem("hp", 10)
+	board.Set("running", 1, "tree", "node")
+	if hsets, publishes := redis.counts(); hsets != 1 || publishes != 1 {
+		t.Errorf("%d writes and %d invalidations, want the global key only", hsets, publishes)
+	}
+}
+
+func TestForeachLoadsWithoutWritingBack(t *testing.T) {
+	redis := newFakeRedis()
+	first := newTestStorage(t, redis)
+	first.ShareAllScopes()
+	board := core.NewBlackboard(first)
+	board.SetMem("hp", 10)
+	board.Set("running", 1, "tree", "node")
+	hsets, publishes := redis.counts()
+
+	second := newTestStorage(t, redis)
+	second.ShareAllScopes()
+	loaded := core.NewBlackboard(second)
+	if hp := loaded.GetInt("hp", "", ""); hp != 10 {
+		t.Error("hp:", loaded.GetMem("hp"))
+	}
+	if running := loaded.GetInt("running", "tree", "node"); running != 1 {
+		t.Error("running:", loaded.Get("running", "tree", "node"))
+	}
+	if h, p := redis.counts(); h != hsets || p != publishes {
+		t.Errorf("loading wrote %d entries and sent %d invalidations", h-hsets, p-publishes)
+	}
+}
+
+func TestSyncAppliesPeerChanges(t *testing.T) {
+	redis := newFakeRedis()
+	writer := newTestStorage(t, redis)
+	writer.ShareAllScopes()
+	reader := newTestStorage(t, redis)
+	reader.ShareAllScopes()
+	if err := reader.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	writerBoard := core.NewBlackboard(writer)
+	readerBoard := core.NewBlackboard(reader)
+
+	writerBoard.SetMem("hp", 10)
+	syncWait(t, reader, readerBoard)
+	if hp := readerBoard.GetMem("hp"); hp != 10 {
+		t.Errorf("hp %#v after sync", hp)
+	}
+
+	writerBoard.Set("running", 1, "tree", "node")
+	syncWait(t, reader, readerBoard)
+	writerBoard.RemoveKey("running", "tree", "node")
+	syncWait(t, reader, readerBoard)
+	for _, e := range readerBoard.Export().Entries {
+		if e.Key == "running" {
+			t.Errorf("removed key synced as %#v", e.Value)
+		}
+	}
+
+	writerBoard.Remove("hp")
+	syncWait(t, reader, readerBoard)
+	if hp := readerBoard.GetMem("hp"); hp != nil {
+		t.Errorf("hp %#v after removal", hp)
+	}
+}
+
+func TestListenIgnoresOwnInvalidations(t *testing.T) {
+	redis := newFakeRedis()
+	own := newTestStorage(t, redis)
+	if err := own.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	defer own.Close()
+	ownBoard := core.NewBlackboard(own)
+	peerBoard := core.NewBlackboard(newTestStorage(t, redis))
+
+	ownBoard.SetMem("mine", 1)
+	// delivered after the own invalidation, on the same channel
+	peerBoard.SetMem("theirs", 2)
+	if n := syncWait(t, own, ownBoard); n != 1 {
+		t.Errorf("%d entries synced, want the peer one only", n)
+	}
+}
+
+func TestListenReportsSubscribeError(t *testing.T) {
+	redis := newFakeRedis()
+	redis.subscribeErr = errors.New("connection refused")
+	s := newTestStorage(t, redis)
+	if err := s.Listen(); err == nil {
+		t.Fatal("Listen hid the subscribe error")
+	}
+	if err := s.Listen(); err != nil {
+		t.Fatal("Listen after the error:", err)
+	}
+	s.Close()
+}
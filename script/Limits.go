@@ -0,0 +1,69 @@
+/*
+Package script holds what the scripting nodes (script/wasm and the
+interpreted languages) share: the resource limits applied to every call
+into a script and the error reported when a script exceeds them.
+*/
+package script
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+/**
+ * Limits bounds a single invocation of a script (one open, tick or close
+ * of a node). A zero field means no limit.
+ *
+ * Instructions is counted in the unit the engine can meter: bytecode
+ * instructions for interpreters, function calls for WASM (loops without
+ * calls are still bounded by Timeout).
+**/
+type Limits struct {
+	Timeout      time.Duration
+	Memory       uint64 // bytes
+	Instructions uint64
+}
+
+// DefaultLimits keeps a runaway script from stalling the tick loop. The
+// Lua runtime only takes its Timeout, memory not being metered there.
+var DefaultLimits = Limits{
+	Timeout: 50 * time.Millisecond,
+	Memory:  16 << 20,
+}
+
+// Context derives the context of one invocation from parent.
+func (this Limits) Context(parent context.Context) (context.Context, context.CancelFunc) {
+	if this.Timeout > 0 {
+		return context.WithTimeout(parent, this.Timeout)
+	}
+	return context.WithCancel(parent)
+}
+
+// Resources a script can run out of.
+const (
+	LimitTime         = "time"
+	LimitMemory       = "memory"
+	LimitInstructions = "instructions"
+)
+
+/**
+ * LimitError is returned when an invocation is stopped for exceeding one
+ * of its Limits. Scripting nodes turn it into an ERROR status.
+**/
+type LimitError struct {
+	Script   string // module or script name
+	Function string // entry point being run
+	Resource string // one of the Limit* constants
+	Limit    uint64 // nanoseconds for LimitTime
+	Used     uint64
+}
+
+func (this *LimitError) Error() string {
+	if this.Resource == LimitTime {
+		return fmt.Sprintf("script %s: %s exceeded time limit %v (ran %v)",
+			this.Script, this.Function, time.Duration(this.Limit), time.Duration(this.Used))
+	}
+	return fmt.Sprintf("script %s: %s exceeded %s limit %d (used %d)",
+		this.Script, this.Function, this.Resource, this.Limit, this.Used)
+}
@@ -0,0 +1,66 @@
+package lua
+
+import (
+	"fmt"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+	glua "github.com/yuin/gopher-lua"
+)
+
+/**
+ * LuaAction runs the Lua script of its "script" property on every tick,
+ * see the package documentation for what the script can use. Register it
+ * with the custom nodes of the loader:
+ *
+ *     maps.Register("Lua", &lua.LuaAction{})
+ *
+ * The script is compiled when the node is initialized, so a syntax error
+ * fails the loading of the tree. A script that raises an error, exceeds
+ * the limits of the runtime (see script.Limits) or returns something that
+ * is not a status makes the node return ERROR; the error is passed to the
+ * error handler of the runtime and kept on the node memory under the
+ * "lua.error" key.
+ *
+ * @module b3
+ * @class LuaAction
+ * @extends Action
+**/
+type LuaAction struct {
+	Action
+	runtime *Runtime
+	proto   *glua.FunctionProto
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **script** (*String*) The Lua source of the tick.
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *LuaAction) Initialize(setting *BTNodeCfg) {
+	this.Action.Initialize(setting)
+	this.runtime = Default()
+	proto, err := Compile(setting.Title, setting.GetPropertyAsString("script"))
+	if err != nil {
+		panic(fmt.Sprintf("lua: node %s: %v", setting.Id, err))
+	}
+	this.proto = proto
+}
+
+func (this *LuaAction) OnTick(tick *Tick) b3.Status {
+	status, err := this.runtime.Call(tick, this, this.proto)
+	if err != nil {
+		this.runtime.onError(this, err)
+		tick.SetError(this, err)
+		tick.Blackboard.Set("lua.error", err.Error(), tick.GetTree().GetID(), this.GetID())
+		return b3.ERROR
+	}
+	return status
+}
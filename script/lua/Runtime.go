@@ -0,0 +1,333 @@
+/*
+Package lua runs action nodes written in Lua with gopher-lua, so designers
+can change the logic of a node without recompiling the game. The script of
+a LuaAction is the body of its tick: it runs on every tick and returns the
+status of the node.
+
+	local hp = bb.get("hp")
+	if hp < 30 then
+		bb.set("fleeing", true)
+		return RUNNING
+	end
+	return SUCCESS
+
+Globals available to the scripts, besides the base, table, string and math
+libraries (no io, os nor module loading):
+
+	SUCCESS, FAILURE, RUNNING, ERROR
+		the statuses; returning nothing or true means SUCCESS, false
+		FAILURE.
+	bb.get(key [, scope]), bb.set(key, value [, scope]), bb.remove(key [, scope])
+		read and write the blackboard; scope is "global" (default), "tree"
+		or "node" (the memory of the LuaAction node).
+	log(...)
+		writes through the logger of the runtime, core.DefaultLogger by
+		default.
+
+Numbers written to the blackboard are float64, tables become
+[]interface{} when they are sequences and map[string]interface{}
+otherwise. Every call gets fresh globals, so nothing survives between
+ticks in the script: state is kept on the blackboard.
+*/
+package lua
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	b3 "github.com/youngtrips/behavior3go"
+	"github.com/youngtrips/behavior3go/core"
+	"github.com/youngtrips/behavior3go/script"
+	glua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+)
+
+/**
+ * Runtime compiles and runs the scripts of LuaAction nodes. Interpreters
+ * are pooled, so concurrent trees do not share one.
+ *
+ * Every call is bounded by the Timeout of the script.Limits of the
+ * runtime, a stopped call returning a *script.LimitError. gopher-lua can
+ * neither meter instructions nor memory, so a runtime is never created
+ * with those limits set rather than running scripts without them.
+ *
+ * @class Runtime
+**/
+type Runtime struct {
+	ctx     context.Context
+	limits  script.Limits
+	pool    sync.Pool
+	logger  func(node *LuaAction, msg string)
+	onError func(node *LuaAction, err error)
+}
+
+// NewRuntime creates a runtime with the Timeout of script.DefaultLimits;
+// calls are cancelled when ctx is done.
+func NewRuntime(ctx context.Context) *Runtime {
+	this, _ := NewRuntimeWithLimits(ctx, script.Limits{Timeout: script.DefaultLimits.Timeout})
+	return this
+}
+
+// NewRuntimeWithLimits creates a runtime bounding every call by limits,
+// refusing the Memory and Instructions limits it cannot enforce.
+func NewRuntimeWithLimits(ctx context.Context, limits script.Limits) (*Runtime, error) {
+	if limits.Memory > 0 {
+		return nil, fmt.Errorf("lua: %s limit not supported", script.LimitMemory)
+	}
+	if limits.Instructions > 0 {
+		return nil, fmt.Errorf("lua: %s limit not supported", script.LimitInstructions)
+	}
+	this := &Runtime{
+		ctx:    ctx,
+		limits: limits,
+		logger: func(node *LuaAction, msg string) {
+			core.DefaultLogger.Info("lua: ", node.GetTitle(), ": ", msg)
+		},
+		onError: func(node *LuaAction, err error) {
+			core.DefaultLogger.Error("lua: ", node.GetTitle(), ": ", err)
+		},
+	}
+	this.pool.New = func() interface{} {
+		return this.newState()
+	}
+	return this, nil
+}
+
+// SetLogger replaces the handler of the log function, which writes to
+// core.DefaultLogger by default.
+func (this *Runtime) SetLogger(f func(node *LuaAction, msg string)) {
+	this.logger = f
+}
+
+// SetErrorHandler replaces the handler receiving the errors of failed
+// calls, which logs them to core.DefaultLogger by default.
+func (this *Runtime) SetErrorHandler(f func(node *LuaAction, err error)) {
+	this.onError = f
+}
+
+func (this *Runtime) Limits() script.Limits {
+	return this.limits
+}
+
+// Compile parses a script; name is used in the error messages.
+func Compile(name, source string) (*glua.FunctionProto, error) {
+	chunk, err := parse.Parse(strings.NewReader(source), name)
+	if err != nil {
+		return nil, err
+	}
+	return glua.Compile(chunk, name)
+}
+
+// state is a pooled interpreter with the bindings installed.
+type state struct {
+	L *glua.LState
+	// the call in progress, read by the bindings
+	tick *core.Tick
+	node *LuaAction
+}
+
+func (this *Runtime) newState() *state {
+	s := &state{}
+	L := glua.NewState(glua.Options{SkipOpenLibs: true})
+	for _, lib := range []struct {
+		name string
+		open glua.LGFunction
+	}{
+		{glua.BaseLibName, glua.OpenBase},
+		{glua.TabLibName, glua.OpenTable},
+		{glua.StringLibName, glua.OpenString},
+		{glua.MathLibName, glua.OpenMath},
+	} {
+		if err := L.CallByParam(glua.P{Fn: L.NewFunction(lib.open), Protect: true}, glua.LString(lib.name)); err != nil {
+			panic(err)
+		}
+	}
+	// the base library can load code from files
+	for _, name := range []string{"dofile", "loadfile"} {
+		L.SetGlobal(name, glua.LNil)
+	}
+
+	for _, status := range []b3.Status{b3.SUCCESS, b3.FAILURE, b3.RUNNING, b3.ERROR} {
+		L.SetGlobal(strings.ToUpper(status.String()), glua.LNumber(status))
+	}
+	bb := L.NewTable()
+	L.SetField(bb, "get", L.NewFunction(func(L *glua.LState) int {
+		treeScope, nodeScope := s.scope(L, 2)
+		L.Push(toLua(L, s.tick.Blackboard.Get(L.CheckString(1), treeScope, nodeScope)))
+		return 1
+	}))
+	L.SetField(bb, "set", L.NewFunction(func(L *glua.LState) int {
+		treeScope, nodeScope := s.scope(L, 3)
+		s.tick.Blackboard.Set(L.CheckString(1), fromLua(L.CheckAny(2)), treeScope, nodeScope)
+		return 0
+	}))
+	L.SetField(bb, "remove", L.NewFunction(func(L *glua.LState) int {
+		treeScope, nodeScope := s.scope(L, 2)
+		s.tick.Blackboard.RemoveKey(L.CheckString(1), treeScope, nodeScope)
+		return 0
+	}))
+	L.SetGlobal("bb", bb)
+	L.SetGlobal("log", L.NewFunction(func(L *glua.LState) int {
+		var parts []string
+		for i := 1; i <= L.GetTop(); i++ {
+			parts = append(parts, L.Get(i).String())
+		}
+		this.logger(s.node, strings.Join(parts, " "))
+		return 0
+	}))
+	s.L = L
+	return s
+}
+
+// scope reads the optional scope argument at n.
+func (this *state) scope(L *glua.LState, n int) (treeScope, nodeScope string) {
+	switch scope := L.OptString(n, "global"); scope {
+	case "global":
+		return "", ""
+	case "tree":
+		return this.tick.GetTree().GetID(), ""
+	case "node":
+		return this.tick.GetTree().GetID(), this.node.GetID()
+	default:
+		L.ArgError(n, "unknown scope "+scope)
+		return "", ""
+	}
+}
+
+// Call runs proto for node and returns its status.
+func (this *Runtime) Call(tick *core.Tick, node *LuaAction, proto *glua.FunctionProto) (b3.Status, error) {
+	s := this.pool.Get().(*state)
+	s.tick, s.node = tick, node
+	defer func() {
+		s.tick, s.node = nil, nil
+		s.L.RemoveContext()
+		this.pool.Put(s)
+	}()
+	ctx, cancel := this.limits.Context(this.ctx)
+	defer cancel()
+	s.L.SetContext(ctx)
+
+	// fresh globals falling back on the shared ones
+	L := s.L
+	env, mt := L.NewTable(), L.NewTable()
+	mt.RawSetString("__index", L.G.Global)
+	L.SetMetatable(env, mt)
+	fn := L.NewFunctionFromProto(proto)
+	fn.Env = env
+
+	start := time.Now()
+	top := L.GetTop()
+	err := L.CallByParam(glua.P{Fn: fn, NRet: 1, Protect: true})
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			elapsed := time.Since(start)
+			err = &script.LimitError{
+				Script:   node.GetTitle(),
+				Function: "tick",
+				Resource: script.LimitTime,
+				Limit:    uint64(this.limits.Timeout),
+				Used:     uint64(elapsed),
+			}
+		}
+		return b3.ERROR, err
+	}
+	ret := L.Get(-1)
+	L.Pop(L.GetTop() - top)
+	switch ret := ret.(type) {
+	case *glua.LNilType:
+		return b3.SUCCESS, nil
+	case glua.LBool:
+		if ret {
+			return b3.SUCCESS, nil
+		}
+		return b3.FAILURE, nil
+	case glua.LNumber:
+		if f := float64(ret); f == math.Trunc(f) && f >= float64(b3.SUCCESS) && f <= float64(b3.ERROR) {
+			return b3.Status(f), nil
+		}
+	}
+	return b3.ERROR, fmt.Errorf("script returned %s, not a status", ret.String())
+}
+
+func toLua(L *glua.LState, v interface{}) glua.LValue {
+	switch v := v.(type) {
+	case nil:
+		return glua.LNil
+	case bool:
+		return glua.LBool(v)
+	case string:
+		return glua.LString(v)
+	case []interface{}:
+		t := L.NewTable()
+		for _, e := range v {
+			t.Append(toLua(L, e))
+		}
+		return t
+	case map[string]interface{}:
+		t := L.NewTable()
+		for k, e := range v {
+			t.RawSetString(k, toLua(L, e))
+		}
+		return t
+	}
+	if f, ok := core.CoerceFloat64(v); ok {
+		return glua.LNumber(f)
+	}
+	ud := L.NewUserData()
+	ud.Value = v
+	return ud
+}
+
+func fromLua(v glua.LValue) interface{} {
+	switch v := v.(type) {
+	case glua.LBool:
+		return bool(v)
+	case glua.LNumber:
+		return float64(v)
+	case glua.LString:
+		return string(v)
+	case *glua.LUserData:
+		return v.Value
+	case *glua.LTable:
+		if n := v.MaxN(); n > 0 && n == v.Len() {
+			list := make([]interface{}, 0, n)
+			for i := 1; i <= n; i++ {
+				list = append(list, fromLua(v.RawGetInt(i)))
+			}
+			return list
+		}
+		m := make(map[string]interface{})
+		v.ForEach(func(k, e glua.LValue) {
+			m[k.String()] = fromLua(e)
+		})
+		return m
+	}
+	return nil
+}
+
+var (
+	defaultMutex   sync.Mutex
+	defaultRuntime *Runtime
+)
+
+// SetRuntime sets the runtime used by LuaAction nodes.
+func SetRuntime(rt *Runtime) {
+	defaultMutex.Lock()
+	defaultRuntime = rt
+	defaultMutex.Unlock()
+}
+
+// Default returns the runtime used by LuaAction nodes, creating one bound
+// to context.Background on first use.
+func Default() *Runtime {
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+	if defaultRuntime == nil {
+		defaultRuntime = NewRuntime(context.Background())
+	}
+	return defaultRuntime
+}
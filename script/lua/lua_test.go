@@ -0,0 +1,147 @@
+package lua_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+	"github.com/youngtrips/behavior3go/loader"
+	"github.com/youngtrips/behavior3go/script"
+	"github.com/youngtrips/behavior3go/script/lua"
+)
+
+// loadScript loads a tree made of one LuaAction running source with rt.
+func loadScript(t *testing.T, rt *lua.Runtime, source string) *BehaviorTree {
+	t.Helper()
+	lua.SetRuntime(rt)
+	t.Cleanup(func() { lua.SetRuntime(nil) })
+	src, _ := json.Marshal(source)
+	treeConfig, err := LoadTreeCfgFromBytes([]byte(`{
+		"id": "t", "title": "lua", "root": "l",
+		"nodes": {
+			"l": {"id": "l", "name": "Lua", "title": "script", "category": "action",
+				"properties": {"script": ` + string(src) + `}}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	maps := b3.NewRegisterStructMaps()
+	maps.Register("Lua", &lua.LuaAction{})
+	tree, err := loader.TryCreateBevTreeFromConfig(treeConfig, maps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+// errorsOf collects the errors passed to the error handler of rt.
+func errorsOf(rt *lua.Runtime) *[]error {
+	var errs []error
+	rt.SetErrorHandler(func(node *lua.LuaAction, err error) {
+		errs = append(errs, err)
+	})
+	return &errs
+}
+
+func TestBlackboardScopes(t *testing.T) {
+	rt := lua.NewRuntime(context.Background())
+	errs := errorsOf(rt)
+	tree := loadScript(t, rt, `
+		if bb.get("step") == nil then
+			bb.set("g", 1)
+			bb.set("t", "tree", "tree")
+			bb.set("n", {1, 2}, "node")
+			bb.set("step", 1)
+			return RUNNING
+		end
+		if bb.get("g") ~= 1 or bb.get("t", "tree") ~= "tree" or bb.get("n", "node")[2] ~= 2 then
+			return FAILURE
+		end
+		if bb.get("t") ~= nil or bb.get("n", "tree") ~= nil then
+			return FAILURE
+		end
+		bb.remove("g")
+		bb.remove("t", "tree")
+		bb.remove("n", "node")
+		return SUCCESS
+	`)
+	board := NewBlackboard(nil)
+	if status := tree.Tick(0, board); status != b3.RUNNING {
+		t.Fatal("tick 1:", status, *errs)
+	}
+	if got := board.Get("g", "", ""); got != 1.0 {
+		t.Errorf("global: %#v", got)
+	}
+	if got := board.Get("t", tree.GetID(), ""); got != "tree" {
+		t.Errorf("tree: %#v", got)
+	}
+	if got, ok := board.Get("n", tree.GetID(), "l").([]interface{}); !ok || len(got) != 2 || got[0] != 1.0 {
+		t.Errorf("node: %#v", board.Get("n", tree.GetID(), "l"))
+	}
+	if status := tree.Tick(0, board); status != b3.SUCCESS {
+		t.Fatal("tick 2:", status, *errs)
+	}
+	for _, key := range []struct{ key, tree, node string }{
+		{"g", "", ""}, {"t", tree.GetID(), ""}, {"n", tree.GetID(), "l"},
+	} {
+		if got := board.Get(key.key, key.tree, key.node); got != nil {
+			t.Errorf("%s not removed: %#v", key.key, got)
+		}
+	}
+}
+
+func TestScriptErrorReturnsError(t *testing.T) {
+	rt := lua.NewRuntime(context.Background())
+	errs := errorsOf(rt)
+	for _, source := range []string{`error("boom")`, `return "done"`, `bb.get("k", "zone")`} {
+		tree := loadScript(t, rt, source)
+		board := NewBlackboard(nil)
+		*errs = nil
+		if status := tree.Tick(0, board); status != b3.ERROR {
+			t.Errorf("%s: %v", source, status)
+		}
+		if len(*errs) != 1 {
+			t.Errorf("%s: errors %v", source, *errs)
+		}
+		if board.Get("lua.error", tree.GetID(), "l") == nil {
+			t.Errorf("%s: error not kept", source)
+		}
+	}
+}
+
+func TestTimeoutIsLimitError(t *testing.T) {
+	limits := script.Limits{Timeout: 10 * time.Millisecond}
+	rt, err := lua.NewRuntimeWithLimits(context.Background(), limits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := errorsOf(rt)
+	tree := loadScript(t, rt, `while true do end`)
+	if status := tree.Tick(0, NewBlackboard(nil)); status != b3.ERROR {
+		t.Fatal(status)
+	}
+	if len(*errs) != 1 {
+		t.Fatal("errors:", *errs)
+	}
+	var limitErr *script.LimitError
+	if !errors.As((*errs)[0], &limitErr) {
+		t.Fatalf("%T: %v", (*errs)[0], (*errs)[0])
+	}
+	if limitErr.Resource != script.LimitTime || limitErr.Script != "script" {
+		t.Errorf("%+v", limitErr)
+	}
+}
+
+func TestUnmeteredLimitsRefused(t *testing.T) {
+	for _, limits := range []script.Limits{{Memory: 1 << 20}, {Instructions: 1000}} {
+		if _, err := lua.NewRuntimeWithLimits(context.Background(), limits); err == nil {
+			t.Errorf("%+v accepted", limits)
+		}
+	}
+}
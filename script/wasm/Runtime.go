@@ -0,0 +1,350 @@
+/*
+Package wasm runs behavior tree nodes compiled to WebAssembly with wazero,
+so untrusted behaviors (mods, user generated content) can be plugged into a
+tree without native plugins. A module only sees the host API below and its
+own linear memory; it has no access to files, network or the Go heap.
+
+Host module "b3" (all pointers and lengths are i32 offsets in the guest
+memory, values are JSON encoded):
+
+	get(scope, key_ptr, key_len, buf_ptr, buf_cap) -> i32
+		copies the value of key into buf and returns its length, -1 if the
+		key is not set. Nothing is copied if the length exceeds buf_cap, so
+		the guest can grow its buffer and call again.
+	set(scope, key_ptr, key_len, val_ptr, val_len) -> i32
+		stores the value, returns 0 or -1 if it is not valid JSON.
+	remove(scope, key_ptr, key_len)
+	log(msg_ptr, msg_len)
+
+scope is 0 for the global memory of the blackboard, 1 for the memory of
+the tree and 2 for the memory of the node.
+
+A module exports its memory as "memory" and a function "tick" () -> i32
+returning a b3 status (1 SUCCESS, 2 FAILURE, 3 RUNNING, 4 ERROR). It may
+also export "open" and "close" () called when the node opens and closes.
+Every call runs in a fresh instance of the module, so nothing survives in
+the guest between calls: state is kept in the node scope of the blackboard.
+*/
+package wasm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
+	"github.com/youngtrips/behavior3go/core"
+	"github.com/youngtrips/behavior3go/script"
+)
+
+// Blackboard scopes of the host API.
+const (
+	ScopeGlobal = 0
+	ScopeTree   = 1
+	ScopeNode   = 2
+)
+
+/**
+ * Runtime compiles and runs the modules of WasmAction nodes. Compiled
+ * modules are cached by name.
+ *
+ * Every call is bounded by the script.Limits of the runtime: the memory
+ * limit caps the linear memory of an instance (memory.grow fails past
+ * it), the timeout and instruction count stop the call. A stopped call
+ * returns a *script.LimitError.
+ *
+ * @class Runtime
+**/
+type Runtime struct {
+	ctx     context.Context
+	runtime wazero.Runtime
+	limits  script.Limits
+
+	mutex   sync.Mutex
+	modules map[string]wazero.CompiledModule
+	load    func(name string) ([]byte, error)
+	logger  func(node *WasmAction, msg string)
+	onError func(node *WasmAction, err error)
+}
+
+// NewRuntime creates a runtime with script.DefaultLimits; calls are
+// cancelled when ctx is done.
+func NewRuntime(ctx context.Context) (*Runtime, error) {
+	return NewRuntimeWithLimits(ctx, script.DefaultLimits)
+}
+
+/**
+ * NewRuntimeWithLimits creates a runtime bounding every call by limits.
+ * Counting instructions needs the wazero interpreter instead of the
+ * compiler, so only set limits.Instructions when the modules are not
+ * trusted to terminate quickly.
+**/
+func NewRuntimeWithLimits(ctx context.Context, limits script.Limits) (*Runtime, error) {
+	cfg := wazero.NewRuntimeConfig()
+	if limits.Instructions > 0 {
+		cfg = wazero.NewRuntimeConfigInterpreter()
+		ctx = experimental.WithFunctionListenerFactory(ctx, experimental.FunctionListenerFactoryFunc(countCalls))
+	}
+	cfg = cfg.WithCloseOnContextDone(true)
+	if limits.Memory > 0 {
+		pages := (limits.Memory + 0xffff) >> 16
+		if pages > 65536 {
+			pages = 65536
+		}
+		cfg = cfg.WithMemoryLimitPages(uint32(pages))
+	}
+	rt := wazero.NewRuntimeWithConfig(ctx, cfg)
+	this := &Runtime{
+		ctx:     ctx,
+		runtime: rt,
+		limits:  limits,
+		modules: make(map[string]wazero.CompiledModule),
+		load:    os.ReadFile,
+		logger: func(node *WasmAction, msg string) {
+			core.DefaultLogger.Info("wasm: ", node.GetTitle(), ": ", msg)
+		},
+		onError: func(node *WasmAction, err error) {
+			core.DefaultLogger.Error("wasm: ", node.GetTitle(), ": ", err)
+		},
+	}
+	_, err := rt.NewHostModuleBuilder("b3").
+		NewFunctionBuilder().WithFunc(hostGet).Export("get").
+		NewFunctionBuilder().WithFunc(hostSet).Export("set").
+		NewFunctionBuilder().WithFunc(hostRemove).Export("remove").
+		NewFunctionBuilder().WithFunc(hostLog).Export("log").
+		Instantiate(ctx)
+	if err != nil {
+		rt.Close(ctx)
+		return nil, err
+	}
+	return this, nil
+}
+
+/**
+ * SetModuleLoader sets how the "module" property of a node is turned into
+ * a binary, os.ReadFile by default. Modules already compiled are kept.
+**/
+func (this *Runtime) SetModuleLoader(load func(name string) ([]byte, error)) {
+	this.mutex.Lock()
+	this.load = load
+	this.mutex.Unlock()
+}
+
+// SetLogger replaces the handler of the log host function, which writes
+// to core.DefaultLogger by default.
+func (this *Runtime) SetLogger(f func(node *WasmAction, msg string)) {
+	this.logger = f
+}
+
+// SetErrorHandler replaces the handler receiving the errors of failed
+// calls (traps, limit violations), which logs them to core.DefaultLogger
+// by default.
+func (this *Runtime) SetErrorHandler(f func(node *WasmAction, err error)) {
+	this.onError = f
+}
+
+func (this *Runtime) Limits() script.Limits {
+	return this.limits
+}
+
+// Compile loads and compiles the module name, or returns the cached one.
+func (this *Runtime) Compile(name string) (wazero.CompiledModule, error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if m, ok := this.modules[name]; ok {
+		return m, nil
+	}
+	binary, err := this.load(name)
+	if err != nil {
+		return nil, err
+	}
+	m, err := this.runtime.CompileModule(this.ctx, binary)
+	if err != nil {
+		return nil, fmt.Errorf("wasm: compile %s: %v", name, err)
+	}
+	this.modules[name] = m
+	return m, nil
+}
+
+// Close releases the runtime and all compiled modules.
+func (this *Runtime) Close() error {
+	return this.runtime.Close(this.ctx)
+}
+
+type callKey struct{}
+
+// call is the node invocation a host function runs for.
+type call struct {
+	tick *core.Tick
+	node *WasmAction
+
+	calls    uint64
+	maxCalls uint64
+	cancel   context.CancelFunc
+}
+
+// countCalls meters the instructions of a call in guest function calls.
+func countCalls(def api.FunctionDefinition) experimental.FunctionListener {
+	return experimental.FunctionListenerFunc(func(ctx context.Context, _ api.Module, _ api.FunctionDefinition, _ []uint64, _ experimental.StackIterator) {
+		c, ok := ctx.Value(callKey{}).(*call)
+		if !ok || c.maxCalls == 0 {
+			return
+		}
+		c.calls++
+		if c.calls > c.maxCalls {
+			c.cancel()
+		}
+	})
+}
+
+/**
+ * Call instantiates compiled and calls its export fn within the limits of
+ * the runtime. ok is false if the module does not export fn.
+**/
+func (this *Runtime) Call(tick *core.Tick, node *WasmAction, compiled wazero.CompiledModule, fn string) (results []uint64, ok bool, err error) {
+	ctx, cancel := this.limits.Context(this.ctx)
+	defer cancel()
+	c := &call{tick: tick, node: node, maxCalls: this.limits.Instructions, cancel: cancel}
+	ctx = context.WithValue(ctx, callKey{}, c)
+
+	start := time.Now()
+	mod, err := this.runtime.InstantiateModule(ctx, compiled, wazero.NewModuleConfig().WithName(""))
+	if err != nil {
+		return nil, false, this.checkLimits(ctx, c, node, fn, start, err)
+	}
+	defer mod.Close(this.ctx)
+	f := mod.ExportedFunction(fn)
+	if f == nil {
+		return nil, false, nil
+	}
+	results, err = f.Call(ctx)
+	if err != nil {
+		return nil, true, this.checkLimits(ctx, c, node, fn, start, err)
+	}
+	return results, true, nil
+}
+
+// checkLimits turns the error of a call stopped by a limit into a
+// *script.LimitError.
+func (this *Runtime) checkLimits(ctx context.Context, c *call, node *WasmAction, fn string, start time.Time, err error) error {
+	name := ""
+	if node != nil {
+		name = node.module
+	}
+	switch {
+	case c.maxCalls > 0 && c.calls > c.maxCalls:
+		return &script.LimitError{Script: name, Function: fn, Resource: script.LimitInstructions,
+			Limit: c.maxCalls, Used: c.calls}
+	case ctx.Err() == context.DeadlineExceeded:
+		return &script.LimitError{Script: name, Function: fn, Resource: script.LimitTime,
+			Limit: uint64(this.limits.Timeout), Used: uint64(time.Since(start))}
+	}
+	return err
+}
+
+var (
+	defaultMutex   sync.Mutex
+	defaultRuntime *Runtime
+)
+
+// SetRuntime sets the runtime used by WasmAction nodes.
+func SetRuntime(rt *Runtime) {
+	defaultMutex.Lock()
+	defaultRuntime = rt
+	defaultMutex.Unlock()
+}
+
+// Default returns the runtime used by WasmAction nodes, creating one
+// bound to context.Background on first use.
+func Default() (*Runtime, error) {
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+	if defaultRuntime == nil {
+		rt, err := NewRuntime(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		defaultRuntime = rt
+	}
+	return defaultRuntime, nil
+}
+
+// ------------------------host functions-------------------------
+
+func readString(m api.Module, ptr, size uint32) (string, bool) {
+	data, ok := m.Memory().Read(ptr, size)
+	if !ok {
+		return "", false
+	}
+	return string(data), true
+}
+
+func scopeOf(c *call, scope uint32) (string, string) {
+	switch scope {
+	case ScopeTree:
+		return c.tick.GetTree().GetID(), ""
+	case ScopeNode:
+		return c.tick.GetTree().GetID(), c.node.GetID()
+	}
+	return "", ""
+}
+
+func hostGet(ctx context.Context, m api.Module, scope, keyPtr, keyLen, bufPtr, bufCap uint32) int32 {
+	c := ctx.Value(callKey{}).(*call)
+	key, ok := readString(m, keyPtr, keyLen)
+	if !ok {
+		return -1
+	}
+	treeScope, nodeScope := scopeOf(c, scope)
+	value := c.tick.Blackboard.Get(key, treeScope, nodeScope)
+	if value == nil {
+		return -1
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return -1
+	}
+	if uint32(len(data)) <= bufCap && !m.Memory().Write(bufPtr, data) {
+		return -1
+	}
+	return int32(len(data))
+}
+
+func hostSet(ctx context.Context, m api.Module, scope, keyPtr, keyLen, valPtr, valLen uint32) int32 {
+	c := ctx.Value(callKey{}).(*call)
+	key, ok := readString(m, keyPtr, keyLen)
+	if !ok {
+		return -1
+	}
+	data, ok := m.Memory().Read(valPtr, valLen)
+	if !ok {
+		return -1
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return -1
+	}
+	treeScope, nodeScope := scopeOf(c, scope)
+	c.tick.Blackboard.Set(key, value, treeScope, nodeScope)
+	return 0
+}
+
+func hostRemove(ctx context.Context, m api.Module, scope, keyPtr, keyLen uint32) {
+	c := ctx.Value(callKey{}).(*call)
+	if key, ok := readString(m, keyPtr, keyLen); ok {
+		treeScope, nodeScope := scopeOf(c, scope)
+		c.tick.Blackboard.RemoveKey(key, treeScope, nodeScope)
+	}
+}
+
+func hostLog(ctx context.Context, m api.Module, ptr, size uint32) {
+	c := ctx.Value(callKey{}).(*call)
+	if msg, ok := readString(m, ptr, size); ok {
+		c.node.runtime.logger(c.node, msg)
+	}
+}
@@ -0,0 +1,97 @@
+package wasm
+
+import (
+	"fmt"
+
+	"github.com/tetratelabs/wazero"
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+)
+
+/**
+ * WasmAction runs a WebAssembly module as an action node, see the package
+ * documentation for the interface the module implements. Register it with
+ * the custom nodes of the loader:
+ *
+ *     maps.Register("Wasm", &wasm.WasmAction{})
+ *
+ * A module that traps, exceeds the limits of the runtime (see
+ * script.Limits) or returns something that is not a status makes the node
+ * return ERROR; the error is passed to the error handler of the runtime
+ * and kept on the node memory under the "wasm.error" key.
+ *
+ * @module b3
+ * @class WasmAction
+ * @extends Action
+**/
+type WasmAction struct {
+	Action
+	module   string
+	runtime  *Runtime
+	compiled wazero.CompiledModule
+}
+
+/**
+ * Initialization method.
+ *
+ * Settings parameters:
+ *
+ * - **module** (*String*) Name of the module, passed to the module loader
+ *                         of the runtime (a file path by default).
+ *
+ * @method Initialize
+ * @param {Object} settings Object with parameters.
+ * @construCtor
+**/
+func (this *WasmAction) Initialize(setting *BTNodeCfg) {
+	this.Action.Initialize(setting)
+	this.module = setting.GetPropertyAsString("module")
+	rt, err := Default()
+	if err != nil {
+		panic(err)
+	}
+	this.runtime = rt
+	this.compiled, err = rt.Compile(this.module)
+	if err != nil {
+		panic(fmt.Sprintf("wasm: node %s: %v", setting.Id, err))
+	}
+}
+
+func (this *WasmAction) OnOpen(tick *Tick) {
+	if _, _, err := this.runtime.Call(tick, this, this.compiled, "open"); err != nil {
+		this.fail(tick, err)
+	}
+}
+
+func (this *WasmAction) OnTick(tick *Tick) b3.Status {
+	results, ok, err := this.runtime.Call(tick, this, this.compiled, "tick")
+	if err == nil && !ok {
+		err = fmt.Errorf("module %s does not export tick", this.module)
+	}
+	if err == nil && len(results) != 1 {
+		err = fmt.Errorf("tick of module %s returned %d values", this.module, len(results))
+	}
+	if err != nil {
+		this.fail(tick, err)
+		return b3.ERROR
+	}
+	status := int32(results[0])
+	if status < int32(b3.SUCCESS) || status > int32(b3.ERROR) {
+		this.fail(tick, fmt.Errorf("tick of module %s returned status %d", this.module, status))
+		return b3.ERROR
+	}
+	return b3.Status(status)
+}
+
+func (this *WasmAction) OnClose(tick *Tick) {
+	if _, _, err := this.runtime.Call(tick, this, this.compiled, "close"); err != nil {
+		this.fail(tick, err)
+	}
+}
+
+func (this *WasmAction) fail(tick *Tick, err error) {
+	this.runtime.onError(this, err)
+	tick.SetError(this, err)
+	tick.Blackboard.Set("wasm.error", err.Error(), tick.GetTree().GetID(), this.GetID())
+}
@@ -0,0 +1,299 @@
+package wasm_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+	"github.com/youngtrips/behavior3go/loader"
+	"github.com/youngtrips/behavior3go/script"
+	"github.com/youngtrips/behavior3go/script/wasm"
+)
+
+// functions of the host module, in the order they are imported
+const (
+	hostGet = iota
+	hostSet
+	hostRemove
+	hostLog
+)
+
+// moduleData lays out the strings a test module passes to the host.
+type moduleData struct {
+	data []byte
+}
+
+// add appends s and returns its pointer and length.
+func (this *moduleData) add(s string) (int32, int32) {
+	ptr := int32(len(this.data))
+	this.data = append(this.data, s...)
+	return ptr, int32(len(s))
+}
+
+func uleb(v uint32) []byte {
+	var out []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if v != 0 {
+			out = append(out, b|0x80)
+			continue
+		}
+		return append(out, b)
+	}
+}
+
+func sleb(v int32) []byte {
+	var out []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if (v == 0 && b&0x40 == 0) || (v == -1 && b&0x40 != 0) {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+func name(s string) []byte {
+	return append(uleb(uint32(len(s))), s...)
+}
+
+func vec(items ...[]byte) []byte {
+	out := uleb(uint32(len(items)))
+	for _, item := range items {
+		out = append(out, item...)
+	}
+	return out
+}
+
+func section(id byte, content []byte) []byte {
+	return append(append([]byte{id}, uleb(uint32(len(content)))...), content...)
+}
+
+// i32 pushes a constant.
+func i32(v int32) []byte {
+	return append([]byte{0x41}, sleb(v)...)
+}
+
+// call calls a host function with constant arguments.
+func call(fn byte, args ...int32) []byte {
+	var out []byte
+	for _, arg := range args {
+		out = append(out, i32(arg)...)
+	}
+	return append(out, 0x10, fn)
+}
+
+const (
+	drop = 0x1a
+	add  = 0x6a
+)
+
+// module assembles a module importing the b3 host functions, holding data
+// at offset 0 of its memory and exporting a tick running body.
+func module(data []byte, body ...[]byte) []byte {
+	i32s := func(n int) []byte {
+		out := uleb(uint32(n))
+		for i := 0; i < n; i++ {
+			out = append(out, 0x7f)
+		}
+		return out
+	}
+	funcType := func(params int, results int) []byte {
+		return append(append([]byte{0x60}, i32s(params)...), i32s(results)...)
+	}
+	imp := func(fn string, typ byte) []byte {
+		return append(append(name("b3"), name(fn)...), 0x00, typ)
+	}
+	var code []byte
+	for _, part := range body {
+		code = append(code, part...)
+	}
+	code = append([]byte{0x00}, append(code, 0x0b)...)
+
+	out := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	out = append(out, section(1, vec(funcType(5, 1), funcType(3, 0), funcType(2, 0), funcType(0, 1)))...)
+	out = append(out, section(2, vec(imp("get", 0), imp("set", 0), imp("remove", 1), imp("log", 2)))...)
+	out = append(out, section(3, vec([]byte{3}))...)
+	out = append(out, section(5, vec([]byte{0x00, 0x01}))...)
+	out = append(out, section(7, vec(append(name("memory"), 0x02, 0x00), append(name("tick"), 0x00, 0x04)))...)
+	out = append(out, section(10, vec(append(uleb(uint32(len(code))), code...)))...)
+	out = append(out, section(11, vec(append(append([]byte{0x00}, append(i32(0), 0x0b)...), name(string(data))...)))...)
+	return out
+}
+
+// newRuntime returns a runtime loading the modules of binaries, used by
+// the WasmAction nodes for the duration of the test.
+func newRuntime(t *testing.T, limits script.Limits, binaries map[string][]byte) (*wasm.Runtime, *[]error) {
+	t.Helper()
+	rt, err := wasm.NewRuntimeWithLimits(context.Background(), limits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt.SetModuleLoader(func(name string) ([]byte, error) {
+		if binary, ok := binaries[name]; ok {
+			return binary, nil
+		}
+		return nil, fmt.Errorf("no module %s", name)
+	})
+	var errs []error
+	rt.SetErrorHandler(func(node *wasm.WasmAction, err error) {
+		errs = append(errs, err)
+	})
+	wasm.SetRuntime(rt)
+	t.Cleanup(func() {
+		wasm.SetRuntime(nil)
+		rt.Close()
+	})
+	return rt, &errs
+}
+
+// loadModule loads a tree made of one WasmAction running module.
+func loadModule(t *testing.T, module string) *BehaviorTree {
+	t.Helper()
+	treeConfig, err := LoadTreeCfgFromBytes([]byte(`{
+		"id": "t", "title": "wasm", "root": "w",
+		"nodes": {
+			"w": {"id": "w", "name": "Wasm", "title": "module", "category": "action",
+				"properties": {"module": "` + module + `"}}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	maps := b3.NewRegisterStructMaps()
+	maps.Register("Wasm", &wasm.WasmAction{})
+	tree, err := loader.TryCreateBevTreeFromConfig(treeConfig, maps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+func TestHostSetAndRemoveScopes(t *testing.T) {
+	var d moduleData
+	g, gLen := d.add("g")
+	k, kLen := d.add("k")
+	one, oneLen := d.add("1")
+	text, textLen := d.add(`"t"`)
+	list, listLen := d.add("[1]")
+	set := module(d.data,
+		call(hostSet, wasm.ScopeGlobal, g, gLen, one, oneLen), []byte{drop},
+		call(hostSet, wasm.ScopeTree, k, kLen, text, textLen), []byte{drop},
+		call(hostSet, wasm.ScopeNode, k, kLen, list, listLen), []byte{drop},
+		i32(int32(b3.SUCCESS)))
+	remove := module(d.data,
+		call(hostRemove, wasm.ScopeGlobal, g, gLen),
+		call(hostRemove, wasm.ScopeTree, k, kLen),
+		call(hostRemove, wasm.ScopeNode, k, kLen),
+		i32(int32(b3.SUCCESS)))
+	_, errs := newRuntime(t, script.DefaultLimits, map[string][]byte{"set": set, "remove": remove})
+
+	board := NewBlackboard(nil)
+	setTree := loadModule(t, "set")
+	if status := setTree.Tick(0, board); status != b3.SUCCESS {
+		t.Fatal("set:", status, *errs)
+	}
+	if got := board.Get("g", "", ""); got != 1.0 {
+		t.Errorf("global: %#v", got)
+	}
+	if got := board.Get("k", setTree.GetID(), ""); got != "t" {
+		t.Errorf("tree: %#v", got)
+	}
+	if got, ok := board.Get("k", setTree.GetID(), "w").([]interface{}); !ok || len(got) != 1 {
+		t.Errorf("node: %#v", board.Get("k", setTree.GetID(), "w"))
+	}
+
+	removeTree := loadModule(t, "remove")
+	board.Set("k", "t", removeTree.GetID(), "")
+	board.Set("k", 1, removeTree.GetID(), "w")
+	if status := removeTree.Tick(0, board); status != b3.SUCCESS {
+		t.Fatal("remove:", status, *errs)
+	}
+	for _, entry := range board.Export().Entries {
+		if entry.Key == "g" || entry.Key == "k" && entry.TreeScope == removeTree.GetID() {
+			t.Errorf("%s left in %q/%q: %#v", entry.Key, entry.TreeScope, entry.NodeScope, entry.Value)
+		}
+	}
+}
+
+func TestHostGet(t *testing.T) {
+	var d moduleData
+	in, inLen := d.add("in")
+	out, outLen := d.add("out")
+	none, noneLen := d.add("none")
+	const buf = 256
+	// copies "in" to "out" and returns FAILURE from the -1 of a missing key
+	get := module(d.data,
+		call(hostGet, wasm.ScopeGlobal, in, inLen, buf, 64), []byte{drop},
+		call(hostSet, wasm.ScopeTree, out, outLen, buf, 4), []byte{drop},
+		call(hostGet, wasm.ScopeGlobal, none, noneLen, buf, 64), i32(3), []byte{add})
+	// returns the length of "in", longer than its buffer
+	short := module(d.data,
+		call(hostGet, wasm.ScopeGlobal, in, inLen, buf, 1))
+	_, errs := newRuntime(t, script.DefaultLimits, map[string][]byte{"get": get, "short": short})
+
+	board := NewBlackboard(nil)
+	board.Set("in", "ab", "", "")
+	tree := loadModule(t, "get")
+	if status := tree.Tick(0, board); status != b3.FAILURE {
+		t.Fatal("get:", status, *errs)
+	}
+	if got := board.Get("out", tree.GetID(), ""); got != "ab" {
+		t.Errorf("copied: %#v", got)
+	}
+	if status := loadModule(t, "short").Tick(0, board); status != b3.Status(len(`"ab"`)) {
+		t.Error("short buffer:", status, *errs)
+	}
+}
+
+func TestHostLog(t *testing.T) {
+	var d moduleData
+	msg, msgLen := d.add("hello")
+	logs := module(d.data, call(hostLog, msg, msgLen), i32(int32(b3.SUCCESS)))
+	rt, _ := newRuntime(t, script.DefaultLimits, map[string][]byte{"log": logs})
+	var got []string
+	rt.SetLogger(func(node *wasm.WasmAction, msg string) {
+		got = append(got, node.GetTitle()+": "+msg)
+	})
+	if status := loadModule(t, "log").Tick(0, NewBlackboard(nil)); status != b3.SUCCESS {
+		t.Fatal(status)
+	}
+	if len(got) != 1 || got[0] != "module: hello" {
+		t.Errorf("%q", got)
+	}
+}
+
+func TestTrapAndTimeoutReturnError(t *testing.T) {
+	trap := module(nil, []byte{0x00})
+	loop := module(nil, []byte{0x03, 0x40, 0x0c, 0x00, 0x0b}, i32(int32(b3.SUCCESS)))
+	_, errs := newRuntime(t, script.Limits{Timeout: 10 * time.Millisecond},
+		map[string][]byte{"trap": trap, "loop": loop})
+
+	board := NewBlackboard(nil)
+	tree := loadModule(t, "trap")
+	if status := tree.Tick(0, board); status != b3.ERROR {
+		t.Fatal("trap:", status)
+	}
+	if len(*errs) != 1 || board.Get("wasm.error", tree.GetID(), "w") == nil {
+		t.Fatal("trap errors:", *errs)
+	}
+
+	*errs = nil
+	if status := loadModule(t, "loop").Tick(0, board); status != b3.ERROR {
+		t.Fatal("loop:", status)
+	}
+	var limitErr *script.LimitError
+	if len(*errs) != 1 || !errors.As((*errs)[0], &limitErr) {
+		t.Fatal("loop errors:", *errs)
+	}
+	if limitErr.Resource != script.LimitTime || limitErr.Script != "loop" {
+		t.Errorf("%+v", limitErr)
+	}
+}
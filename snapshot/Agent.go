@@ -0,0 +1,262 @@
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/youngtrips/behavior3go/core"
+	"github.com/youngtrips/behavior3go/internal/pbwire"
+)
+
+// Tree identifies a tree an agent runs and its running state.
+type Tree struct {
+	TreeID    string
+	ConfigID  string
+	Title     string
+	OpenNodes []string
+	Version   string
+}
+
+/**
+ * AgentState mirrors the AgentState message of agent.proto: everything
+ * needed to hibernate an agent and resume it later, possibly in another
+ * process where the trees got new runtime ids.
+**/
+type AgentState struct {
+	AgentID string
+	// unix time in milliseconds
+	SavedAt int64
+	Trees   []Tree
+	Entries []core.MemoryEntry
+}
+
+func configID(tree *core.BehaviorTree) string {
+	if cfg := tree.Dump(); cfg != nil {
+		return cfg.ID
+	}
+	return ""
+}
+
+// Capture builds the state of an agent running trees with blackboard.
+func Capture(agentID string, blackboard *core.Blackboard, trees ...*core.BehaviorTree) *AgentState {
+	bs := blackboard.Export()
+	open := make(map[string][]string, len(bs.Trees))
+	for _, ts := range bs.Trees {
+		open[ts.TreeID] = ts.OpenNodes
+	}
+	state := &AgentState{
+		AgentID: agentID,
+		SavedAt: time.Now().UnixNano() / int64(time.Millisecond),
+		Entries: bs.Entries,
+	}
+	for _, tree := range trees {
+		state.Trees = append(state.Trees, Tree{
+			TreeID:    tree.GetID(),
+			ConfigID:  configID(tree),
+			Title:     tree.GetTitile(),
+			OpenNodes: open[tree.GetID()],
+			Version:   tree.Version(),
+		})
+	}
+	return state
+}
+
+// ErrVersionMismatch is returned when a saved tree has another version
+// than the tree it is restored onto and no migration is supplied.
+var ErrVersionMismatch = errors.New("snapshot: tree version mismatch")
+
+/**
+ * MigrateFunc adapts the state saved for a tree to tree, whose version
+ * differs; it may rewrite saved.OpenNodes or return an error to refuse.
+**/
+type MigrateFunc func(saved *Tree, tree *core.BehaviorTree) error
+
+/**
+ * DropRunning is a MigrateFunc keeping the memory of a changed tree but
+ * forgetting its running nodes, so it starts over from the root.
+**/
+func DropRunning(saved *Tree, tree *core.BehaviorTree) error {
+	saved.OpenNodes = nil
+	return nil
+}
+
+/**
+ * Apply restores state onto blackboard. Every saved tree is matched with
+ * one of trees by config id (or by runtime id for trees built in code) and
+ * the memory scopes are renamed to the runtime ids of the given trees.
+ * ErrVersionMismatch is returned if a tree changed since the state was
+ * saved, see ApplyMigrate.
+**/
+func Apply(state *AgentState, blackboard *core.Blackboard, trees ...*core.BehaviorTree) error {
+	return ApplyMigrate(state, blackboard, nil, trees...)
+}
+
+// ApplyMigrate is Apply calling migrate for the trees whose version
+// changed instead of failing.
+func ApplyMigrate(state *AgentState, blackboard *core.Blackboard, migrate MigrateFunc, trees ...*core.BehaviorTree) error {
+	byID := make(map[string]*core.BehaviorTree, len(trees))
+	rename := make(map[string]string, len(state.Trees))
+	bs := &core.BlackboardState{}
+	for _, saved := range state.Trees {
+		var match *core.BehaviorTree
+		for _, tree := range trees {
+			if (saved.ConfigID != "" && configID(tree) == saved.ConfigID) || tree.GetID() == saved.TreeID {
+				match = tree
+				break
+			}
+		}
+		if match == nil {
+			return fmt.Errorf("snapshot: agent %s: tree %s(%s) not provided", state.AgentID, saved.Title, saved.ConfigID)
+		}
+		if saved.Version != "" && saved.Version != match.Version() {
+			if migrate == nil {
+				return fmt.Errorf("%w: agent %s: tree %s(%s) saved at %s, now %s", ErrVersionMismatch,
+					state.AgentID, saved.Title, saved.ConfigID, saved.Version, match.Version())
+			}
+			if err := migrate(&saved, match); err != nil {
+				return err
+			}
+		}
+		rename[saved.TreeID] = match.GetID()
+		byID[match.GetID()] = match
+		if len(saved.OpenNodes) > 0 {
+			bs.Trees = append(bs.Trees, core.TreeState{TreeID: match.GetID(), OpenNodes: saved.OpenNodes})
+		}
+	}
+	for _, e := range state.Entries {
+		if id, ok := rename[e.TreeScope]; ok {
+			e.TreeScope = id
+		}
+		bs.Entries = append(bs.Entries, e)
+	}
+	return blackboard.Import(bs, func(treeID string) *core.BehaviorTree {
+		return byID[treeID]
+	})
+}
+
+// SaveAgent captures and encodes the state of an agent. It fails on
+// memory values of types encodeValue does not keep, as the live handles
+// of a running Exec, HTTPRequest or WaitForEvent.
+func SaveAgent(agentID string, blackboard *core.Blackboard, trees ...*core.BehaviorTree) ([]byte, error) {
+	return Marshal(Capture(agentID, blackboard, trees...))
+}
+
+// LoadAgent decodes data and restores it onto blackboard, see Apply.
+func LoadAgent(data []byte, blackboard *core.Blackboard, trees ...*core.BehaviorTree) (*AgentState, error) {
+	return LoadAgentMigrate(data, blackboard, nil, trees...)
+}
+
+// LoadAgentMigrate is LoadAgent with a migration, see ApplyMigrate.
+func LoadAgentMigrate(data []byte, blackboard *core.Blackboard, migrate MigrateFunc, trees ...*core.BehaviorTree) (*AgentState, error) {
+	state, err := Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := ApplyMigrate(state, blackboard, migrate, trees...); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// ------------------------encoding-------------------------
+
+func Marshal(state *AgentState) ([]byte, error) {
+	e := pbwire.NewEncoder()
+	e.OptString(1, state.AgentID)
+	e.OptInt64(2, state.SavedAt)
+	for _, t := range state.Trees {
+		e.Message(3, func(e *pbwire.Encoder) {
+			e.OptString(1, t.TreeID)
+			e.OptString(2, t.ConfigID)
+			e.OptString(3, t.Title)
+			for _, id := range t.OpenNodes {
+				e.String(4, id)
+			}
+			e.OptString(5, t.Version)
+		})
+	}
+	for _, entry := range state.Entries {
+		var err error
+		e.Message(4, func(e *pbwire.Encoder) {
+			e.OptString(1, entry.Key)
+			e.OptString(2, entry.TreeScope)
+			e.OptString(3, entry.NodeScope)
+			e.Message(4, func(e *pbwire.Encoder) {
+				err = encodeValue(e, entry.Value)
+			})
+		})
+		if err != nil {
+			return nil, fmt.Errorf("snapshot: key %s: %v", entry.Key, err)
+		}
+	}
+	return e.Bytes(), nil
+}
+
+func Unmarshal(data []byte) (*AgentState, error) {
+	state := &AgentState{}
+	err := pbwire.Parse(data, func(f pbwire.Field) error {
+		switch f.Num {
+		case 1:
+			state.AgentID = f.String()
+		case 2:
+			state.SavedAt = f.Int64()
+		case 3:
+			t, err := decodeTree(f.Data)
+			if err != nil {
+				return err
+			}
+			state.Trees = append(state.Trees, t)
+		case 4:
+			entry, err := decodeEntry(f.Data)
+			if err != nil {
+				return err
+			}
+			state.Entries = append(state.Entries, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: %v", err)
+	}
+	return state, nil
+}
+
+func decodeTree(data []byte) (Tree, error) {
+	var t Tree
+	err := pbwire.Parse(data, func(f pbwire.Field) error {
+		switch f.Num {
+		case 1:
+			t.TreeID = f.String()
+		case 2:
+			t.ConfigID = f.String()
+		case 3:
+			t.Title = f.String()
+		case 4:
+			t.OpenNodes = append(t.OpenNodes, f.String())
+		case 5:
+			t.Version = f.String()
+		}
+		return nil
+	})
+	return t, err
+}
+
+func decodeEntry(data []byte) (core.MemoryEntry, error) {
+	var entry core.MemoryEntry
+	err := pbwire.Parse(data, func(f pbwire.Field) error {
+		var err error
+		switch f.Num {
+		case 1:
+			entry.Key = f.String()
+		case 2:
+			entry.TreeScope = f.String()
+		case 3:
+			entry.NodeScope = f.String()
+		case 4:
+			entry.Value, err = decodeValue(f.Data)
+		}
+		return err
+	})
+	return entry, err
+}
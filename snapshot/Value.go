@@ -0,0 +1,330 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	b3 "github.com/youngtrips/behavior3go"
+	"github.com/youngtrips/behavior3go/core"
+	"github.com/youngtrips/behavior3go/internal/pbwire"
+)
+
+// listTypes are the slice types a List value restores, by name.
+var listTypes = map[string]func(items []interface{}) (interface{}, bool){
+	"[]interface{}": func(items []interface{}) (interface{}, bool) { return items, true },
+	"[]int": func(items []interface{}) (interface{}, bool) {
+		list := make([]int, len(items))
+		for i, item := range items {
+			v, ok := item.(int)
+			if !ok {
+				return nil, false
+			}
+			list[i] = v
+		}
+		return list, true
+	},
+	"[]int64": func(items []interface{}) (interface{}, bool) {
+		list := make([]int64, len(items))
+		for i, item := range items {
+			v, ok := item.(int64)
+			if !ok {
+				return nil, false
+			}
+			list[i] = v
+		}
+		return list, true
+	},
+	"[]float64": func(items []interface{}) (interface{}, bool) {
+		list := make([]float64, len(items))
+		for i, item := range items {
+			v, ok := item.(float64)
+			if !ok {
+				return nil, false
+			}
+			list[i] = v
+		}
+		return list, true
+	},
+	"[]string": func(items []interface{}) (interface{}, bool) {
+		list := make([]string, len(items))
+		for i, item := range items {
+			v, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			list[i] = v
+		}
+		return list, true
+	},
+	"[]b3.Status": func(items []interface{}) (interface{}, bool) {
+		list := make([]b3.Status, len(items))
+		for i, item := range items {
+			v, ok := item.(b3.Status)
+			if !ok {
+				return nil, false
+			}
+			list[i] = v
+		}
+		return list, true
+	},
+}
+
+// listItems returns the name and items of the slice types of listTypes.
+func listItems(value interface{}) (string, []interface{}, bool) {
+	var items []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		return "[]interface{}", v, true
+	case []int:
+		for _, item := range v {
+			items = append(items, item)
+		}
+		return "[]int", items, true
+	case []int64:
+		for _, item := range v {
+			items = append(items, item)
+		}
+		return "[]int64", items, true
+	case []float64:
+		for _, item := range v {
+			items = append(items, item)
+		}
+		return "[]float64", items, true
+	case []string:
+		for _, item := range v {
+			items = append(items, item)
+		}
+		return "[]string", items, true
+	case []b3.Status:
+		for _, item := range v {
+			items = append(items, item)
+		}
+		return "[]b3.Status", items, true
+	}
+	return "", nil, false
+}
+
+/**
+ * encodeValue writes value as a Value message, keeping its Go type: the
+ * basic types, b3.Status, time.Time, time.Duration, the slices of
+ * listTypes, map[string]interface{} and *core.ProfileStats, which cover
+ * what the nodes of this module store. Other types are refused rather
+ * than restored as something else, which the nodes would not recognize.
+**/
+func encodeValue(e *pbwire.Encoder, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		e.Bool(1, true)
+	case bool:
+		e.Bool(2, v)
+	case int:
+		e.Sint64(3, int64(v))
+	case int32:
+		e.Sint64(4, int64(v))
+	case int64:
+		e.Sint64(5, v)
+	case uint32:
+		e.Uint64(6, uint64(v))
+	case uint64:
+		e.Uint64(7, v)
+	case float32:
+		e.Float(8, v)
+	case float64:
+		e.Double(9, v)
+	case string:
+		e.String(10, v)
+	case []byte:
+		e.Data(11, v)
+	case b3.Status:
+		e.Sint64(13, int64(v))
+	case time.Duration:
+		e.Sint64(14, int64(v))
+	case time.Time:
+		e.Message(15, func(e *pbwire.Encoder) {
+			e.Sint64(1, v.Unix())
+			e.Sint64(2, int64(v.Nanosecond()))
+		})
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var err error
+		e.Message(17, func(e *pbwire.Encoder) {
+			for _, k := range keys {
+				e.Message(1, func(e *pbwire.Encoder) {
+					e.String(1, k)
+					e.Message(2, func(e *pbwire.Encoder) {
+						if verr := encodeValue(e, v[k]); verr != nil && err == nil {
+							err = fmt.Errorf("%s: %v", k, verr)
+						}
+					})
+				})
+			}
+		})
+		return err
+	case *core.ProfileStats:
+		if v == nil {
+			e.Bool(1, true)
+			return nil
+		}
+		e.Message(18, func(e *pbwire.Encoder) {
+			e.Sint64(1, v.Ticks)
+			e.Sint64(2, v.Nodes)
+			e.Sint64(3, int64(v.Total))
+			e.Sint64(4, int64(v.Last))
+			e.Sint64(5, int64(v.Max))
+		})
+	default:
+		name, items, ok := listItems(value)
+		if !ok {
+			return fmt.Errorf("unsupported type %T", value)
+		}
+		var err error
+		e.Message(16, func(e *pbwire.Encoder) {
+			e.String(1, name)
+			for i, item := range items {
+				e.Message(2, func(e *pbwire.Encoder) {
+					if verr := encodeValue(e, item); verr != nil && err == nil {
+						err = fmt.Errorf("[%d]: %v", i, verr)
+					}
+				})
+			}
+		})
+		return err
+	}
+	return nil
+}
+
+func decodeValue(data []byte) (interface{}, error) {
+	var value interface{}
+	err := pbwire.Parse(data, func(f pbwire.Field) error {
+		var err error
+		switch f.Num {
+		case 1:
+			value = nil
+		case 2:
+			value = f.Bool()
+		case 3:
+			value = int(f.Sint64())
+		case 4:
+			value = int32(f.Sint64())
+		case 5:
+			value = f.Sint64()
+		case 6:
+			value = uint32(f.Value)
+		case 7:
+			value = f.Value
+		case 8:
+			value = f.Float()
+		case 9:
+			value = f.Double()
+		case 10:
+			value = f.String()
+		case 11:
+			value = append([]byte(nil), f.Data...)
+		case 12:
+			// written by older versions for the other types
+			var v interface{}
+			err = json.Unmarshal(f.Data, &v)
+			value = v
+		case 13:
+			value = b3.Status(f.Sint64())
+		case 14:
+			value = time.Duration(f.Sint64())
+		case 15:
+			var sec, nsec int64
+			err = pbwire.Parse(f.Data, func(f pbwire.Field) error {
+				switch f.Num {
+				case 1:
+					sec = f.Sint64()
+				case 2:
+					nsec = f.Sint64()
+				}
+				return nil
+			})
+			value = time.Unix(sec, nsec)
+		case 16:
+			value, err = decodeList(f.Data)
+		case 17:
+			value, err = decodeMap(f.Data)
+		case 18:
+			stats := &core.ProfileStats{}
+			err = pbwire.Parse(f.Data, func(f pbwire.Field) error {
+				switch f.Num {
+				case 1:
+					stats.Ticks = f.Sint64()
+				case 2:
+					stats.Nodes = f.Sint64()
+				case 3:
+					stats.Total = time.Duration(f.Sint64())
+				case 4:
+					stats.Last = time.Duration(f.Sint64())
+				case 5:
+					stats.Max = time.Duration(f.Sint64())
+				}
+				return nil
+			})
+			value = stats
+		}
+		return err
+	})
+	return value, err
+}
+
+func decodeList(data []byte) (interface{}, error) {
+	var name string
+	items := []interface{}{}
+	err := pbwire.Parse(data, func(f pbwire.Field) error {
+		switch f.Num {
+		case 1:
+			name = f.String()
+		case 2:
+			item, err := decodeValue(f.Data)
+			if err != nil {
+				return err
+			}
+			items = append(items, item)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	build, ok := listTypes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown list type %s", name)
+	}
+	list, ok := build(items)
+	if !ok {
+		return nil, fmt.Errorf("items of %s of another type", name)
+	}
+	return list, nil
+}
+
+func decodeMap(data []byte) (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+	err := pbwire.Parse(data, func(f pbwire.Field) error {
+		if f.Num != 1 {
+			return nil
+		}
+		var key string
+		var value interface{}
+		err := pbwire.Parse(f.Data, func(f pbwire.Field) error {
+			var err error
+			switch f.Num {
+			case 1:
+				key = f.String()
+			case 2:
+				value, err = decodeValue(f.Data)
+			}
+			return err
+		})
+		m[key] = value
+		return err
+	})
+	return m, err
+}
@@ -0,0 +1,123 @@
+package snapshot_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	b3 "github.com/youngtrips/behavior3go"
+	. "github.com/youngtrips/behavior3go/config"
+	. "github.com/youngtrips/behavior3go/core"
+	"github.com/youngtrips/behavior3go/loader"
+	"github.com/youngtrips/behavior3go/snapshot"
+)
+
+func loadTree(t *testing.T, data string) *BehaviorTree {
+	t.Helper()
+	treeConfig, err := LoadTreeCfgFromBytes([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := loader.TryCreateBevTreeFromConfig(treeConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+func TestValuesKeepTheirTypes(t *testing.T) {
+	values := map[string]interface{}{
+		"nil":      nil,
+		"bool":     true,
+		"int":      -3,
+		"int32":    int32(7),
+		"int64":    int64(1) << 40,
+		"uint32":   uint32(9),
+		"uint64":   uint64(1) << 63,
+		"float32":  float32(1.5),
+		"float64":  2.25,
+		"string":   "s",
+		"bytes":    []byte("raw"),
+		"status":   b3.RUNNING,
+		"duration": 1500 * time.Millisecond,
+		"time":     time.Unix(1700000000, 42),
+		"ints":     []int{2, 0, 1},
+		"int64s":   []int64{1, 2},
+		"floats":   []float64{0.5, 1},
+		"strings":  []string{"a", "b"},
+		"statuses": []b3.Status{b3.SUCCESS, b3.FAILURE, b3.RUNNING},
+		"list":     []interface{}{1, "a", b3.SUCCESS},
+		"map":      map[string]interface{}{"a": []int{1}, "b": 2.5},
+		"stats":    &ProfileStats{Ticks: 3, Nodes: 12, Total: time.Second, Last: time.Millisecond, Max: 2 * time.Millisecond},
+	}
+	board := NewBlackboard(nil)
+	for key, value := range values {
+		board.SetMem(key, value)
+	}
+	data, err := snapshot.SaveAgent("a", board)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := NewBlackboard(nil)
+	if _, err := snapshot.LoadAgent(data, restored); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range values {
+		got := restored.GetMem(key)
+		if wt, ok := want.(time.Time); ok {
+			if gt, ok := got.(time.Time); !ok || !gt.Equal(wt) {
+				t.Errorf("%s: %#v, want %#v", key, got, want)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: %#v (%T), want %#v (%T)", key, got, got, want, want)
+		}
+	}
+}
+
+func TestUnsupportedValueFailsSave(t *testing.T) {
+	board := NewBlackboard(nil)
+	board.SetMem("handle", make(chan struct{}))
+	if _, err := snapshot.SaveAgent("a", board); err == nil {
+		t.Fatal("saved a channel")
+	}
+	board.SetMem("handle", []interface{}{struct{}{}})
+	if _, err := snapshot.SaveAgent("a", board); err == nil {
+		t.Fatal("saved a struct in a list")
+	}
+}
+
+func TestRestoredParallelResumes(t *testing.T) {
+	const data = `{
+		"id": "t", "title": "parallel", "root": "p",
+		"nodes": {
+			"p": {"id": "p", "name": "Parallel", "category": "composite",
+				"properties": {"successPolicy": "all"}, "children": ["w", "s"]},
+			"w": {"id": "w", "name": "Wait", "category": "action", "properties": {"milliseconds": 1}},
+			"s": {"id": "s", "name": "Succeeder", "category": "action"}
+		}
+	}`
+	tree := loadTree(t, data)
+	board := NewBlackboard(nil)
+	clock := NewStepClock(time.Unix(0, 0), 2*time.Millisecond)
+	board.SetClock(clock)
+	if status := tree.Tick(0, board); status != b3.RUNNING {
+		t.Fatal("tick 1:", status)
+	}
+	saved, err := snapshot.SaveAgent("a", board, tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restoredTree := loadTree(t, data)
+	restored := NewBlackboard(nil)
+	restored.SetClock(clock)
+	if _, err := snapshot.LoadAgent(saved, restored, restoredTree); err != nil {
+		t.Fatal(err)
+	}
+	clock.Step()
+	if status := restoredTree.Tick(0, restored); status != b3.SUCCESS {
+		t.Fatal("tick after restore:", status)
+	}
+}
@@ -0,0 +1,180 @@
+package variant
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	b3 "github.com/youngtrips/behavior3go"
+	"github.com/youngtrips/behavior3go/core"
+)
+
+// 默认写入黑板(全局域)的key
+const (
+	DefaultKey = "b3.variant"
+)
+
+type Variant struct {
+	Name   string
+	Tree   *core.BehaviorTree
+	Weight int
+}
+
+/**
+ * Manager runs one of several versions of a tree per agent, for tuning AI
+ * in a live game. Each agent key is hashed (with a salt, so different
+ * experiments bucket independently) into the weights of the registered
+ * variants; the assignment is sticky as long as the weights do not change.
+ * With weights 90/10 and 100 buckets, 10% of the agents run the new tree.
+ *
+ * The variant an agent runs is written to its blackboard under Key. A
+ * variant is only switched (after SetWeight or Pin) once the running one
+ * completes, i.e. returns something other than RUNNING, so an agent is
+ * never moved to another tree in the middle of a behavior.
+ *
+ * Manager is safe for concurrent use; the blackboards passed to Tick are
+ * not, as usual.
+ *
+ * @class Manager
+**/
+type Manager struct {
+	salt string
+	key  string
+
+	mutex    sync.RWMutex
+	variants []*Variant
+	pins     map[string]string
+}
+
+func NewManager(salt string) *Manager {
+	return &Manager{salt: salt, key: DefaultKey, pins: make(map[string]string)}
+}
+
+// SetKey changes the blackboard key the variant name is stored under.
+func (this *Manager) SetKey(key string) {
+	this.key = key
+}
+
+func (this *Manager) Key() string {
+	return this.key
+}
+
+/**
+ * Register adds a variant, or replaces the tree and weight of an existing
+ * one with the same name. A weight of 0 keeps the variant registered (e.g.
+ * for pinned agents) without rolling it out.
+**/
+func (this *Manager) Register(name string, tree *core.BehaviorTree, weight int) error {
+	if weight < 0 {
+		return fmt.Errorf("variant %s: negative weight %d", name, weight)
+	}
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	for _, v := range this.variants {
+		if v.Name == name {
+			v.Tree = tree
+			v.Weight = weight
+			return nil
+		}
+	}
+	this.variants = append(this.variants, &Variant{Name: name, Tree: tree, Weight: weight})
+	return nil
+}
+
+// SetWeight changes the rollout of a registered variant.
+func (this *Manager) SetWeight(name string, weight int) error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if v := this.find(name); v != nil {
+		v.Weight = weight
+		return nil
+	}
+	return fmt.Errorf("variant %s not registered", name)
+}
+
+// Pin forces agentKey onto a variant regardless of the weights; an empty
+// name removes the pin.
+func (this *Manager) Pin(agentKey string, name string) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if name == "" {
+		delete(this.pins, agentKey)
+	} else {
+		this.pins[agentKey] = name
+	}
+}
+
+func (this *Manager) find(name string) *Variant {
+	for _, v := range this.variants {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// Get returns the registered variant called name, nil if there is none.
+func (this *Manager) Get(name string) *Variant {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+	return this.find(name)
+}
+
+// Assign returns the variant agentKey should run now, nil if no variant
+// has a weight.
+func (this *Manager) Assign(agentKey string) *Variant {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+	if name, ok := this.pins[agentKey]; ok {
+		if v := this.find(name); v != nil {
+			return v
+		}
+	}
+	total := 0
+	for _, v := range this.variants {
+		total += v.Weight
+	}
+	if total == 0 {
+		return nil
+	}
+	h := fnv.New32a()
+	h.Write([]byte(this.salt))
+	h.Write([]byte(agentKey))
+	bucket := int(h.Sum32() % uint32(total))
+	for _, v := range this.variants {
+		if bucket < v.Weight {
+			return v
+		}
+		bucket -= v.Weight
+	}
+	return nil
+}
+
+/**
+ * Tick ticks the variant of agentKey. If the variant recorded on the
+ * blackboard is still RUNNING it keeps being ticked, otherwise the agent
+ * is (re)assigned first. Returns ERROR if no variant can be assigned.
+**/
+func (this *Manager) Tick(agentKey string, target interface{}, blackboard *core.Blackboard) b3.Status {
+	var v *Variant
+	if name, ok := blackboard.GetMem(this.key).(string); ok && blackboard.GetBool(this.key+".running", "", "") {
+		v = this.Get(name)
+	}
+	if v == nil {
+		v = this.Assign(agentKey)
+	}
+	if v == nil || v.Tree == nil {
+		return b3.ERROR
+	}
+	blackboard.SetMem(this.key, v.Name)
+
+	status := v.Tree.Tick(target, blackboard)
+	blackboard.SetMem(this.key+".running", status == b3.RUNNING)
+	return status
+}
+
+// Current returns the variant name recorded on blackboard, "" if none.
+func (this *Manager) Current(blackboard *core.Blackboard) string {
+	name, _ := blackboard.GetMem(this.key).(string)
+	return name
+}